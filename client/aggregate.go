@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// DefaultToolSeparator separates the server name from the tool name in the
+// tool names listed by an AggregateClient.
+const DefaultToolSeparator = "__"
+
+// ErrUnknownTool is returned by AggregateClient.CallTool when no backend
+// lists a tool with the given name.
+var ErrUnknownTool = errors.New("unknown tool")
+
+// ErrUnknownResource is returned by AggregateClient.ReadResource when no
+// backend lists a resource with the given URI.
+var ErrUnknownResource = errors.New("unknown resource")
+
+// AggregateClient presents several MCP clients, one per backend server, as a
+// single one. Tools of all backends are listed with their names prefixed by
+// the name of their server, and tool calls and resource reads are routed to
+// the backend that listed them.
+//
+// Backends are visited in the order of their names, so that name collisions
+// are resolved deterministically: when two backends list the same prefixed
+// tool name or the same resource URI, the first backend wins.
+type AggregateClient struct {
+	clients   map[string]*Client
+	names     []string // sorted server names
+	separator string
+
+	mu        sync.RWMutex
+	tools     map[string]toolRoute // prefixed tool name -> backend tool
+	resources map[string]string    // resource URI -> server name
+
+	notifyMu      sync.RWMutex
+	notifications []func(server string, notification mcp.JSONRPCNotification)
+}
+
+// toolRoute identifies a tool of a backend.
+type toolRoute struct {
+	server string
+	name   string
+}
+
+// AggregateOption configures an AggregateClient.
+type AggregateOption func(*AggregateClient)
+
+// WithToolSeparator sets the separator placed between the server name and the
+// tool name. The default is DefaultToolSeparator.
+func WithToolSeparator(separator string) AggregateOption {
+	return func(a *AggregateClient) {
+		a.separator = separator
+	}
+}
+
+// NewAggregateClient creates an AggregateClient over clients, keyed by the
+// server name used as tool name prefix. The clients must be started and
+// initialized by the caller.
+func NewAggregateClient(clients map[string]*Client, opts ...AggregateOption) *AggregateClient {
+	a := &AggregateClient{
+		clients:   make(map[string]*Client, len(clients)),
+		separator: DefaultToolSeparator,
+		tools:     make(map[string]toolRoute),
+		resources: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	for name, c := range clients {
+		a.clients[name] = c
+		a.names = append(a.names, name)
+		c.OnNotification(func(notification mcp.JSONRPCNotification) {
+			a.handleNotification(name, notification)
+		})
+	}
+	sort.Strings(a.names)
+	return a
+}
+
+// Client returns the client of the named backend server.
+func (a *AggregateClient) Client(server string) (*Client, bool) {
+	c, ok := a.clients[server]
+	return c, ok
+}
+
+// OnNotification registers a handler for the notifications of all backends,
+// along with the name of the server that sent them. This includes the
+// list_changed notifications, after which the merged listings should be
+// fetched again.
+func (a *AggregateClient) OnNotification(handler func(server string, notification mcp.JSONRPCNotification)) {
+	a.notifyMu.Lock()
+	defer a.notifyMu.Unlock()
+	a.notifications = append(a.notifications, handler)
+}
+
+func (a *AggregateClient) handleNotification(server string, notification mcp.JSONRPCNotification) {
+	a.notifyMu.RLock()
+	defer a.notifyMu.RUnlock()
+	for _, handler := range a.notifications {
+		handler(server, notification)
+	}
+}
+
+// ListTools lists the tools of all backends that support tools, with their
+// names prefixed by the name of their server and the separator.
+func (a *AggregateClient) ListTools(ctx context.Context) (*mcp.ListToolsResult, error) {
+	result := &mcp.ListToolsResult{Tools: []mcp.Tool{}}
+	routes := make(map[string]toolRoute)
+	for _, server := range a.names {
+		c := a.clients[server]
+		if !c.SupportsTools() {
+			continue
+		}
+		listed, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", server, err)
+		}
+		for _, tool := range listed.Tools {
+			name := server + a.separator + tool.Name
+			if _, exists := routes[name]; exists {
+				continue
+			}
+			routes[name] = toolRoute{server: server, name: tool.Name}
+			tool.Name = name
+			result.Tools = append(result.Tools, tool)
+		}
+	}
+
+	a.mu.Lock()
+	a.tools = routes
+	a.mu.Unlock()
+	return result, nil
+}
+
+// CallTool calls a tool listed by ListTools on its backend, with the server
+// prefix stripped from its name. The tools are listed first if the name is
+// not known yet.
+func (a *AggregateClient) CallTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	return a.CallToolWithOptions(ctx, request)
+}
+
+// CallToolWithOptions calls a tool like CallTool, passing the per-call
+// options on to the backend.
+func (a *AggregateClient) CallToolWithOptions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	opts ...RequestOption,
+) (*mcp.CallToolResult, error) {
+	route, ok := a.toolRoute(request.Params.Name)
+	if !ok {
+		if _, err := a.ListTools(ctx); err != nil {
+			return nil, err
+		}
+		if route, ok = a.toolRoute(request.Params.Name); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTool, request.Params.Name)
+		}
+	}
+
+	request.Params.Name = route.name
+	return a.clients[route.server].CallToolWithOptions(ctx, request, opts...)
+}
+
+func (a *AggregateClient) toolRoute(name string) (toolRoute, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	route, ok := a.tools[name]
+	return route, ok
+}
+
+// ListResources lists the resources of all backends that support resources.
+// Resource URIs are left unchanged.
+func (a *AggregateClient) ListResources(ctx context.Context) (*mcp.ListResourcesResult, error) {
+	result := &mcp.ListResourcesResult{Resources: []mcp.Resource{}}
+	routes := make(map[string]string)
+	for _, server := range a.names {
+		c := a.clients[server]
+		if !c.SupportsResources() {
+			continue
+		}
+		listed, err := c.ListResources(ctx, mcp.ListResourcesRequest{})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", server, err)
+		}
+		for _, resource := range listed.Resources {
+			if _, exists := routes[resource.URI]; exists {
+				continue
+			}
+			routes[resource.URI] = server
+			result.Resources = append(result.Resources, resource)
+		}
+	}
+
+	a.mu.Lock()
+	a.resources = routes
+	a.mu.Unlock()
+	return result, nil
+}
+
+// ReadResource reads a resource listed by ListResources from its backend.
+// The resources are listed first if the URI is not known yet.
+func (a *AggregateClient) ReadResource(
+	ctx context.Context,
+	request mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	server, ok := a.resourceServer(request.Params.URI)
+	if !ok {
+		if _, err := a.ListResources(ctx); err != nil {
+			return nil, err
+		}
+		if server, ok = a.resourceServer(request.Params.URI); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownResource, request.Params.URI)
+		}
+	}
+	return a.clients[server].ReadResource(ctx, request)
+}
+
+func (a *AggregateClient) resourceServer(uri string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	server, ok := a.resources[uri]
+	return server, ok
+}
+
+// Close closes the clients of all backends.
+func (a *AggregateClient) Close() error {
+	var errs []error
+	for _, server := range a.names {
+		if err := a.clients[server].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", server, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// newAggregateBackend returns an initialized client connected to a mock
+// transport whose server lists the given tools and resources. Tool calls are
+// answered with the name of the server and of the called tool.
+func newAggregateBackend(t *testing.T, server string, tools []mcp.Tool, resources []mcp.Resource) (*Client, *transport.MockTransport) {
+	t.Helper()
+	c, mock := newToolsTestClient(t)
+	if resources != nil {
+		c.serverCapabilities.Resources = &struct {
+			Subscribe   bool `json:"subscribe,omitempty"`
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{}
+	}
+	if err := mock.RespondWith("tools/list", mcp.ListToolsResult{Tools: tools}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	if err := mock.RespondWith("resources/list", mcp.ListResourcesResult{Resources: resources}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	mock.Handle("tools/call", func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+		params := request.Params.(mcp.CallToolParams)
+		result := fmt.Sprintf(`{"content":[{"type":"text","text":"%s:%s"}]}`, server, params.Name)
+		return transport.NewJSONRPCResultResponse(request.ID, []byte(result)), nil
+	})
+	if err := mock.RespondWith("resources/read", mcp.ReadResourceResult{
+		Contents: []mcp.ResourceContents{mcp.TextResourceContents{URI: "file:///" + server, Text: server}},
+	}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	return c, mock
+}
+
+func TestAggregateClient(t *testing.T) {
+	ctx := context.Background()
+	git, _ := newAggregateBackend(t, "git",
+		[]mcp.Tool{mcp.NewTool("hub__search"), mcp.NewTool("search")},
+		[]mcp.Resource{mcp.NewResource("repo://readme", "Git README"), mcp.NewResource("repo://log", "Log")},
+	)
+	// Its search tool and readme collide with the ones of git
+	hub, _ := newAggregateBackend(t, "git__hub",
+		[]mcp.Tool{mcp.NewTool("search"), mcp.NewTool("create_issue")},
+		[]mcp.Resource{mcp.NewResource("repo://readme", "Hub README")},
+	)
+	jira, jiraMock := newAggregateBackend(t, "jira", []mcp.Tool{mcp.NewTool("search")}, nil)
+
+	aggregate := NewAggregateClient(map[string]*Client{"git__hub": hub, "git": git, "jira": jira})
+
+	t.Run("ListTools prefixes tool names and resolves collisions by server name", func(t *testing.T) {
+		result, err := aggregate.ListTools(ctx)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		expected := []string{"git__hub__search", "git__search", "git__hub__create_issue", "jira__search"}
+		if fmt.Sprint(names) != fmt.Sprint(expected) {
+			t.Errorf("Expected tools %v, got %v", expected, names)
+		}
+	})
+
+	t.Run("CallTool routes to the backend", func(t *testing.T) {
+		for name, expected := range map[string]string{
+			"git__hub__search":       "git:hub__search",
+			"git__hub__create_issue": "git__hub:create_issue",
+			"jira__search":           "jira:search",
+		} {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = name
+			result, err := aggregate.CallTool(ctx, request)
+			if err != nil {
+				t.Fatalf("CallTool(%s) failed: %v", name, err)
+			}
+			if text := result.Content[0].(mcp.TextContent).Text; text != expected {
+				t.Errorf("CallTool(%s): expected %q, got %q", name, expected, text)
+			}
+		}
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "jira__missing"
+		if _, err := aggregate.CallTool(ctx, request); !errors.Is(err, ErrUnknownTool) {
+			t.Errorf("Expected ErrUnknownTool, got %v", err)
+		}
+	})
+
+	t.Run("ListResources skips backends without resources", func(t *testing.T) {
+		result, err := aggregate.ListResources(ctx)
+		if err != nil {
+			t.Fatalf("ListResources failed: %v", err)
+		}
+		var names []string
+		for _, resource := range result.Resources {
+			names = append(names, resource.Name)
+		}
+		expected := []string{"Git README", "Log"}
+		if fmt.Sprint(names) != fmt.Sprint(expected) {
+			t.Errorf("Expected resources %v, got %v", expected, names)
+		}
+
+		read, err := aggregate.ReadResource(ctx, mcp.ReadResourceRequest{Params: mcp.ReadResourceParams{URI: "repo://readme"}})
+		if err != nil {
+			t.Fatalf("ReadResource failed: %v", err)
+		}
+		if text := read.Contents[0].(mcp.TextResourceContents).Text; text != "git" {
+			t.Errorf("Expected repo://readme to be read from git, got %q", text)
+		}
+	})
+
+	t.Run("Notifications are forwarded with the server name", func(t *testing.T) {
+		var got []string
+		aggregate.OnNotification(func(server string, notification mcp.JSONRPCNotification) {
+			got = append(got, server+" "+notification.Method)
+		})
+		jiraMock.SimulateNotification(mcp.MethodNotificationToolsListChanged, nil)
+		if len(got) != 1 || got[0] != "jira "+mcp.MethodNotificationToolsListChanged {
+			t.Errorf("Expected jira list_changed notification, got %v", got)
+		}
+	})
+}
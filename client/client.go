@@ -2,26 +2,71 @@ package client
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mathiasXie/mcp-go/client/transport"
 	"github.com/mathiasXie/mcp-go/mcp"
 )
 
+// ErrCapabilityNotSupported is returned when a request is made for a capability
+// the server did not advertise during initialization.
+var ErrCapabilityNotSupported = errors.New("capability not supported by server")
+
+// ErrRequestTimeout is returned when a request does not complete within the
+// timeout set with WithDefaultRequestTimeout or WithToolCallTimeout.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// ErrUnsupportedProtocolVersion is returned by Initialize when the server
+// negotiated a protocol version the client does not support.
+var ErrUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+
 // Client implements the MCP client.
 type Client struct {
 	transport transport.Interface
 
 	initialized        bool
 	notifications      []func(mcp.JSONRPCNotification)
+	logHandlers        []func(mcp.LoggingMessageNotification)
 	notifyMu           sync.RWMutex
 	requestID          atomic.Int64
+	idGenerator        func() any
 	clientCapabilities mcp.ClientCapabilities
 	serverCapabilities mcp.ServerCapabilities
+
+	progressHandlers map[string]ProgressHandler
+	outputHandlers   map[string]func([]mcp.Content) // progress token -> tool output handler
+	progressMu       sync.RWMutex
+	progressToken    atomic.Int64
+
+	rootsEnabled     bool
+	roots            []mcp.Root
+	rootsNegotiated  bool // Initialize completed, the fields below are set
+	rootsAdvertised  bool // the roots capability was sent in initialize
+	rootsListChanged bool // list changed notifications were advertised
+	rootsMu          sync.RWMutex
+
+	samplingHandler    SamplingHandler
+	elicitationHandler ElicitationHandler
+
+	toolCache toolCache
+	toolsMu   sync.RWMutex
+
+	requestTimeout   time.Duration
+	requestObservers []RequestObserverFunc
+
+	protocolVersions []string // accepted protocol versions, mcp.ValidProtocolVersions if nil
+	protocolVersion  string   // negotiated during initialization
 }
 
 type ClientOption func(*Client)
@@ -33,6 +78,127 @@ func WithClientCapabilities(capabilities mcp.ClientCapabilities) ClientOption {
 	}
 }
 
+// WithProtocolVersions overrides the protocol versions the client accepts from
+// the server during initialization, which default to mcp.ValidProtocolVersions.
+// It is meant for testing against servers that implement newer versions.
+func WithProtocolVersions(versions []string) ClientOption {
+	return func(c *Client) {
+		c.protocolVersions = versions
+	}
+}
+
+// WithDefaultRequestTimeout sets a timeout applied to every request sent by the client.
+// A shorter deadline on the request context still takes precedence. When the timeout
+// expires, the client sends a cancellation notification and returns ErrRequestTimeout.
+func WithDefaultRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
+}
+
+// RequestObserverFunc observes a request sent by the client. It is called once
+// the request completes, with the context of the request, its method, the time
+// it was sent and the error it failed with, if any. RequestIDFromContext
+// returns the ID of the request from the context.
+type RequestObserverFunc func(ctx context.Context, method string, start time.Time, err error)
+
+// WithRequestObserver registers an observer called after every request the
+// client sends, to build spans and metrics. The option may be given several
+// times; observers are called in order.
+func WithRequestObserver(observer RequestObserverFunc) ClientOption {
+	return func(c *Client) {
+		c.requestObservers = append(c.requestObservers, observer)
+	}
+}
+
+// WithIDGenerator sets the function that generates the IDs of the requests
+// sent by the client, which default to increasing integers. Since the IDs are
+// generated by the client, it works with every transport; it is meant for
+// servers that require IDs in a particular format, such as string UUIDs:
+//
+//	c := client.NewClient(trans, client.WithIDGenerator(func() any {
+//		return uuid.NewString()
+//	}))
+//
+// The generator must return a string or an integer, unique among the requests
+// of the client, and may be called concurrently. Requests fail with an error
+// when it returns any other type.
+func WithIDGenerator(generator func() any) ClientOption {
+	return func(c *Client) {
+		c.idGenerator = generator
+	}
+}
+
+// nextRequestID returns the ID of the next request. Generated integers are
+// normalized to int64, the type of the IDs decoded from responses, so that
+// transports match responses to requests whatever the integer type.
+func (c *Client) nextRequestID() (mcp.RequestId, error) {
+	if c.idGenerator == nil {
+		return mcp.NewRequestId(c.requestID.Add(1)), nil
+	}
+
+	id := c.idGenerator()
+	if s, ok := id.(string); ok {
+		return mcp.NewRequestId(s), nil
+	}
+	value := reflect.ValueOf(id)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return mcp.NewRequestId(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() <= math.MaxInt64 {
+			return mcp.NewRequestId(int64(value.Uint())), nil
+		}
+	}
+	return mcp.RequestId{}, fmt.Errorf("invalid request ID %v of type %T: must be a string or an integer", id, id)
+}
+
+// requestIDKey is the context key for storing the ID of the request being sent
+type requestIDKey struct{}
+
+// RequestIDFromContext retrieves the JSON-RPC ID of the request from the
+// context passed to a RequestObserverFunc.
+func RequestIDFromContext(ctx context.Context) (mcp.RequestId, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(mcp.RequestId)
+	return id, ok
+}
+
+// ProgressHandler receives progress updates for a long-running request.
+type ProgressHandler func(progress, total float64, message string)
+
+// RequestOption configures a single tool call made with CallToolWithOptions or
+// CallToolStream.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	progressHandler ProgressHandler
+	timeout         time.Duration
+}
+
+// WithProgressHandler attaches a progress token to the request and calls handler
+// for every progress notification the server sends for it, until the request returns.
+func WithProgressHandler(handler ProgressHandler) RequestOption {
+	return func(o *requestOptions) {
+		o.progressHandler = handler
+	}
+}
+
+// WithToolCallTimeout sets the timeout of the tool call, overriding the timeout
+// set with WithDefaultRequestTimeout. Other requests keep the default timeout.
+func WithToolCallTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+func newRequestOptions(opts []RequestOption) requestOptions {
+	var options requestOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 // NewClient creates a new MCP client with the given transport.
 // Usage:
 //
@@ -43,7 +209,9 @@ func WithClientCapabilities(capabilities mcp.ClientCapabilities) ClientOption {
 //	}
 func NewClient(transport transport.Interface, options ...ClientOption) *Client {
 	client := &Client{
-		transport: transport,
+		transport:        transport,
+		progressHandlers: make(map[string]ProgressHandler),
+		outputHandlers:   make(map[string]func([]mcp.Content)),
 	}
 
 	for _, opt := range options {
@@ -59,12 +227,32 @@ func (c *Client) Start(ctx context.Context) error {
 	if c.transport == nil {
 		return fmt.Errorf("transport is nil")
 	}
+	if err := validateRoots(c.roots); err != nil {
+		return err
+	}
 	err := c.transport.Start(ctx)
 	if err != nil {
 		return err
 	}
 
+	if bidirectional, ok := c.transport.(transport.BidirectionalInterface); ok {
+		bidirectional.SetRequestHandler(c.handleIncomingRequest)
+	}
+
 	c.transport.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == "notifications/progress" {
+			c.handleProgress(notification)
+		}
+		if notification.Method == mcp.MethodNotificationMessage {
+			c.handleLogMessage(notification)
+		}
+		if notification.Method == mcp.MethodNotificationToolsListChanged {
+			c.invalidateTools()
+		}
+		if notification.Method == mcp.MethodNotificationToolOutput {
+			c.handleToolOutput(notification)
+		}
+
 		c.notifyMu.RLock()
 		defer c.notifyMu.RUnlock()
 		for _, handler := range c.notifications {
@@ -74,6 +262,79 @@ func (c *Client) Start(ctx context.Context) error {
 	return nil
 }
 
+// requestError is an error answered to the server with a specific JSON-RPC error code.
+type requestError struct {
+	code    int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// handleIncomingRequest answers requests sent by the server.
+// Errors are mapped to JSON-RPC error responses.
+func (c *Client) handleIncomingRequest(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*transport.JSONRPCResponse, error) {
+	result, err := c.dispatchIncomingRequest(ctx, request)
+	if err != nil {
+		var reqErr *requestError
+		if errors.As(err, &reqErr) {
+			return transport.NewJSONRPCErrorResponse(request.ID, reqErr.code, reqErr.message), nil
+		}
+		return transport.NewJSONRPCErrorResponse(request.ID, mcp.INTERNAL_ERROR, err.Error()), nil
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return transport.NewJSONRPCResultResponse(request.ID, data), nil
+}
+
+func (c *Client) dispatchIncomingRequest(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (any, error) {
+	switch request.Method {
+	case string(mcp.MethodPing):
+		return struct{}{}, nil
+	case string(mcp.MethodRootsList):
+		if roots, ok := c.listRoots(); ok {
+			return mcp.ListRootsResult{Roots: roots}, nil
+		}
+	case string(mcp.MethodSamplingCreateMessage):
+		if c.samplingHandler != nil {
+			return c.handleCreateMessage(ctx, request)
+		}
+	case string(mcp.MethodElicitationCreate):
+		if c.elicitationHandler != nil {
+			return c.handleElicit(ctx, request)
+		}
+	}
+	return nil, &requestError{
+		code:    mcp.METHOD_NOT_FOUND,
+		message: fmt.Sprintf("method not found: %s", request.Method),
+	}
+}
+
+// unmarshalParams decodes the params of an incoming request into v.
+func unmarshalParams(request transport.JSONRPCRequest, v any) error {
+	data, err := json.Marshal(request.Params)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		return &requestError{
+			code:    mcp.INVALID_PARAMS,
+			message: fmt.Sprintf("invalid params: %v", err),
+		}
+	}
+	return nil
+}
+
 // Close shuts down the client and closes the transport.
 func (c *Client) Close() error {
 	return c.transport.Close()
@@ -89,28 +350,113 @@ func (c *Client) OnNotification(
 	c.notifications = append(c.notifications, handler)
 }
 
-// sendRequest sends a JSON-RPC request to the server and waits for a response.
+// OnLogMessage registers a handler function to be called for every log message
+// notification sent by the server. Use SetLevel to choose the minimum level the
+// server sends; by default the server sends messages at info level and above.
+func (c *Client) OnLogMessage(
+	handler func(notification mcp.LoggingMessageNotification),
+) {
+	c.notifyMu.Lock()
+	defer c.notifyMu.Unlock()
+	c.logHandlers = append(c.logHandlers, handler)
+}
+
+// handleLogMessage decodes a notifications/message notification and passes it to the log handlers.
+func (c *Client) handleLogMessage(notification mcp.JSONRPCNotification) {
+	c.notifyMu.RLock()
+	handlers := c.logHandlers
+	c.notifyMu.RUnlock()
+	if len(handlers) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(notification.Params)
+	if err != nil {
+		return
+	}
+	logMessage := mcp.LoggingMessageNotification{
+		Notification: notification.Notification,
+	}
+	if err := json.Unmarshal(data, &logMessage.Params); err != nil {
+		return
+	}
+
+	for _, handler := range handlers {
+		handler(logMessage)
+	}
+}
+
+// sendRequest sends a JSON-RPC request to the server and waits for a response,
+// using the client's default request timeout.
 // Returns the raw JSON response message or an error if the request fails.
 func (c *Client) sendRequest(
 	ctx context.Context,
 	method string,
 	params any,
 ) (*json.RawMessage, error) {
+	return c.sendRequestWithTimeout(ctx, method, params, c.requestTimeout)
+}
+
+// sendRequestWithTimeout is like sendRequest, but bounds the request by timeout if it is positive.
+func (c *Client) sendRequestWithTimeout(
+	ctx context.Context,
+	method string,
+	params any,
+	timeout time.Duration,
+) (result *json.RawMessage, err error) {
 	if !c.initialized && method != "initialize" {
 		return nil, fmt.Errorf("client not initialized")
 	}
 
-	id := c.requestID.Add(1)
+	ctx, params, err = addMetadata(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := c.nextRequestID()
+	if err != nil {
+		return nil, err
+	}
 
 	request := transport.JSONRPCRequest{
 		JSONRPC: mcp.JSONRPC_VERSION,
-		ID:      mcp.NewRequestId(id),
+		ID:      id,
 		Method:  method,
 		Params:  params,
 	}
 
+	ctx = context.WithValue(ctx, requestIDKey{}, request.ID)
+	if len(c.requestObservers) > 0 {
+		observeCtx, start := ctx, time.Now()
+		defer func() {
+			for _, observer := range c.requestObservers {
+				observer(observeCtx, method, start, err)
+			}
+		}()
+	}
+
+	if timeout > 0 {
+		// An earlier deadline of the parent context still applies
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrRequestTimeout)
+		defer cancel()
+	}
+
 	response, err := c.transport.SendRequest(ctx, request)
 	if err != nil {
+		timedOut := context.Cause(ctx) == ErrRequestTimeout
+		// The caller gave up on the request, let the server know so it can stop processing.
+		// A client MUST NOT attempt to cancel its initialize request.
+		if ctx.Err() != nil && method != string(mcp.MethodInitialize) {
+			reason := ctx.Err().Error()
+			if timedOut {
+				reason = ErrRequestTimeout.Error()
+			}
+			c.sendCancellation(ctx, request.ID, reason)
+		}
+		if timedOut {
+			return nil, fmt.Errorf("%s: %w after %v", method, ErrRequestTimeout, timeout)
+		}
 		return nil, fmt.Errorf("transport error: %w", err)
 	}
 
@@ -121,6 +467,72 @@ func (c *Client) sendRequest(
 	return &response.Result, nil
 }
 
+// cancellationTimeout bounds how long sending a cancellation notification may take.
+const cancellationTimeout = 5 * time.Second
+
+// sendCancellation notifies the server that the request with the given ID has been cancelled.
+// It is best-effort: failures are ignored since the caller has already given up on the request.
+func (c *Client) sendCancellation(ctx context.Context, id mcp.RequestId, reason string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancellationTimeout)
+	defer cancel()
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationCancelled,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"requestId": id,
+					"reason":    reason,
+				},
+			},
+		},
+	}
+	_ = c.transport.SendNotification(ctx, notification)
+}
+
+// newProgressToken generates a new progress token.
+func (c *Client) newProgressToken() string {
+	return fmt.Sprintf("progress-%d", c.progressToken.Add(1))
+}
+
+// registerProgressHandler generates a new progress token and routes progress notifications for it to handler.
+func (c *Client) registerProgressHandler(handler ProgressHandler) mcp.ProgressToken {
+	token := c.newProgressToken()
+	c.progressMu.Lock()
+	c.progressHandlers[token] = handler
+	c.progressMu.Unlock()
+	return token
+}
+
+func (c *Client) unregisterProgressHandler(token mcp.ProgressToken) {
+	c.progressMu.Lock()
+	delete(c.progressHandlers, fmt.Sprint(token))
+	delete(c.outputHandlers, fmt.Sprint(token))
+	c.progressMu.Unlock()
+}
+
+// handleProgress dispatches a progress notification to the handler registered for its token.
+func (c *Client) handleProgress(notification mcp.JSONRPCNotification) {
+	fields := notification.Params.AdditionalFields
+	token, ok := fields["progressToken"]
+	if !ok {
+		return
+	}
+
+	c.progressMu.RLock()
+	handler, ok := c.progressHandlers[fmt.Sprint(token)]
+	c.progressMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	progress, _ := fields["progress"].(float64)
+	total, _ := fields["total"].(float64)
+	message, _ := fields["message"].(string)
+	handler(progress, total, message)
+}
+
 // Initialize negotiates with the server.
 // Must be called after Start, and before any request methods.
 func (c *Client) Initialize(
@@ -137,6 +549,23 @@ func (c *Client) Initialize(
 		ClientInfo:      request.Params.ClientInfo,
 		Capabilities:    request.Params.Capabilities, // Will be empty struct if not set
 	}
+	if params.ProtocolVersion == "" {
+		params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	}
+	c.rootsMu.RLock()
+	rootsEnabled := c.rootsEnabled
+	c.rootsMu.RUnlock()
+	if rootsEnabled && params.Capabilities.Roots == nil {
+		params.Capabilities.Roots = &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{ListChanged: true}
+	}
+	if c.samplingHandler != nil && params.Capabilities.Sampling == nil {
+		params.Capabilities.Sampling = &struct{}{}
+	}
+	if c.elicitationHandler != nil && params.Capabilities.Elicitation == nil {
+		params.Capabilities.Elicitation = &struct{}{}
+	}
 
 	response, err := c.sendRequest(ctx, "initialize", params)
 	if err != nil {
@@ -148,6 +577,20 @@ func (c *Client) Initialize(
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	supported := c.protocolVersions
+	if supported == nil {
+		supported = mcp.ValidProtocolVersions
+	}
+	if !slices.Contains(supported, result.ProtocolVersion) {
+		return nil, fmt.Errorf(
+			"%w: server returned %q, supported versions are %s",
+			ErrUnsupportedProtocolVersion,
+			result.ProtocolVersion,
+			strings.Join(supported, ", "),
+		)
+	}
+	c.protocolVersion = result.ProtocolVersion
+
 	// Store serverCapabilities
 	c.serverCapabilities = result.Capabilities
 
@@ -155,7 +598,7 @@ func (c *Client) Initialize(
 	notification := mcp.JSONRPCNotification{
 		JSONRPC: mcp.JSONRPC_VERSION,
 		Notification: mcp.Notification{
-			Method: "notifications/initialized",
+			Method: mcp.MethodNotificationInitialized,
 		},
 	}
 
@@ -167,6 +610,12 @@ func (c *Client) Initialize(
 		)
 	}
 
+	c.rootsMu.Lock()
+	c.rootsNegotiated = true
+	c.rootsAdvertised = params.Capabilities.Roots != nil
+	c.rootsListChanged = c.rootsAdvertised && params.Capabilities.Roots.ListChanged
+	c.rootsMu.Unlock()
+
 	c.initialized = true
 	return &result, nil
 }
@@ -181,6 +630,9 @@ func (c *Client) ListResourcesByPage(
 	ctx context.Context,
 	request mcp.ListResourcesRequest,
 ) (*mcp.ListResourcesResult, error) {
+	if err := c.requireCapability("resources", c.SupportsResources()); err != nil {
+		return nil, err
+	}
 	result, err := listByPage[mcp.ListResourcesResult](ctx, c, request.PaginatedRequest, "resources/list")
 	if err != nil {
 		return nil, err
@@ -217,6 +669,9 @@ func (c *Client) ListResourceTemplatesByPage(
 	ctx context.Context,
 	request mcp.ListResourceTemplatesRequest,
 ) (*mcp.ListResourceTemplatesResult, error) {
+	if err := c.requireCapability("resources", c.SupportsResources()); err != nil {
+		return nil, err
+	}
 	result, err := listByPage[mcp.ListResourceTemplatesResult](ctx, c, request.PaginatedRequest, "resources/templates/list")
 	if err != nil {
 		return nil, err
@@ -253,6 +708,9 @@ func (c *Client) ReadResource(
 	ctx context.Context,
 	request mcp.ReadResourceRequest,
 ) (*mcp.ReadResourceResult, error) {
+	if err := c.requireCapability("resources", c.SupportsResources()); err != nil {
+		return nil, err
+	}
 	response, err := c.sendRequest(ctx, "resources/read", request.Params)
 	if err != nil {
 		return nil, err
@@ -261,10 +719,91 @@ func (c *Client) ReadResource(
 	return mcp.ParseReadResourceResult(response)
 }
 
+// ReadResourceRange reads length bytes of the resource at uri starting at
+// offset; a length of zero reads to the end of the resource. Servers that
+// cannot serve a range for the resource answer with its full contents, which
+// are then sliced by the client, so the result always holds the requested
+// range. Like the range of a served slice, the Range of the result is the
+// requested one, while the contents are shorter if the resource ends first,
+// and empty if it ends before offset. Text contents sliced by the client inside a multi-byte
+// character are returned as blob contents holding the exact bytes.
+func (c *Client) ReadResourceRange(
+	ctx context.Context,
+	uri string,
+	offset int64,
+	length int64,
+) (*mcp.ReadResourceResult, error) {
+	if offset < 0 || length < 0 {
+		return nil, fmt.Errorf("invalid range: offset %d, length %d", offset, length)
+	}
+	byteRange := mcp.ByteRange{Offset: offset, Length: length}
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = uri
+	request.Params.Range = &byteRange
+
+	result, err := c.ReadResource(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.Range != nil {
+		return result, nil
+	}
+
+	// The server returned the full resource
+	for i, contents := range result.Contents {
+		sliced, err := sliceResourceContents(contents, byteRange)
+		if err != nil {
+			return nil, err
+		}
+		result.Contents[i] = sliced
+	}
+	result.Range = &byteRange
+	return result, nil
+}
+
+// sliceResourceContents returns the given range of the bytes of contents.
+// Text sliced inside a multi-byte character is not valid UTF-8, so it is
+// returned as a blob of the exact bytes instead.
+func sliceResourceContents(contents mcp.ResourceContents, byteRange mcp.ByteRange) (mcp.ResourceContents, error) {
+	slice := func(data []byte) []byte {
+		start := min(byteRange.Offset, int64(len(data)))
+		end := int64(len(data))
+		if byteRange.Length > 0 {
+			end = min(start+byteRange.Length, end)
+		}
+		return data[start:end]
+	}
+
+	switch contents := contents.(type) {
+	case mcp.TextResourceContents:
+		data := slice([]byte(contents.Text))
+		if !utf8.Valid(data) {
+			return mcp.BlobResourceContents{
+				URI:      contents.URI,
+				MIMEType: contents.MIMEType,
+				Blob:     base64.StdEncoding.EncodeToString(data),
+			}, nil
+		}
+		contents.Text = string(data)
+		return contents, nil
+	case mcp.BlobResourceContents:
+		data, err := base64.StdEncoding.DecodeString(contents.Blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode blob of %s: %w", contents.URI, err)
+		}
+		contents.Blob = base64.StdEncoding.EncodeToString(slice(data))
+		return contents, nil
+	}
+	return contents, nil
+}
+
 func (c *Client) Subscribe(
 	ctx context.Context,
 	request mcp.SubscribeRequest,
 ) error {
+	if err := c.requireCapability("resources subscription", c.SupportsResourceSubscriptions()); err != nil {
+		return err
+	}
 	_, err := c.sendRequest(ctx, "resources/subscribe", request.Params)
 	return err
 }
@@ -273,6 +812,9 @@ func (c *Client) Unsubscribe(
 	ctx context.Context,
 	request mcp.UnsubscribeRequest,
 ) error {
+	if err := c.requireCapability("resources subscription", c.SupportsResourceSubscriptions()); err != nil {
+		return err
+	}
 	_, err := c.sendRequest(ctx, "resources/unsubscribe", request.Params)
 	return err
 }
@@ -281,6 +823,9 @@ func (c *Client) ListPromptsByPage(
 	ctx context.Context,
 	request mcp.ListPromptsRequest,
 ) (*mcp.ListPromptsResult, error) {
+	if err := c.requireCapability("prompts", c.SupportsPrompts()); err != nil {
+		return nil, err
+	}
 	result, err := listByPage[mcp.ListPromptsResult](ctx, c, request.PaginatedRequest, "prompts/list")
 	if err != nil {
 		return nil, err
@@ -317,6 +862,9 @@ func (c *Client) GetPrompt(
 	ctx context.Context,
 	request mcp.GetPromptRequest,
 ) (*mcp.GetPromptResult, error) {
+	if err := c.requireCapability("prompts", c.SupportsPrompts()); err != nil {
+		return nil, err
+	}
 	response, err := c.sendRequest(ctx, "prompts/get", request.Params)
 	if err != nil {
 		return nil, err
@@ -329,6 +877,9 @@ func (c *Client) ListToolsByPage(
 	ctx context.Context,
 	request mcp.ListToolsRequest,
 ) (*mcp.ListToolsResult, error) {
+	if err := c.requireCapability("tools", c.SupportsTools()); err != nil {
+		return nil, err
+	}
 	result, err := listByPage[mcp.ListToolsResult](ctx, c, request.PaginatedRequest, "tools/list")
 	if err != nil {
 		return nil, err
@@ -336,10 +887,14 @@ func (c *Client) ListToolsByPage(
 	return result, nil
 }
 
+// ListTools lists all tools, following the pagination cursors.
+// Listing from the first page populates the tool cache used by GetTool.
 func (c *Client) ListTools(
 	ctx context.Context,
 	request mcp.ListToolsRequest,
 ) (*mcp.ListToolsResult, error) {
+	fromStart := request.Params.Cursor == ""
+	generation := c.toolCacheGeneration()
 	result, err := c.ListToolsByPage(ctx, request)
 	if err != nil {
 		return nil, err
@@ -358,6 +913,9 @@ func (c *Client) ListTools(
 			result.NextCursor = newPageRes.NextCursor
 		}
 	}
+	if fromStart {
+		c.storeTools(generation, result.Tools)
+	}
 	return result, nil
 }
 
@@ -365,7 +923,41 @@ func (c *Client) CallTool(
 	ctx context.Context,
 	request mcp.CallToolRequest,
 ) (*mcp.CallToolResult, error) {
-	response, err := c.sendRequest(ctx, "tools/call", request.Params)
+	return c.CallToolWithOptions(ctx, request)
+}
+
+// CallToolWithOptions invokes a tool on the server like CallTool, configured
+// by per-call options such as WithProgressHandler and WithToolCallTimeout.
+func (c *Client) CallToolWithOptions(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	opts ...RequestOption,
+) (*mcp.CallToolResult, error) {
+	if err := c.requireCapability("tools", c.SupportsTools()); err != nil {
+		return nil, err
+	}
+	options := newRequestOptions(opts)
+	if options.progressHandler != nil {
+		token := c.registerProgressHandler(options.progressHandler)
+		defer c.unregisterProgressHandler(token)
+		request = withProgressToken(request, token)
+	}
+
+	return c.callTool(ctx, request, options)
+}
+
+// callTool sends the tools/call request with the timeout of options.
+func (c *Client) callTool(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	options requestOptions,
+) (*mcp.CallToolResult, error) {
+	timeout := c.requestTimeout
+	if options.timeout > 0 {
+		timeout = options.timeout
+	}
+
+	response, err := c.sendRequestWithTimeout(ctx, "tools/call", request.Params, timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -373,10 +965,25 @@ func (c *Client) CallTool(
 	return mcp.ParseCallToolResult(response)
 }
 
+// withProgressToken returns a copy of request that carries the progress token.
+func withProgressToken(request mcp.CallToolRequest, token mcp.ProgressToken) mcp.CallToolRequest {
+	// Copy the meta so the caller's request is left untouched
+	meta := mcp.Meta{}
+	if request.Params.Meta != nil {
+		meta = *request.Params.Meta
+	}
+	meta.ProgressToken = token
+	request.Params.Meta = &meta
+	return request
+}
+
 func (c *Client) SetLevel(
 	ctx context.Context,
 	request mcp.SetLevelRequest,
 ) error {
+	if err := c.requireCapability("logging", c.SupportsLogging()); err != nil {
+		return err
+	}
 	_, err := c.sendRequest(ctx, "logging/setLevel", request.Params)
 	return err
 }
@@ -428,7 +1035,48 @@ func (c *Client) GetServerCapabilities() mcp.ServerCapabilities {
 	return c.serverCapabilities
 }
 
+// ProtocolVersion returns the protocol version negotiated with the server
+// during initialization, or an empty string before initialization.
+func (c *Client) ProtocolVersion() string {
+	return c.protocolVersion
+}
+
 // GetClientCapabilities returns the client capabilities.
 func (c *Client) GetClientCapabilities() mcp.ClientCapabilities {
 	return c.clientCapabilities
 }
+
+// SupportsTools reports whether the server advertised the tools capability.
+func (c *Client) SupportsTools() bool {
+	return c.serverCapabilities.Tools != nil
+}
+
+// SupportsResources reports whether the server advertised the resources capability.
+func (c *Client) SupportsResources() bool {
+	return c.serverCapabilities.Resources != nil
+}
+
+// SupportsResourceSubscriptions reports whether the server supports subscribing to resource updates.
+func (c *Client) SupportsResourceSubscriptions() bool {
+	return c.serverCapabilities.Resources != nil && c.serverCapabilities.Resources.Subscribe
+}
+
+// SupportsPrompts reports whether the server advertised the prompts capability.
+func (c *Client) SupportsPrompts() bool {
+	return c.serverCapabilities.Prompts != nil
+}
+
+// SupportsLogging reports whether the server advertised the logging capability.
+func (c *Client) SupportsLogging() bool {
+	return c.serverCapabilities.Logging != nil
+}
+
+// requireCapability returns ErrCapabilityNotSupported if the client is initialized
+// and the server did not advertise the named capability.
+// Uninitialized clients are left to fail in sendRequest.
+func (c *Client) requireCapability(name string, supported bool) error {
+	if !c.initialized || supported {
+		return nil
+	}
+	return fmt.Errorf("server does not support %s: %w", name, ErrCapabilityNotSupported)
+}
@@ -0,0 +1,683 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// fakeTransport is a transport.Interface used to exercise the client without a server.
+// Requests are answered by respond; if respond is nil, requests block until the context is done.
+type fakeTransport struct {
+	mu            sync.Mutex
+	requests      []transport.JSONRPCRequest
+	notifications []mcp.JSONRPCNotification
+	respond       func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error)
+	onNotify      func(mcp.JSONRPCNotification)
+	onRequest     transport.RequestHandler
+}
+
+func (f *fakeTransport) Start(ctx context.Context) error { return nil }
+
+func (f *fakeTransport) SendRequest(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	respond := f.respond
+	f.mu.Unlock()
+
+	if respond != nil {
+		return respond(ctx, request)
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (f *fakeTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.notifications = append(f.notifications, notification)
+	return nil
+}
+
+func (f *fakeTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onNotify = handler
+}
+
+func (f *fakeTransport) SetRequestHandler(handler transport.RequestHandler) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onRequest = handler
+}
+
+func (f *fakeTransport) Close() error { return nil }
+
+// serverRequest simulates a request sent by the server and returns the client's response.
+func (f *fakeTransport) serverRequest(t *testing.T, method string, params any) *transport.JSONRPCResponse {
+	t.Helper()
+	f.mu.Lock()
+	handler := f.onRequest
+	f.mu.Unlock()
+	if handler == nil {
+		t.Fatal("No request handler set on transport")
+	}
+	response, err := handler(context.Background(), transport.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		t.Fatalf("Request handler failed: %v", err)
+	}
+	return response
+}
+
+// notificationsWithMethod returns the notifications sent through the transport with the given method.
+func (f *fakeTransport) notificationsWithMethod(method string) []mcp.JSONRPCNotification {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []mcp.JSONRPCNotification
+	for _, n := range f.notifications {
+		if n.Method == method {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// newInitializedClient returns a client that behaves as if initialization
+// completed with the given server capabilities JSON.
+func newInitializedClient(t *testing.T, ft *fakeTransport, capabilities string) *Client {
+	t.Helper()
+	c := NewClient(ft)
+	if err := json.Unmarshal([]byte(capabilities), &c.serverCapabilities); err != nil {
+		t.Fatalf("Failed to unmarshal capabilities: %v", err)
+	}
+	c.initialized = true
+	return c
+}
+
+// resultResponse builds a successful response for the request with the given result.
+func resultResponse(request transport.JSONRPCRequest, result any) *transport.JSONRPCResponse {
+	data, _ := json.Marshal(result)
+	return &transport.JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      request.ID,
+		Result:  data,
+	}
+}
+
+func TestClientSendsCancellationOnContextDone(t *testing.T) {
+	ft := &fakeTransport{}
+	c := newInitializedClient(t, ft, `{"tools":{}}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "slow-tool"
+	_, err := c.CallTool(ctx, request)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded error, got: %v", err)
+	}
+
+	cancellations := ft.notificationsWithMethod(mcp.MethodNotificationCancelled)
+	if len(cancellations) != 1 {
+		t.Fatalf("Expected 1 cancellation notification, got %d", len(cancellations))
+	}
+
+	data, err := json.Marshal(cancellations[0])
+	if err != nil {
+		t.Fatalf("Failed to marshal notification: %v", err)
+	}
+	var decoded struct {
+		Params mcp.CancelledNotificationParams `json:"params"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal notification: %v", err)
+	}
+	if decoded.Params.RequestId.String() != ft.requests[0].ID.String() {
+		t.Errorf("Expected cancellation for request %s, got %s", ft.requests[0].ID, decoded.Params.RequestId)
+	}
+}
+
+func TestClientDoesNotCancelCompletedRequest(t *testing.T) {
+	ft := &fakeTransport{
+		respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			return resultResponse(request, mcp.NewToolResultText("done")), nil
+		},
+	}
+	c := newInitializedClient(t, ft, `{"tools":{}}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "fast-tool"
+	if _, err := c.CallTool(ctx, request); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	cancel()
+
+	if got := len(ft.notificationsWithMethod(mcp.MethodNotificationCancelled)); got != 0 {
+		t.Errorf("Expected no cancellation notification, got %d", got)
+	}
+}
+
+func TestClientDoesNotCancelInitialize(t *testing.T) {
+	ft := &fakeTransport{}
+	c := NewClient(ft)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.Initialize(ctx, mcp.InitializeRequest{}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if got := len(ft.notificationsWithMethod(mcp.MethodNotificationCancelled)); got != 0 {
+		t.Errorf("Expected no cancellation notification for initialize, got %d", got)
+	}
+}
+
+func TestClientCapabilityChecks(t *testing.T) {
+	ft := &fakeTransport{
+		respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			return resultResponse(request, map[string]any{}), nil
+		},
+	}
+	c := newInitializedClient(t, ft, `{"tools":{"listChanged":true},"resources":{}}`)
+
+	if !c.SupportsTools() {
+		t.Error("Expected SupportsTools to be true")
+	}
+	if !c.SupportsResources() {
+		t.Error("Expected SupportsResources to be true")
+	}
+	if c.SupportsResourceSubscriptions() {
+		t.Error("Expected SupportsResourceSubscriptions to be false")
+	}
+	if c.SupportsPrompts() {
+		t.Error("Expected SupportsPrompts to be false")
+	}
+	if c.SupportsLogging() {
+		t.Error("Expected SupportsLogging to be false")
+	}
+
+	ctx := context.Background()
+	if _, err := c.ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
+		t.Errorf("ListTools failed: %v", err)
+	}
+	if _, err := c.ListResources(ctx, mcp.ListResourcesRequest{}); err != nil {
+		t.Errorf("ListResources failed: %v", err)
+	}
+
+	unsupported := map[string]func() error{
+		"ListPrompts": func() error {
+			_, err := c.ListPrompts(ctx, mcp.ListPromptsRequest{})
+			return err
+		},
+		"GetPrompt": func() error {
+			_, err := c.GetPrompt(ctx, mcp.GetPromptRequest{})
+			return err
+		},
+		"Subscribe": func() error {
+			return c.Subscribe(ctx, mcp.SubscribeRequest{})
+		},
+		"SetLevel": func() error {
+			return c.SetLevel(ctx, mcp.SetLevelRequest{})
+		},
+	}
+	for name, call := range unsupported {
+		if err := call(); !errors.Is(err, ErrCapabilityNotSupported) {
+			t.Errorf("%s: expected ErrCapabilityNotSupported, got: %v", name, err)
+		}
+	}
+
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	if len(ft.requests) != 2 {
+		t.Errorf("Expected only the supported requests to reach the transport, got %d requests", len(ft.requests))
+	}
+}
+
+func TestClientOnLogMessage(t *testing.T) {
+	ft := &fakeTransport{}
+	c := NewClient(ft)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	var received []mcp.LoggingMessageNotification
+	c.OnLogMessage(func(notification mcp.LoggingMessageNotification) {
+		received = append(received, notification)
+	})
+
+	ft.onNotify(mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: "notifications/progress",
+		},
+	})
+	ft.onNotify(mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationMessage,
+			Params: mcp.NotificationParams{
+				AdditionalFields: map[string]any{
+					"level":  "warning",
+					"logger": "db",
+					"data":   "slow query",
+				},
+			},
+		},
+	})
+
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 log message, got %d", len(received))
+	}
+	params := received[0].Params
+	if params.Level != mcp.LoggingLevelWarning || params.Logger != "db" || params.Data != "slow query" {
+		t.Errorf("Unexpected log message params: %+v", params)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	callTool := func(c *Client, ctx context.Context, opts ...RequestOption) error {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "slow-tool"
+		_, err := c.CallToolWithOptions(ctx, request, opts...)
+		return err
+	}
+
+	t.Run("default timeout", func(t *testing.T) {
+		ft := &fakeTransport{}
+		c := newInitializedClient(t, ft, `{"tools":{}}`)
+		c.requestTimeout = 20 * time.Millisecond
+
+		err := callTool(c, context.Background())
+		if !errors.Is(err, ErrRequestTimeout) {
+			t.Fatalf("Expected ErrRequestTimeout, got: %v", err)
+		}
+		if got := len(ft.notificationsWithMethod(mcp.MethodNotificationCancelled)); got != 1 {
+			t.Errorf("Expected 1 cancellation notification, got %d", got)
+		}
+
+		// The default applies to every request
+		if err := c.Ping(context.Background()); !errors.Is(err, ErrRequestTimeout) {
+			t.Errorf("Expected ErrRequestTimeout for ping, got: %v", err)
+		}
+	})
+
+	t.Run("request option overrides default", func(t *testing.T) {
+		ft := &fakeTransport{
+			respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+				select {
+				case <-time.After(50 * time.Millisecond):
+					return resultResponse(request, mcp.NewToolResultText("done")), nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			},
+		}
+		c := newInitializedClient(t, ft, `{"tools":{}}`)
+		c.requestTimeout = 10 * time.Millisecond
+
+		if err := callTool(c, context.Background(), WithToolCallTimeout(time.Second)); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if err := callTool(c, context.Background()); !errors.Is(err, ErrRequestTimeout) {
+			t.Errorf("Expected ErrRequestTimeout without override, got: %v", err)
+		}
+	})
+
+	t.Run("shorter context deadline wins", func(t *testing.T) {
+		ft := &fakeTransport{}
+		c := newInitializedClient(t, ft, `{"tools":{}}`)
+		c.requestTimeout = time.Minute
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := callTool(c, ctx)
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRequestTimeout) {
+			t.Fatalf("Expected context deadline exceeded, got: %v", err)
+		}
+	})
+}
+
+func TestClientWithMockTransport(t *testing.T) {
+	mock := transport.NewMockTransport()
+	initResult := mcp.InitializeResult{
+		ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+		ServerInfo:      mcp.Implementation{Name: "mock", Version: "1.0.0"},
+	}
+	initResult.Capabilities.Tools = &struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{}
+	if err := mock.RespondWith("initialize", initResult); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	if err := mock.RespondWithDelay("tools/call", time.Second, mcp.NewToolResultText("done")); err != nil {
+		t.Fatalf("RespondWithDelay failed: %v", err)
+	}
+
+	c := NewClient(mock, WithDefaultRequestTimeout(20*time.Millisecond))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	var changed []string
+	c.OnNotification(func(notification mcp.JSONRPCNotification) {
+		changed = append(changed, notification.Method)
+	})
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(context.Background(), request); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if got := len(mock.Notifications()); got != 1 {
+		t.Errorf("Expected initialized notification, got %d notifications", got)
+	}
+
+	callRequest := mcp.CallToolRequest{}
+	callRequest.Params.Name = "slow"
+	if _, err := c.CallTool(context.Background(), callRequest); !errors.Is(err, ErrRequestTimeout) {
+		t.Errorf("Expected ErrRequestTimeout, got %v", err)
+	}
+
+	mock.SimulateNotification(mcp.MethodNotificationToolsListChanged, nil)
+	if len(changed) != 1 || changed[0] != mcp.MethodNotificationToolsListChanged {
+		t.Errorf("Unexpected notifications: %v", changed)
+	}
+}
+
+func TestClientRequestObserver(t *testing.T) {
+	mock := transport.NewMockTransport()
+	initResult := mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}
+	initResult.Capabilities.Tools = &struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{}
+	if err := mock.RespondWith("initialize", initResult); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	if err := mock.RespondWith("ping", struct{}{}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	mock.RespondWithError("tools/call", mcp.INVALID_PARAMS, "bad arguments")
+
+	var methods, ids []string
+	var errs []error
+	c := NewClient(mock, WithRequestObserver(func(ctx context.Context, method string, start time.Time, err error) {
+		if start.IsZero() {
+			t.Error("Expected the start time of the request")
+		}
+		id, ok := RequestIDFromContext(ctx)
+		if !ok {
+			t.Error("Expected the request ID in the context")
+		}
+		methods = append(methods, method)
+		ids = append(ids, id.String())
+		errs = append(errs, err)
+	}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(context.Background(), request); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if err := c.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if _, err := c.CallTool(context.Background(), mcp.CallToolRequest{}); err == nil {
+		t.Fatal("Expected CallTool to fail")
+	}
+
+	expectedMethods := []string{"initialize", "ping", "tools/call"}
+	if fmt.Sprint(methods) != fmt.Sprint(expectedMethods) {
+		t.Errorf("Expected observed methods %v, got %v", expectedMethods, methods)
+	}
+	expectedIDs := []string{"int64:1", "int64:2", "int64:3"}
+	if fmt.Sprint(ids) != fmt.Sprint(expectedIDs) {
+		t.Errorf("Expected observed IDs %v, got %v", expectedIDs, ids)
+	}
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("Expected successful requests to be observed without error, got %v", errs)
+	}
+	if errs[2] == nil || errs[2].Error() != "bad arguments" {
+		t.Errorf("Expected the error of the failed request, got %v", errs[2])
+	}
+}
+
+func TestClientReadResourceRange(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	c.serverCapabilities.Resources = &struct {
+		Subscribe   bool `json:"subscribe,omitempty"`
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{}
+	blob := base64.StdEncoding.EncodeToString([]byte("0123456789"))
+
+	t.Run("Server serves the range", func(t *testing.T) {
+		mock.Handle("resources/read", func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			params := request.Params.(mcp.ReadResourceParams)
+			if params.Range == nil || *params.Range != (mcp.ByteRange{Offset: 4, Length: 2}) {
+				t.Errorf("Expected range 4+2 in the request, got %+v", params.Range)
+			}
+			return transport.NewJSONRPCResultResponse(request.ID, []byte(
+				`{"contents":[{"uri":"blob://data","blob":"NDU="}],"range":{"offset":4,"length":2}}`,
+			)), nil
+		})
+		result, err := c.ReadResourceRange(context.Background(), "blob://data", 4, 2)
+		if err != nil {
+			t.Fatalf("ReadResourceRange failed: %v", err)
+		}
+		if got := result.Contents[0].(mcp.BlobResourceContents).Blob; got != "NDU=" {
+			t.Errorf("Expected the served slice, got %q", got)
+		}
+	})
+
+	t.Run("Client slices full contents", func(t *testing.T) {
+		if err := mock.RespondWith("resources/read", mcp.ReadResourceResult{Contents: []mcp.ResourceContents{
+			mcp.BlobResourceContents{URI: "blob://data", Blob: blob},
+			mcp.TextResourceContents{URI: "text://data", Text: "0123456789"},
+		}}); err != nil {
+			t.Fatalf("RespondWith failed: %v", err)
+		}
+
+		result, err := c.ReadResourceRange(context.Background(), "blob://data", 8, 5)
+		if err != nil {
+			t.Fatalf("ReadResourceRange failed: %v", err)
+		}
+		if result.Range == nil || *result.Range != (mcp.ByteRange{Offset: 8, Length: 5}) {
+			t.Errorf("Expected the requested range in the result, got %+v", result.Range)
+		}
+		data, _ := base64.StdEncoding.DecodeString(result.Contents[0].(mcp.BlobResourceContents).Blob)
+		if string(data) != "89" {
+			t.Errorf("Expected blob slice %q, got %q", "89", data)
+		}
+		if text := result.Contents[1].(mcp.TextResourceContents).Text; text != "89" {
+			t.Errorf("Expected text slice %q, got %q", "89", text)
+		}
+
+		result, err = c.ReadResourceRange(context.Background(), "blob://data", 3, 0)
+		if err != nil {
+			t.Fatalf("ReadResourceRange failed: %v", err)
+		}
+		if text := result.Contents[1].(mcp.TextResourceContents).Text; text != "3456789" {
+			t.Errorf("Expected a zero length to read to the end, got %q", text)
+		}
+
+		result, err = c.ReadResourceRange(context.Background(), "blob://data", 12, 4)
+		if err != nil {
+			t.Fatalf("ReadResourceRange failed: %v", err)
+		}
+		if result.Range == nil || *result.Range != (mcp.ByteRange{Offset: 12, Length: 4}) {
+			t.Errorf("Expected the requested range in the result, got %+v", result.Range)
+		}
+		if blob := result.Contents[0].(mcp.BlobResourceContents).Blob; blob != "" {
+			t.Errorf("Expected an empty blob past the end, got %q", blob)
+		}
+		if text := result.Contents[1].(mcp.TextResourceContents).Text; text != "" {
+			t.Errorf("Expected an empty text past the end, got %q", text)
+		}
+	})
+
+	t.Run("Client slices multi-byte text", func(t *testing.T) {
+		if err := mock.RespondWith("resources/read", mcp.ReadResourceResult{Contents: []mcp.ResourceContents{
+			mcp.TextResourceContents{URI: "text://data", MIMEType: "text/plain", Text: "héllo"},
+		}}); err != nil {
+			t.Fatalf("RespondWith failed: %v", err)
+		}
+
+		// "é" is two bytes, the range ends between them
+		result, err := c.ReadResourceRange(context.Background(), "text://data", 0, 2)
+		if err != nil {
+			t.Fatalf("ReadResourceRange failed: %v", err)
+		}
+		blob, ok := result.Contents[0].(mcp.BlobResourceContents)
+		if !ok {
+			t.Fatalf("Expected blob contents for a split character, got %T", result.Contents[0])
+		}
+		data, _ := base64.StdEncoding.DecodeString(blob.Blob)
+		if string(data) != "h\xc3" || blob.MIMEType != "text/plain" {
+			t.Errorf("Expected the exact bytes, got %q with MIME type %q", data, blob.MIMEType)
+		}
+
+		result, err = c.ReadResourceRange(context.Background(), "text://data", 1, 2)
+		if err != nil {
+			t.Fatalf("ReadResourceRange failed: %v", err)
+		}
+		if text := result.Contents[0].(mcp.TextResourceContents).Text; text != "é" {
+			t.Errorf("Expected text slice %q, got %q", "é", text)
+		}
+	})
+
+	if _, err := c.ReadResourceRange(context.Background(), "blob://data", -1, 0); err == nil {
+		t.Error("Expected an error for a negative offset")
+	}
+}
+
+func TestClientProtocolVersion(t *testing.T) {
+	initialize := func(t *testing.T, serverVersion string, opts ...ClientOption) (*Client, *transport.MockTransport, error) {
+		t.Helper()
+		mock := transport.NewMockTransport()
+		if err := mock.RespondWith("initialize", mcp.InitializeResult{ProtocolVersion: serverVersion}); err != nil {
+			t.Fatalf("RespondWith failed: %v", err)
+		}
+		c := NewClient(mock, opts...)
+		if err := c.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		_, err := c.Initialize(context.Background(), mcp.InitializeRequest{})
+		return c, mock, err
+	}
+
+	t.Run("Negotiated version", func(t *testing.T) {
+		c, mock, err := initialize(t, "2024-11-05")
+		if err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		if c.ProtocolVersion() != "2024-11-05" {
+			t.Errorf("Expected protocol version 2024-11-05, got %q", c.ProtocolVersion())
+		}
+		// The latest version is requested when none is given
+		params := mock.Requests()[0].Params
+		data, _ := json.Marshal(params)
+		if !strings.Contains(string(data), `"protocolVersion":"`+mcp.LATEST_PROTOCOL_VERSION+`"`) {
+			t.Errorf("Expected the latest protocol version to be requested, got %s", data)
+		}
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		c, mock, err := initialize(t, "2099-01-01")
+		if !errors.Is(err, ErrUnsupportedProtocolVersion) {
+			t.Fatalf("Expected ErrUnsupportedProtocolVersion, got %v", err)
+		}
+		for _, version := range mcp.ValidProtocolVersions {
+			if !strings.Contains(err.Error(), version) {
+				t.Errorf("Expected the error to list %s, got %v", version, err)
+			}
+		}
+		if c.ProtocolVersion() != "" {
+			t.Errorf("Expected no negotiated version, got %q", c.ProtocolVersion())
+		}
+		if len(mock.Notifications()) != 0 {
+			t.Error("Expected no initialized notification")
+		}
+		if err := c.Ping(context.Background()); err == nil {
+			t.Error("Expected the client to stay uninitialized")
+		}
+	})
+
+	t.Run("Overridden versions", func(t *testing.T) {
+		c, _, err := initialize(t, "2099-01-01", WithProtocolVersions([]string{"2099-01-01"}))
+		if err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		if c.ProtocolVersion() != "2099-01-01" {
+			t.Errorf("Expected protocol version 2099-01-01, got %q", c.ProtocolVersion())
+		}
+
+		if _, _, err := initialize(t, mcp.LATEST_PROTOCOL_VERSION, WithProtocolVersions([]string{"2099-01-01"})); !errors.Is(err, ErrUnsupportedProtocolVersion) {
+			t.Errorf("Expected ErrUnsupportedProtocolVersion, got %v", err)
+		}
+	})
+}
+
+func TestClientIDGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      any
+		want    string
+		wantErr bool
+	}{
+		{name: "string", id: "4f1c2b", want: "string:4f1c2b"},
+		{name: "int", id: 7, want: "int64:7"},
+		{name: "uint32", id: uint32(8), want: "int64:8"},
+		{name: "float", id: 1.5, wantErr: true},
+		{name: "nil", id: nil, wantErr: true},
+		{name: "overflowing uint64", id: uint64(1 << 63), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient(transport.NewMockTransport(), WithIDGenerator(func() any { return tt.id }))
+			id, err := c.nextRequestID()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for ID %v", tt.id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nextRequestID failed: %v", err)
+			}
+			if id.String() != tt.want {
+				t.Errorf("Expected ID %s, got %s", tt.want, id.String())
+			}
+		})
+	}
+
+	// Invalid IDs fail the request before it is sent
+	mock := transport.NewMockTransport()
+	c := NewClient(mock, WithIDGenerator(func() any { return struct{}{} }))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err == nil {
+		t.Error("Expected Initialize to fail with an invalid ID")
+	}
+	if got := len(mock.Requests()); got != 0 {
+		t.Errorf("Expected no request to be sent, got %d", got)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// ElicitationHandler asks the user for the input requested by an elicitation/create
+// request from the server, typically by presenting a form for the requested schema.
+// The result reports whether the user accepted, declined or cancelled the request;
+// its content is only sent to the server when the user accepted.
+// Returning an error sends a JSON-RPC error response to the server.
+type ElicitationHandler func(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error)
+
+// WithElicitationHandler sets the handler for elicitation requests from the server and
+// advertises the elicitation capability during initialization.
+func WithElicitationHandler(handler ElicitationHandler) ClientOption {
+	return func(c *Client) {
+		c.elicitationHandler = handler
+	}
+}
+
+// handleElicit decodes an elicitation/create request and passes it to the elicitation handler.
+func (c *Client) handleElicit(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*mcp.ElicitResult, error) {
+	var elicitRequest mcp.ElicitRequest
+	elicitRequest.Method = request.Method
+	if err := unmarshalParams(request, &elicitRequest.Params); err != nil {
+		return nil, err
+	}
+
+	result, err := c.elicitationHandler(ctx, elicitRequest)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("elicitation handler returned no result")
+	}
+	if !result.Action.Valid() {
+		return nil, fmt.Errorf("elicitation handler returned invalid action %q", result.Action)
+	}
+	if result.Action != mcp.ElicitationActionAccept && result.Content != nil {
+		// Input is only shared with the server when the user accepted
+		declined := *result
+		declined.Content = nil
+		result = &declined
+	}
+	return result, nil
+}
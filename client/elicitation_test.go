@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestClientElicitationHandler(t *testing.T) {
+	var received mcp.ElicitRequest
+	action := mcp.ElicitationActionAccept
+	ft := &fakeTransport{
+		respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			return resultResponse(request, mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}), nil
+		},
+	}
+	c := NewClient(ft, WithElicitationHandler(func(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		received = request
+		return &mcp.ElicitResult{
+			Action:  action,
+			Content: map[string]any{"name": "octocat"},
+		}, nil
+	}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params, _ := json.Marshal(ft.requests[0].Params)
+	var initParams struct {
+		Capabilities mcp.ClientCapabilities `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &initParams); err != nil {
+		t.Fatalf("Failed to unmarshal initialize params: %v", err)
+	}
+	if initParams.Capabilities.Elicitation == nil {
+		t.Errorf("Expected elicitation capability, got %s", params)
+	}
+
+	elicit := map[string]any{
+		"message": "Which GitHub user?",
+		"requestedSchema": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"name": map[string]any{"type": "string"}},
+			"required":   []any{"name"},
+		},
+	}
+	response := ft.serverRequest(t, "elicitation/create", elicit)
+	if response.Error != nil {
+		t.Fatalf("Expected result, got error: %s", response.Error.Message)
+	}
+	if received.Params.Message != "Which GitHub user?" {
+		t.Errorf("Expected message, got %q", received.Params.Message)
+	}
+	if _, ok := received.Params.RequestedSchema.Properties["name"]; !ok || len(received.Params.RequestedSchema.Required) != 1 {
+		t.Errorf("Expected requested schema, got %+v", received.Params.RequestedSchema)
+	}
+	var result mcp.ElicitResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result.Action != mcp.ElicitationActionAccept || result.Content["name"] != "octocat" {
+		t.Errorf("Unexpected result: %s", response.Result)
+	}
+
+	// Content is not sent when the user declined or cancelled
+	for _, action = range []mcp.ElicitationAction{mcp.ElicitationActionDecline, mcp.ElicitationActionCancel} {
+		response = ft.serverRequest(t, "elicitation/create", elicit)
+		if response.Error != nil {
+			t.Fatalf("Expected result, got error: %s", response.Error.Message)
+		}
+		result = mcp.ElicitResult{}
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if result.Action != action || result.Content != nil {
+			t.Errorf("Expected %s without content, got %s", action, response.Result)
+		}
+	}
+
+	action = "maybe"
+	response = ft.serverRequest(t, "elicitation/create", elicit)
+	if response.Error == nil || response.Error.Code != mcp.INTERNAL_ERROR {
+		t.Errorf("Expected error for an invalid action, got %+v", response)
+	}
+}
+
+func TestClientElicitationWithoutHandler(t *testing.T) {
+	ft := &fakeTransport{}
+	c := NewClient(ft)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	response := ft.serverRequest(t, "elicitation/create", map[string]any{"message": "Name?"})
+	if response.Error == nil || response.Error.Code != mcp.METHOD_NOT_FOUND {
+		t.Errorf("Expected method not found error, got %+v", response)
+	}
+}
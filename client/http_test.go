@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -219,3 +220,340 @@ func (sm *SafeMap) Len() int {
 	defer sm.mu.RUnlock()
 	return len(sm.data)
 }
+
+func TestHTTPClient_ProgressHandler(t *testing.T) {
+	hooks := &server.Hooks{}
+	hooks.AddAfterCallTool(func(ctx context.Context, id any, message *mcp.CallToolRequest, result *mcp.CallToolResult) {
+		clientSession := server.ClientSessionFromContext(ctx)
+		// wait until all the notifications are handled
+		for len(clientSession.NotificationChannel()) > 0 {
+		}
+		time.Sleep(time.Millisecond * 50)
+	})
+
+	mcpServer := server.NewMCPServer(
+		"test-server",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithHooks(hooks),
+	)
+	mcpServer.AddTool(
+		mcp.NewTool("long-running"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+				return mcp.NewToolResultError("missing progress token"), nil
+			}
+			srv := server.ServerFromContext(ctx)
+			for i := 1; i <= 3; i++ {
+				err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+					"progressToken": request.Params.Meta.ProgressToken,
+					"progress":      i,
+					"total":         3,
+					"message":       fmt.Sprintf("step %d", i),
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to send progress: %w", err)
+				}
+			}
+			// progress for an unrelated request must not reach the handler
+			_ = srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": "unknown",
+				"progress":      99,
+			})
+			return mcp.NewToolResultText("done"), nil
+		},
+	)
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer testServer.Close()
+
+	client, err := NewStreamableHttpClient(testServer.URL)
+	if err != nil {
+		t.Fatalf("create client failed %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	var mu sync.Mutex
+	var updates []string
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "long-running"
+	result, err := client.CallToolWithOptions(ctx, request, WithProgressHandler(func(progress, total float64, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates = append(updates, fmt.Sprintf("%v/%v %s", progress, total, message))
+	}))
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Tool returned error: %v", result.Content)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"1/3 step 1", "2/3 step 2", "3/3 step 3"}
+	if fmt.Sprint(updates) != fmt.Sprint(expected) {
+		t.Errorf("Expected progress updates %v, got %v", expected, updates)
+	}
+
+	if request.Params.Meta != nil {
+		t.Errorf("Expected caller's request to be left untouched")
+	}
+
+	client.progressMu.RLock()
+	defer client.progressMu.RUnlock()
+	if len(client.progressHandlers) != 0 {
+		t.Errorf("Expected progress handler to be unregistered, %d left", len(client.progressHandlers))
+	}
+}
+
+func TestHTTPClient_CallToolStream(t *testing.T) {
+	stopped := make(chan error, 1)
+	mcpServer := server.NewMCPServer(
+		"test-server",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+	)
+	mcpServer.AddStreamingTool(
+		mcp.NewTool("tail"),
+		func(ctx context.Context, request mcp.CallToolRequest, emit server.ToolOutputEmitter) (*mcp.CallToolResult, error) {
+			for i := 1; i <= 3; i++ {
+				if err := emit(mcp.NewTextContent(fmt.Sprintf("line %d", i))); err != nil {
+					return nil, err
+				}
+			}
+			return mcp.NewToolResultText("done"), nil
+		},
+	)
+	mcpServer.AddStreamingTool(
+		mcp.NewTool("follow"),
+		func(ctx context.Context, request mcp.CallToolRequest, emit server.ToolOutputEmitter) (*mcp.CallToolResult, error) {
+			for {
+				if err := emit(mcp.NewTextContent("tick")); err != nil {
+					stopped <- err
+					return nil, err
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		},
+	)
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer testServer.Close()
+
+	client, err := NewStreamableHttpClient(testServer.URL)
+	if err != nil {
+		t.Fatalf("create client failed %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	t.Run("Streams content before the result", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "tail"
+		chunks, err := client.CallToolStream(ctx, request)
+		if err != nil {
+			t.Fatalf("CallToolStream failed: %v", err)
+		}
+
+		var got []string
+		for chunk := range chunks {
+			if chunk.Err != nil {
+				t.Fatalf("Stream failed: %v", chunk.Err)
+			}
+			for _, content := range chunk.Content {
+				got = append(got, content.(mcp.TextContent).Text)
+			}
+			if chunk.Result != nil {
+				got = append(got, "result: "+chunk.Result.Content[0].(mcp.TextContent).Text)
+			}
+		}
+
+		expected := []string{"line 1", "line 2", "line 3", "result: done"}
+		if fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("Cancellation stops the tool", func(t *testing.T) {
+		callCtx, cancel := context.WithCancel(ctx)
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "follow"
+		chunks, err := client.CallToolStream(callCtx, request)
+		if err != nil {
+			t.Fatalf("CallToolStream failed: %v", err)
+		}
+		<-chunks
+		cancel()
+
+		select {
+		case err := <-stopped:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected emit to fail with context.Canceled, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the tool to stop after cancellation")
+		}
+	})
+}
+
+func TestHTTPClient_Elicitation(t *testing.T) {
+	mcpServer := server.NewMCPServer(
+		"test-server",
+		"1.0.0",
+		server.WithToolCapabilities(true),
+	)
+	mcpServer.AddTool(
+		mcp.NewTool("create_repo"),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := mcpServer.RequestElicitation(ctx, "Name of the repository?", mcp.ToolInputSchema{
+				Properties: map[string]any{"name": map[string]any{"type": "string"}},
+				Required:   []string{"name"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if result.Action != mcp.ElicitationActionAccept {
+				return mcp.NewToolResultText(string(result.Action)), nil
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("created %v", result.Content["name"])), nil
+		},
+	)
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer testServer.Close()
+
+	trans, err := transport.NewStreamableHTTP(testServer.URL)
+	if err != nil {
+		t.Fatalf("create transport failed %v", err)
+	}
+	var mu sync.Mutex
+	var action mcp.ElicitationAction
+	client := NewClient(trans, WithElicitationHandler(func(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+		if request.Params.Message != "Name of the repository?" {
+			return nil, fmt.Errorf("unexpected message %q", request.Params.Message)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return &mcp.ElicitResult{Action: action, Content: map[string]any{"name": "mcp-go"}}, nil
+	}))
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	for _, tc := range []struct {
+		action   mcp.ElicitationAction
+		expected string
+	}{
+		{mcp.ElicitationActionAccept, "created mcp-go"},
+		{mcp.ElicitationActionDecline, "decline"},
+		{mcp.ElicitationActionCancel, "cancel"},
+	} {
+		mu.Lock()
+		action = tc.action
+		mu.Unlock()
+
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "create_repo"
+		result, err := client.CallToolWithOptions(ctx, request, WithToolCallTimeout(5*time.Second))
+		if err != nil {
+			t.Fatalf("CallTool failed for %s: %v", tc.action, err)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; text != tc.expected {
+			t.Errorf("Expected %q for %s, got %q", tc.expected, tc.action, text)
+		}
+	}
+}
+
+func TestHTTPClient_Roots(t *testing.T) {
+	var mu sync.Mutex
+	var rootsErrors []error
+	hooks := &server.Hooks{}
+	hooks.AddOnError(func(ctx context.Context, id any, method mcp.MCPMethod, message any, err error) {
+		if method == mcp.MethodRootsList {
+			mu.Lock()
+			defer mu.Unlock()
+			rootsErrors = append(rootsErrors, err)
+		}
+	})
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithHooks(hooks))
+
+	testServer := server.NewTestStreamableHTTPServer(mcpServer)
+	defer testServer.Close()
+
+	// The server sends roots/list on the GET stream opened by the client
+	trans, err := transport.NewStreamableHTTP(testServer.URL, transport.WithContinuousListening())
+	if err != nil {
+		t.Fatalf("create transport failed %v", err)
+	}
+	client := NewClient(trans, WithRoots([]mcp.Root{{URI: "file:///project", Name: "project"}}))
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	expectRoots := func(t *testing.T, want ...string) {
+		t.Helper()
+		var uris []string
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			uris = uris[:0]
+			for _, root := range mcpServer.CurrentRoots(trans.GetSessionId()) {
+				uris = append(uris, root.URI)
+			}
+			if fmt.Sprint(uris) == fmt.Sprint(want) {
+				return
+			}
+		}
+		t.Errorf("Expected roots %v, got %v", want, uris)
+	}
+
+	expectRoots(t, "file:///project")
+	if err := client.AddRoot(ctx, mcp.Root{URI: "file:///data"}); err != nil {
+		t.Fatalf("AddRoot failed: %v", err)
+	}
+	expectRoots(t, "file:///project", "file:///data")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(rootsErrors) > 0 {
+		t.Errorf("Unexpected roots/list errors: %v", rootsErrors)
+	}
+}
@@ -2,8 +2,13 @@ package client
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/mathiasXie/mcp-go/client/transport"
 	"github.com/mathiasXie/mcp-go/mcp"
 	"github.com/mathiasXie/mcp-go/server"
 )
@@ -84,7 +89,7 @@ func TestInProcessMCPClient(t *testing.T) {
 						Role: mcp.RoleUser,
 						Content: mcp.AudioContent{
 							Type:     "audio",
-							Data:     "base64-encoded-audio-data",
+							Data:     "UklGRiQAAABXQVZF",
 							MIMEType: "audio/wav",
 						},
 					},
@@ -419,3 +424,231 @@ func TestInProcessMCPClient(t *testing.T) {
 		}
 	})
 }
+
+func TestInProcessMCPClient_ServerMessages(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("progress"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		for i := 1; i <= 3; i++ {
+			if err := server.ServerFromContext(ctx).SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": "token",
+				"progress":      i,
+			}); err != nil {
+				return nil, err
+			}
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("ask"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := server.ServerFromContext(ctx).RequestElicitation(ctx, "Name?", mcp.ToolInputSchema{})
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(fmt.Sprint(result.Content["name"])), nil
+	})
+
+	const latency = 20 * time.Millisecond
+	client := NewClient(
+		transport.NewInProcessTransport(mcpServer, transport.WithInProcessLatency(latency)),
+		WithElicitationHandler(func(ctx context.Context, request mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return &mcp.ElicitResult{Action: mcp.ElicitationActionAccept, Content: map[string]any{"name": "octocat"}}, nil
+		}),
+	)
+	defer client.Close()
+
+	var mu sync.Mutex
+	var progress []float64
+	listChanged := make(chan struct{}, 1)
+	client.OnNotification(func(notification mcp.JSONRPCNotification) {
+		switch notification.Method {
+		case "notifications/progress":
+			mu.Lock()
+			progress = append(progress, notification.Params.AdditionalFields["progress"].(float64))
+			mu.Unlock()
+		case mcp.MethodNotificationToolsListChanged:
+			listChanged <- struct{}{}
+		}
+	})
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+
+	start := time.Now()
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 2*latency {
+		t.Errorf("Expected the round trip to take at least %v, took %v", 2*latency, elapsed)
+	}
+
+	t.Run("Notifications are delivered in order before the response", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "progress"
+		for run := 0; run < 10; run++ {
+			mu.Lock()
+			progress = nil
+			mu.Unlock()
+			if _, err := client.CallTool(ctx, request); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			mu.Lock()
+			got := append([]float64(nil), progress...)
+			mu.Unlock()
+			if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+				t.Fatalf("Run %d: expected progress 1, 2, 3 before the response, got %v", run, got)
+			}
+		}
+	})
+
+	t.Run("List changed notifications are delivered", func(t *testing.T) {
+		mcpServer.AddTool(mcp.NewTool("new-tool"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("new"), nil
+		})
+		select {
+		case <-listChanged:
+		case <-time.After(time.Second):
+			t.Fatal("Expected a tools list_changed notification")
+		}
+	})
+
+	t.Run("Server requests are answered", func(t *testing.T) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "ask"
+		result, err := client.CallTool(ctx, request)
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if text := result.Content[0].(mcp.TextContent).Text; text != "octocat" {
+			t.Errorf("Expected the elicited name, got %q", text)
+		}
+	})
+}
+
+func TestInProcessMCPClient_NotificationsBeforeResponse(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddStreamingTool(mcp.NewTool("tail"), func(ctx context.Context, request mcp.CallToolRequest, emit server.ToolOutputEmitter) (*mcp.CallToolResult, error) {
+		for i := 1; i <= 5; i++ {
+			if err := server.ServerFromContext(ctx).SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+				"progressToken": "token",
+				"progress":      i,
+			}); err != nil {
+				return nil, err
+			}
+			if err := emit(mcp.NewTextContent(fmt.Sprintf("line %d", i))); err != nil {
+				return nil, err
+			}
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	var progress atomic.Int64
+	client.OnNotification(func(notification mcp.JSONRPCNotification) {
+		if notification.Method == "notifications/progress" {
+			progress.Add(1)
+		}
+	})
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "tail"
+	for run := 0; run < 100; run++ {
+		progress.Store(0)
+		chunks, err := client.CallToolStream(ctx, request)
+		if err != nil {
+			t.Fatalf("CallToolStream failed: %v", err)
+		}
+		var got []string
+		for chunk := range chunks {
+			if chunk.Err != nil {
+				t.Fatalf("Run %d: unexpected error: %v", run, chunk.Err)
+			}
+			for _, content := range chunk.Content {
+				got = append(got, content.(mcp.TextContent).Text)
+			}
+		}
+		if len(got) != 5 || got[0] != "line 1" || got[4] != "line 5" {
+			t.Fatalf("Run %d: expected lines 1 to 5 before the result, got %v", run, got)
+		}
+		if n := progress.Load(); n != 5 {
+			t.Fatalf("Run %d: expected 5 progress notifications before the result, got %d", run, n)
+		}
+	}
+}
+
+func TestInProcessMCPClient_Roots(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("roots"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		session := server.ClientSessionFromContext(ctx)
+		roots := server.ServerFromContext(ctx).CurrentRoots(session.SessionID())
+		uris := make([]string, len(roots))
+		for i, root := range roots {
+			uris[i] = root.URI
+		}
+		return mcp.NewToolResultText(fmt.Sprint(uris)), nil
+	})
+
+	client := NewClient(
+		transport.NewInProcessTransport(mcpServer),
+		WithRoots([]mcp.Root{{URI: "file:///project", Name: "project"}}),
+	)
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	// waitForRoots calls the tool until it reports the expected roots
+	waitForRoots := func(t *testing.T, want string) {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "roots"
+		deadline := time.Now().Add(time.Second)
+		for {
+			result, err := client.CallTool(ctx, request)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			got := result.Content[0].(mcp.TextContent).Text
+			if got == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected roots %s, got %s", want, got)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	waitForRoots(t, "[file:///project]")
+	if err := client.AddRoot(ctx, mcp.Root{URI: "file:///other"}); err != nil {
+		t.Fatalf("AddRoot failed: %v", err)
+	}
+	waitForRoots(t, "[file:///project file:///other]")
+}
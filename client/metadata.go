@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/mathiasXie/mcp-go/internal/metadata"
+)
+
+// metadataKey is the context key for the metadata added to requests
+type metadataKey struct{}
+
+// ContextWithMetadata returns a context that adds the given fields to the _meta of
+// every request sent with it, such as a trace ID or an authenticated subject.
+// Server handlers read them with server.MetadataFromContext. Metadata of an
+// enclosing ContextWithMetadata is kept, unless overridden by a field of the same
+// name. Fields set by the request itself, such as a progress token, take
+// precedence.
+//
+// Over networked transports the metadata is sent as JSON. With the in-process
+// transport, handlers receive the original values.
+func ContextWithMetadata(ctx context.Context, values map[string]any) context.Context {
+	merged := maps.Clone(metadataFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]any, len(values))
+	}
+	maps.Copy(merged, values)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+func metadataFromContext(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(metadataKey{}).(map[string]any)
+	return values
+}
+
+// addMetadata adds the metadata of the context to the _meta of the request
+// params, and to the context for in-process servers.
+func addMetadata(ctx context.Context, params any) (context.Context, any, error) {
+	values := metadataFromContext(ctx)
+	if len(values) == 0 {
+		return ctx, params, nil
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ctx, nil, fmt.Errorf("cannot add metadata to params that are not an object: %w", err)
+	}
+	if fields == nil {
+		fields = make(map[string]any)
+	}
+	meta, _ := fields["_meta"].(map[string]any)
+	if meta == nil {
+		meta = make(map[string]any, len(values))
+	}
+	added := make(map[string]any, len(values))
+	for key, value := range values {
+		if _, ok := meta[key]; !ok {
+			meta[key] = value
+			added[key] = value
+		}
+	}
+	fields["_meta"] = meta
+
+	return metadata.ContextWithValues(ctx, added), fields, nil
+}
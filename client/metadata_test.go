@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+	"github.com/mathiasXie/mcp-go/server"
+)
+
+type traceInfo struct {
+	TraceID string
+	Sampled bool
+}
+
+func TestClientMetadata(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("traced"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		received <- server.MetadataFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	newClient := func(t *testing.T, name string) *Client {
+		t.Helper()
+		var client *Client
+		var err error
+		if name == "inprocess" {
+			client, err = NewInProcessClient(mcpServer)
+		} else {
+			httpServer := server.NewTestStreamableHTTPServer(mcpServer)
+			t.Cleanup(httpServer.Close)
+			client, err = NewStreamableHttpClient(httpServer.URL)
+		}
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		t.Cleanup(func() { client.Close() })
+		if err := client.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start client: %v", err)
+		}
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+		if _, err := client.Initialize(context.Background(), initRequest); err != nil {
+			t.Fatalf("Failed to initialize: %v", err)
+		}
+		return client
+	}
+
+	call := func(t *testing.T, client *Client, ctx context.Context) map[string]any {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "traced"
+		request.Params.Meta = &mcp.Meta{ProgressToken: "progress-1"}
+		if _, err := client.CallTool(ctx, request); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		return <-received
+	}
+
+	ctx := ContextWithMetadata(context.Background(), map[string]any{
+		"traceId":       "abc",
+		"trace":         traceInfo{TraceID: "abc", Sampled: true},
+		"progressToken": "ignored",
+	})
+	ctx = ContextWithMetadata(ctx, map[string]any{"subject": "user-1", "attempt": 2})
+
+	t.Run("InProcess", func(t *testing.T) {
+		metadata := call(t, newClient(t, "inprocess"), ctx)
+		if metadata["traceId"] != "abc" || metadata["subject"] != "user-1" {
+			t.Errorf("Unexpected metadata: %#v", metadata)
+		}
+		// values are passed through unchanged
+		if metadata["attempt"] != 2 {
+			t.Errorf("Expected the int to be passed unchanged, got %#v", metadata["attempt"])
+		}
+		if trace, ok := metadata["trace"].(traceInfo); !ok || !trace.Sampled {
+			t.Errorf("Expected the struct to be passed unchanged, got %#v", metadata["trace"])
+		}
+		// fields of the request take precedence
+		if metadata["progressToken"] != "progress-1" {
+			t.Errorf("Expected the progress token of the request, got %#v", metadata["progressToken"])
+		}
+	})
+
+	t.Run("StreamableHTTP", func(t *testing.T) {
+		metadata := call(t, newClient(t, "http"), ctx)
+		if metadata["traceId"] != "abc" || metadata["subject"] != "user-1" || metadata["progressToken"] != "progress-1" {
+			t.Errorf("Unexpected metadata: %#v", metadata)
+		}
+		// values are decoded from JSON
+		if metadata["attempt"] != float64(2) {
+			t.Errorf("Expected the number to be decoded from JSON, got %#v", metadata["attempt"])
+		}
+		if trace, ok := metadata["trace"].(map[string]any); !ok || trace["Sampled"] != true {
+			t.Errorf("Expected the struct to be decoded from JSON, got %#v", metadata["trace"])
+		}
+	})
+
+	t.Run("WithoutMetadata", func(t *testing.T) {
+		client := newClient(t, "inprocess")
+		request := mcp.CallToolRequest{}
+		request.Params.Name = "traced"
+		if _, err := client.CallTool(context.Background(), request); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		if metadata := <-received; metadata != nil {
+			t.Errorf("Expected no metadata, got %#v", metadata)
+		}
+	})
+}
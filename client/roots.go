@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// WithRoots sets the filesystem roots the client exposes to the server.
+// The client advertises the roots capability and answers roots/list requests.
+// Root URIs must use the file:// scheme; invalid roots cause Start to fail.
+func WithRoots(roots []mcp.Root) ClientOption {
+	return func(c *Client) {
+		c.rootsEnabled = true
+		c.roots = slices.Clone(roots)
+	}
+}
+
+// ErrRootsNotAdvertised is returned by AddRoot after the client initialized
+// without advertising the roots capability, which cannot be added afterwards.
+var ErrRootsNotAdvertised = errors.New("roots capability not advertised during initialization")
+
+// AddRoot adds a root, replacing any existing root with the same URI,
+// and notifies the server that the list of roots changed. Before
+// initialization, adding a root enables the roots capability; after an
+// initialization without it, AddRoot returns ErrRootsNotAdvertised.
+func (c *Client) AddRoot(ctx context.Context, root mcp.Root) error {
+	if err := validateRoot(root); err != nil {
+		return err
+	}
+
+	c.rootsMu.Lock()
+	if c.rootsNegotiated && !c.rootsAdvertised {
+		c.rootsMu.Unlock()
+		return ErrRootsNotAdvertised
+	}
+	c.rootsEnabled = true
+	index := slices.IndexFunc(c.roots, func(r mcp.Root) bool { return r.URI == root.URI })
+	if index >= 0 {
+		c.roots[index] = root
+	} else {
+		c.roots = append(c.roots, root)
+	}
+	c.rootsMu.Unlock()
+
+	return c.notifyRootsListChanged(ctx)
+}
+
+// RemoveRoot removes the root with the given URI and notifies the server that
+// the list of roots changed. Removing an unknown root is a no-op.
+func (c *Client) RemoveRoot(ctx context.Context, uri string) error {
+	c.rootsMu.Lock()
+	index := slices.IndexFunc(c.roots, func(r mcp.Root) bool { return r.URI == uri })
+	if index >= 0 {
+		c.roots = slices.Delete(c.roots, index, index+1)
+	}
+	c.rootsMu.Unlock()
+
+	if index < 0 {
+		return nil
+	}
+	return c.notifyRootsListChanged(ctx)
+}
+
+// listRoots returns a copy of the current roots, and whether the client
+// exposes roots at all.
+func (c *Client) listRoots() ([]mcp.Root, bool) {
+	c.rootsMu.RLock()
+	defer c.rootsMu.RUnlock()
+	if !c.rootsEnabled {
+		return nil, false
+	}
+	roots := slices.Clone(c.roots)
+	if roots == nil {
+		roots = []mcp.Root{}
+	}
+	return roots, true
+}
+
+// notifyRootsListChanged tells the server that the roots changed, if the
+// client advertised list changed notifications during initialization.
+// Nothing is sent before initialization, since the server will list the roots anyway.
+func (c *Client) notifyRootsListChanged(ctx context.Context) error {
+	c.rootsMu.RLock()
+	listChanged := c.rootsListChanged
+	c.rootsMu.RUnlock()
+	if !listChanged {
+		return nil
+	}
+
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: mcp.MethodNotificationRootsListChanged,
+		},
+	}
+	if err := c.transport.SendNotification(ctx, notification); err != nil {
+		return fmt.Errorf("failed to send roots list changed notification: %w", err)
+	}
+	return nil
+}
+
+func validateRoots(roots []mcp.Root) error {
+	for _, root := range roots {
+		if err := validateRoot(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRoot checks that the root URI uses the file:// scheme, as required by the specification.
+func validateRoot(root mcp.Root) error {
+	if !strings.HasPrefix(root.URI, "file://") {
+		return fmt.Errorf("invalid root URI %q: must start with file://", root.URI)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func listRootsFromResponse(t *testing.T, response *transport.JSONRPCResponse) []mcp.Root {
+	t.Helper()
+	if response.Error != nil {
+		t.Fatalf("Expected result, got error: %s", response.Error.Message)
+	}
+	var result mcp.ListRootsResult
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal roots: %v", err)
+	}
+	return result.Roots
+}
+
+func TestClientRoots(t *testing.T) {
+	ft := &fakeTransport{
+		respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			return resultResponse(request, mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}), nil
+		},
+	}
+	c := NewClient(ft, WithRoots([]mcp.Root{{URI: "file:///workspace", Name: "workspace"}}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	params, _ := json.Marshal(ft.requests[0].Params)
+	var initParams struct {
+		Capabilities mcp.ClientCapabilities `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &initParams); err != nil {
+		t.Fatalf("Failed to unmarshal initialize params: %v", err)
+	}
+	if initParams.Capabilities.Roots == nil || !initParams.Capabilities.Roots.ListChanged {
+		t.Errorf("Expected roots capability with listChanged, got %s", params)
+	}
+
+	roots := listRootsFromResponse(t, ft.serverRequest(t, "roots/list", nil))
+	if len(roots) != 1 || roots[0].URI != "file:///workspace" || roots[0].Name != "workspace" {
+		t.Errorf("Unexpected roots: %+v", roots)
+	}
+
+	if err := c.AddRoot(context.Background(), mcp.Root{URI: "file:///data"}); err != nil {
+		t.Fatalf("AddRoot failed: %v", err)
+	}
+	roots = listRootsFromResponse(t, ft.serverRequest(t, "roots/list", nil))
+	if len(roots) != 2 || roots[1].URI != "file:///data" {
+		t.Errorf("Expected added root, got %+v", roots)
+	}
+
+	if err := c.RemoveRoot(context.Background(), "file:///workspace"); err != nil {
+		t.Fatalf("RemoveRoot failed: %v", err)
+	}
+	roots = listRootsFromResponse(t, ft.serverRequest(t, "roots/list", nil))
+	if len(roots) != 1 || roots[0].URI != "file:///data" {
+		t.Errorf("Expected remaining root, got %+v", roots)
+	}
+
+	// Removing an unknown root does not notify the server
+	if err := c.RemoveRoot(context.Background(), "file:///unknown"); err != nil {
+		t.Fatalf("RemoveRoot failed: %v", err)
+	}
+	if got := len(ft.notificationsWithMethod(mcp.MethodNotificationRootsListChanged)); got != 2 {
+		t.Errorf("Expected 2 roots list changed notifications, got %d", got)
+	}
+}
+
+func TestClientRootsNotAdvertised(t *testing.T) {
+	ft := &fakeTransport{
+		respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			return resultResponse(request, mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}), nil
+		},
+	}
+	c := NewClient(ft)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := c.AddRoot(context.Background(), mcp.Root{URI: "file:///data"}); !errors.Is(err, ErrRootsNotAdvertised) {
+		t.Errorf("Expected ErrRootsNotAdvertised, got %v", err)
+	}
+	if got := len(ft.notificationsWithMethod(mcp.MethodNotificationRootsListChanged)); got != 0 {
+		t.Errorf("Expected no roots list changed notifications, got %d", got)
+	}
+	if response := ft.serverRequest(t, "roots/list", nil); response.Error == nil {
+		t.Error("Expected roots/list to fail when roots are not advertised")
+	}
+}
+
+func TestClientRootsValidation(t *testing.T) {
+	c := NewClient(&fakeTransport{}, WithRoots([]mcp.Root{{URI: "https://example.com"}}))
+	if err := c.Start(context.Background()); err == nil {
+		t.Error("Expected Start to fail for a non file:// root")
+	}
+
+	c = NewClient(&fakeTransport{})
+	if err := c.AddRoot(context.Background(), mcp.Root{URI: "/tmp/no-scheme"}); err == nil {
+		t.Error("Expected AddRoot to fail for a root without the file:// scheme")
+	}
+	if roots, _ := c.listRoots(); len(roots) != 0 {
+		t.Errorf("Expected invalid root not to be added, got %d roots", len(roots))
+	}
+}
+
+func TestClientIncomingRequests(t *testing.T) {
+	ft := &fakeTransport{}
+	c := NewClient(ft)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if response := ft.serverRequest(t, "ping", nil); response.Error != nil {
+		t.Errorf("Expected ping to succeed, got error: %s", response.Error.Message)
+	}
+
+	for _, method := range []string{"roots/list", "unknown/method"} {
+		response := ft.serverRequest(t, method, nil)
+		if response.Error == nil || response.Error.Code != mcp.METHOD_NOT_FOUND {
+			t.Errorf("%s: expected method not found error, got %+v", method, response)
+		}
+	}
+}
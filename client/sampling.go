@@ -0,0 +1,58 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// SamplingHandler generates an LLM completion for a sampling/createMessage request from the server.
+// Returning an error sends a JSON-RPC error response to the server.
+type SamplingHandler func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+// WithSamplingHandler sets the handler for sampling requests from the server and
+// advertises the sampling capability during initialization.
+func WithSamplingHandler(handler SamplingHandler) ClientOption {
+	return func(c *Client) {
+		c.samplingHandler = handler
+	}
+}
+
+// handleCreateMessage decodes a sampling/createMessage request and passes it to the sampling handler.
+func (c *Client) handleCreateMessage(
+	ctx context.Context,
+	request transport.JSONRPCRequest,
+) (*mcp.CreateMessageResult, error) {
+	var createRequest mcp.CreateMessageRequest
+	createRequest.Method = request.Method
+	if err := unmarshalParams(request, &createRequest.CreateMessageParams); err != nil {
+		return nil, err
+	}
+
+	// Decode message content into the concrete content types
+	for i, message := range createRequest.Messages {
+		contentMap, ok := message.Content.(map[string]any)
+		if !ok {
+			continue
+		}
+		content, err := mcp.ParseContent(contentMap)
+		if err != nil {
+			return nil, &requestError{
+				code:    mcp.INVALID_PARAMS,
+				message: fmt.Sprintf("invalid content in message %d: %v", i, err),
+			}
+		}
+		createRequest.Messages[i].Content = content
+	}
+
+	result, err := c.samplingHandler(ctx, createRequest)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, fmt.Errorf("sampling handler returned no result")
+	}
+	return result, nil
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestClientSamplingHandler(t *testing.T) {
+	var received mcp.CreateMessageRequest
+	ft := &fakeTransport{
+		respond: func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+			return resultResponse(request, mcp.InitializeResult{ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION}), nil
+		},
+	}
+	c := NewClient(ft, WithSamplingHandler(func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		received = request
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{
+				Role:    mcp.RoleAssistant,
+				Content: mcp.NewTextContent("Paris"),
+			},
+			Model:      "test-model",
+			StopReason: "endTurn",
+		}, nil
+	}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := c.Initialize(context.Background(), mcp.InitializeRequest{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	params, _ := json.Marshal(ft.requests[0].Params)
+	var initParams struct {
+		Capabilities mcp.ClientCapabilities `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &initParams); err != nil {
+		t.Fatalf("Failed to unmarshal initialize params: %v", err)
+	}
+	if initParams.Capabilities.Sampling == nil {
+		t.Errorf("Expected sampling capability, got %s", params)
+	}
+
+	response := ft.serverRequest(t, "sampling/createMessage", map[string]any{
+		"messages": []any{
+			map[string]any{
+				"role":    "user",
+				"content": map[string]any{"type": "text", "text": "What is the capital of France?"},
+			},
+		},
+		"modelPreferences": map[string]any{
+			"hints":         []any{map[string]any{"name": "claude"}},
+			"speedPriority": 0.5,
+		},
+		"systemPrompt": "You are helpful.",
+		"maxTokens":    100,
+	})
+	if response.Error != nil {
+		t.Fatalf("Expected result, got error: %s", response.Error.Message)
+	}
+
+	if len(received.Messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(received.Messages))
+	}
+	text, ok := received.Messages[0].Content.(mcp.TextContent)
+	if !ok || text.Text != "What is the capital of France?" {
+		t.Errorf("Expected text content, got %#v", received.Messages[0].Content)
+	}
+	if received.ModelPreferences == nil || received.ModelPreferences.SpeedPriority != 0.5 {
+		t.Errorf("Expected model preferences, got %+v", received.ModelPreferences)
+	}
+	if received.SystemPrompt != "You are helpful." || received.MaxTokens != 100 {
+		t.Errorf("Unexpected request params: %+v", received.CreateMessageParams)
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result["model"] != "test-model" || result["role"] != "assistant" {
+		t.Errorf("Unexpected result: %s", response.Result)
+	}
+}
+
+func TestClientSamplingErrors(t *testing.T) {
+	ft := &fakeTransport{}
+	c := NewClient(ft, WithSamplingHandler(func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		return nil, errors.New("user rejected sampling request")
+	}))
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	response := ft.serverRequest(t, "sampling/createMessage", map[string]any{
+		"messages":  []any{},
+		"maxTokens": 10,
+	})
+	if response.Error == nil || response.Error.Code != mcp.INTERNAL_ERROR {
+		t.Fatalf("Expected internal error, got %+v", response)
+	}
+	if response.Error.Message != "user rejected sampling request" {
+		t.Errorf("Expected handler error message, got %q", response.Error.Message)
+	}
+
+	response = ft.serverRequest(t, "sampling/createMessage", map[string]any{
+		"messages": "not a list",
+	})
+	if response.Error == nil || response.Error.Code != mcp.INVALID_PARAMS {
+		t.Errorf("Expected invalid params error, got %+v", response)
+	}
+
+	// Without a handler the method is not available
+	ft = &fakeTransport{}
+	c = NewClient(ft)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	response = ft.serverRequest(t, "sampling/createMessage", map[string]any{"messages": []any{}})
+	if response.Error == nil || response.Error.Code != mcp.METHOD_NOT_FOUND {
+		t.Errorf("Expected method not found error, got %+v", response)
+	}
+}
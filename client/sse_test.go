@@ -2,7 +2,10 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -331,3 +334,89 @@ func TestSSEMCPClient(t *testing.T) {
 		}
 	})
 }
+
+func TestSSEMCPClient_IDGenerator(t *testing.T) {
+	var mu sync.Mutex
+	var ids []any
+	hooks := &server.Hooks{}
+	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, id)
+	})
+	mcpServer := server.NewMCPServer("test-server", "1.0.0",
+		server.WithToolCapabilities(true),
+		server.WithHooks(hooks),
+	)
+	mcpServer.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+	testServer := server.NewTestServer(mcpServer)
+	defer testServer.Close()
+
+	tests := []struct {
+		name      string
+		generator func(n int) any
+		wantIDs   []any
+	}{
+		{
+			name:      "string IDs",
+			generator: func(n int) any { return fmt.Sprintf("req-%d", n) },
+			wantIDs:   []any{"req-1", "req-2", "req-3"},
+		},
+		{
+			name:      "unsigned integer IDs",
+			generator: func(n int) any { return uint16(100 + n) },
+			wantIDs:   []any{float64(101), float64(102), float64(103)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			ids = nil
+			mu.Unlock()
+
+			trans, err := transport.NewSSE(testServer.URL + "/sse")
+			if err != nil {
+				t.Fatalf("Failed to create transport: %v", err)
+			}
+			var counter atomic.Int64
+			client := NewClient(trans, WithIDGenerator(func() any {
+				return tt.generator(int(counter.Add(1)))
+			}))
+			defer client.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := client.Start(ctx); err != nil {
+				t.Fatalf("Failed to start client: %v", err)
+			}
+			initRequest := mcp.InitializeRequest{}
+			initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+			if _, err := client.Initialize(ctx, initRequest); err != nil {
+				t.Fatalf("Failed to initialize: %v", err)
+			}
+
+			// The responses are matched to requests with the generated IDs
+			if _, err := client.ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "echo"
+			if _, err := client.CallTool(ctx, request); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("Expected server to receive IDs %v, got %v", tt.wantIDs, ids)
+			}
+			for i, id := range ids {
+				if fmt.Sprintf("%T", id) != fmt.Sprintf("%T", tt.wantIDs[i]) {
+					t.Errorf("Expected ID %v to be a %T, got %T", id, tt.wantIDs[i], id)
+				}
+			}
+		})
+	}
+}
@@ -20,7 +20,21 @@ func NewStdioMCPClient(
 	args ...string,
 ) (*Client, error) {
 
-	stdioTransport := transport.NewStdio(command, env, args...)
+	return NewStdioMCPClientWithOptions(command, env, args)
+}
+
+// NewStdioMCPClientWithOptions creates a new stdio-based MCP client like NewStdioMCPClient,
+// with options to configure the subprocess, such as its environment, working directory and stderr.
+//
+// NOTICE: NewStdioMCPClientWithOptions will start the connection automatically. Don't call the Start method manually.
+func NewStdioMCPClientWithOptions(
+	command string,
+	env []string,
+	args []string,
+	opts ...transport.StdioOption,
+) (*Client, error) {
+
+	stdioTransport := transport.NewStdioWithOptions(command, env, args, opts...)
 	err := stdioTransport.Start(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("failed to start stdio transport: %w", err)
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// ToolError is a failure reported by a tool in its result, with IsError set,
+// as opposed to a protocol error returned by CallTool.
+//
+// Tools that return mcp.NewToolResultErrorWithCode provide a machine-readable
+// code and details; for plain text errors, Code and Details are empty and the
+// message is the text content of the result.
+type ToolError struct {
+	code    string
+	message string
+	details any
+}
+
+// AsToolError returns the ToolError reported by a tool result, or nil if the
+// result is not an error.
+func AsToolError(result *mcp.CallToolResult) *ToolError {
+	if result == nil || !result.IsError {
+		return nil
+	}
+
+	toolErr := &ToolError{message: toolResultText(result)}
+	if content, ok := toolErrorContent(result.StructuredContent); ok {
+		toolErr.code = content.Code
+		toolErr.details = content.Details
+		if content.Message != "" {
+			toolErr.message = content.Message
+		}
+	}
+	return toolErr
+}
+
+// toolErrorContent extracts the "error" field of the structured content of an
+// error result, which is a map after decoding from JSON.
+func toolErrorContent(structured any) (mcp.ToolErrorContent, bool) {
+	var content struct {
+		Error *mcp.ToolErrorContent `json:"error"`
+	}
+	if structured == nil {
+		return mcp.ToolErrorContent{}, false
+	}
+	data, err := json.Marshal(structured)
+	if err != nil {
+		return mcp.ToolErrorContent{}, false
+	}
+	if err := json.Unmarshal(data, &content); err != nil || content.Error == nil || content.Error.Code == "" {
+		return mcp.ToolErrorContent{}, false
+	}
+	return *content.Error, true
+}
+
+// Code returns the machine-readable error code, or "" for plain text errors.
+func (e *ToolError) Code() string {
+	return e.code
+}
+
+// Message returns the human-readable error message.
+func (e *ToolError) Message() string {
+	return e.message
+}
+
+// Details returns the additional information about the failure, as decoded
+// from JSON, or nil if the tool did not provide any.
+func (e *ToolError) Details() any {
+	return e.details
+}
+
+func (e *ToolError) Error() string {
+	if e.code == "" {
+		return e.message
+	}
+	return e.code + ": " + e.message
+}
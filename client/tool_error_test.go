@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+	"github.com/mathiasXie/mcp-go/server"
+)
+
+func TestToolError(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+	mcpServer.AddTool(mcp.NewTool("coded"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultErrorWithCode("RATE_LIMITED", "too many requests", map[string]any{"retryAfter": 30}), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("plain"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("city not found"), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("ok"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultStructured(map[string]any{"error": "not an error"}, "done"), nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	call := func(name string) *mcp.CallToolResult {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		result, err := client.CallTool(ctx, request)
+		if err != nil {
+			t.Fatalf("CallTool %s failed: %v", name, err)
+		}
+		return result
+	}
+
+	t.Run("Coded", func(t *testing.T) {
+		toolErr := AsToolError(call("coded"))
+		if toolErr == nil {
+			t.Fatal("Expected a tool error")
+		}
+		if toolErr.Code() != "RATE_LIMITED" || toolErr.Message() != "too many requests" {
+			t.Errorf("Unexpected tool error: code %q, message %q", toolErr.Code(), toolErr.Message())
+		}
+		details, ok := toolErr.Details().(map[string]any)
+		if !ok || details["retryAfter"] != float64(30) {
+			t.Errorf("Unexpected details: %#v", toolErr.Details())
+		}
+		if toolErr.Error() != "RATE_LIMITED: too many requests" {
+			t.Errorf("Unexpected error string: %q", toolErr.Error())
+		}
+	})
+
+	t.Run("PlainText", func(t *testing.T) {
+		toolErr := AsToolError(call("plain"))
+		if toolErr == nil {
+			t.Fatal("Expected a tool error")
+		}
+		if toolErr.Code() != "" || toolErr.Details() != nil {
+			t.Errorf("Expected no code or details, got %q %#v", toolErr.Code(), toolErr.Details())
+		}
+		if toolErr.Error() != "city not found" {
+			t.Errorf("Unexpected error string: %q", toolErr.Error())
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		if toolErr := AsToolError(call("ok")); toolErr != nil {
+			t.Errorf("Expected no tool error, got %v", toolErr)
+		}
+	})
+
+	t.Run("CallToolTyped", func(t *testing.T) {
+		_, err := CallToolTyped[map[string]any](ctx, client, "coded", nil)
+		var toolErr *ToolError
+		if !errors.As(err, &toolErr) {
+			t.Fatalf("Expected a *ToolError, got %v", err)
+		}
+		if toolErr.Code() != "RATE_LIMITED" {
+			t.Errorf("Expected RATE_LIMITED, got %q", toolErr.Code())
+		}
+	})
+}
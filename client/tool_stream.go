@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// CallToolStream calls a tool and streams the content it emits as it runs.
+//
+// The request is sent with a progress token; content the server sends in
+// notifications/tools/output notifications for that token is delivered as
+// chunks on the returned channel, in order. The last chunk carries the final
+// result or the error of the call, after which the channel is closed. Tools
+// registered on the server with AddStreamingTool emit such notifications.
+//
+// Cancelling ctx tears the stream down: the server is notified that the
+// request was cancelled and the channel is closed, dropping undelivered chunks.
+func (c *Client) CallToolStream(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	opts ...RequestOption,
+) (<-chan mcp.ToolResultChunk, error) {
+	if !c.initialized {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	if err := c.requireCapability("tools", c.SupportsTools()); err != nil {
+		return nil, err
+	}
+	options := newRequestOptions(opts)
+
+	var token string
+	if options.progressHandler != nil {
+		token = fmt.Sprint(c.registerProgressHandler(options.progressHandler))
+	} else {
+		token = c.newProgressToken()
+	}
+	request = withProgressToken(request, token)
+
+	stream := &toolStream{ready: make(chan struct{}, 1)}
+	c.progressMu.Lock()
+	c.outputHandlers[token] = func(content []mcp.Content) {
+		stream.push(mcp.ToolResultChunk{Content: content}, false)
+	}
+	c.progressMu.Unlock()
+
+	go func() {
+		defer c.unregisterProgressHandler(token)
+		result, err := c.callTool(ctx, request, options)
+		stream.push(mcp.ToolResultChunk{Result: result, Err: err}, true)
+	}()
+
+	chunks := make(chan mcp.ToolResultChunk)
+	go stream.forward(ctx, chunks)
+	return chunks, nil
+}
+
+// handleToolOutput dispatches a tool output notification to the stream registered for its token.
+func (c *Client) handleToolOutput(notification mcp.JSONRPCNotification) {
+	fields := notification.Params.AdditionalFields
+	token, ok := fields["progressToken"]
+	if !ok {
+		return
+	}
+
+	c.progressMu.RLock()
+	handler, ok := c.outputHandlers[fmt.Sprint(token)]
+	c.progressMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	content, err := parseToolOutput(fields["content"])
+	if err != nil || len(content) == 0 {
+		return
+	}
+	handler(content)
+}
+
+// parseToolOutput parses the content of a tool output notification.
+func parseToolOutput(raw any) ([]mcp.Content, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+
+	content := make([]mcp.Content, 0, len(items))
+	for _, item := range items {
+		parsed, err := mcp.ParseContent(item)
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, parsed)
+	}
+	return content, nil
+}
+
+// toolStream queues the chunks of a streaming tool call, so that
+// notifications are never blocked by a slow reader of the stream.
+type toolStream struct {
+	mu     sync.Mutex
+	queue  []mcp.ToolResultChunk
+	closed bool
+	ready  chan struct{}
+}
+
+// push queues a chunk; last marks the final chunk of the stream.
+func (s *toolStream) push(chunk mcp.ToolResultChunk, last bool) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.queue = append(s.queue, chunk)
+	s.closed = last
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// forward delivers the queued chunks to out until the final chunk was
+// delivered or ctx is done, then closes out.
+func (s *toolStream) forward(ctx context.Context, out chan<- mcp.ToolResultChunk) {
+	defer close(out)
+	for {
+		s.mu.Lock()
+		chunks, closed := s.queue, s.closed
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, chunk := range chunks {
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if closed {
+			return
+		}
+
+		select {
+		case <-s.ready:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+	"github.com/mathiasXie/mcp-go/server"
+)
+
+func TestClientCallToolStream(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	mock.Handle("tools/call", func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+		params := request.Params.(mcp.CallToolParams)
+		token := params.Meta.ProgressToken
+		for _, text := range []string{"line 1", "line 2"} {
+			mock.SimulateNotification(mcp.MethodNotificationToolOutput, map[string]any{
+				"progressToken": token,
+				"content":       []mcp.Content{mcp.NewTextContent(text)},
+			})
+		}
+		// output of another request is not part of the stream
+		mock.SimulateNotification(mcp.MethodNotificationToolOutput, map[string]any{
+			"progressToken": "unknown",
+			"content":       []mcp.Content{mcp.NewTextContent("other")},
+		})
+		return transport.NewJSONRPCResultResponse(request.ID, []byte(`{"content":[{"type":"text","text":"done"}]}`)), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "tail"
+	chunks, err := c.CallToolStream(context.Background(), request)
+	if err != nil {
+		t.Fatalf("CallToolStream failed: %v", err)
+	}
+
+	var got []string
+	var final *mcp.ToolResultChunk
+	for chunk := range chunks {
+		if chunk.Result != nil || chunk.Err != nil {
+			final = &chunk
+			continue
+		}
+		for _, content := range chunk.Content {
+			got = append(got, content.(mcp.TextContent).Text)
+		}
+	}
+
+	if len(got) != 2 || got[0] != "line 1" || got[1] != "line 2" {
+		t.Errorf("Expected streamed lines [line 1 line 2], got %v", got)
+	}
+	if final == nil {
+		t.Fatal("Expected a final chunk")
+	}
+	if final.Err != nil {
+		t.Fatalf("Expected no error, got %v", final.Err)
+	}
+	if text := final.Result.Content[0].(mcp.TextContent).Text; text != "done" {
+		t.Errorf("Expected final result 'done', got %q", text)
+	}
+	if request.Params.Meta != nil {
+		t.Error("Expected caller's request to be left untouched")
+	}
+
+	c.progressMu.RLock()
+	defer c.progressMu.RUnlock()
+	if len(c.outputHandlers) != 0 {
+		t.Errorf("Expected output handler to be unregistered, %d left", len(c.outputHandlers))
+	}
+}
+
+func TestClientCallToolStream_Cancel(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	mock.Handle("tools/call", func(ctx context.Context, request transport.JSONRPCRequest) (*transport.JSONRPCResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "tail"
+	chunks, err := c.CallToolStream(ctx, request)
+	if err != nil {
+		t.Fatalf("CallToolStream failed: %v", err)
+	}
+	cancel()
+
+	timeout := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-chunks:
+		case <-timeout:
+			t.Fatal("Expected the stream to be closed after cancellation")
+		}
+	}
+
+	// The server is asked to stop the call
+	cancelled := func() bool {
+		for _, notification := range mock.Notifications() {
+			if notification.Method == mcp.MethodNotificationCancelled {
+				return true
+			}
+		}
+		return false
+	}
+	deadline := time.Now().Add(time.Second)
+	for !cancelled() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !cancelled() {
+		t.Fatal("Expected a cancellation notification")
+	}
+}
+
+func TestClientCallToolStream_RequiresTools(t *testing.T) {
+	c := NewClient(transport.NewMockTransport())
+	if _, err := c.CallToolStream(context.Background(), mcp.CallToolRequest{}); err == nil {
+		t.Error("Expected an error for an uninitialized client")
+	}
+}
+
+func TestClientCallToolStream_Transports(t *testing.T) {
+	const lines = 20
+	newServer := func() *server.MCPServer {
+		mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+		mcpServer.AddStreamingTool(
+			mcp.NewTool("tail"),
+			func(ctx context.Context, request mcp.CallToolRequest, emit server.ToolOutputEmitter) (*mcp.CallToolResult, error) {
+				for i := 1; i <= lines; i++ {
+					if err := emit(mcp.NewTextContent(fmt.Sprintf("line %d", i))); err != nil {
+						return nil, err
+					}
+				}
+				return mcp.NewToolResultText("done"), nil
+			},
+		)
+		return mcpServer
+	}
+
+	checkStream := func(t *testing.T, c *Client) {
+		t.Helper()
+		ctx := context.Background()
+		initRequest := mcp.InitializeRequest{}
+		initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+		initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+		if _, err := c.Initialize(ctx, initRequest); err != nil {
+			t.Fatalf("Failed to initialize: %v", err)
+		}
+
+		// The output is written before the result, so no chunk is ever lost
+		for run := 0; run < 50; run++ {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "tail"
+			chunks, err := c.CallToolStream(ctx, request)
+			if err != nil {
+				t.Fatalf("CallToolStream failed: %v", err)
+			}
+			var got []string
+			var final *mcp.ToolResultChunk
+			for chunk := range chunks {
+				if chunk.Result != nil || chunk.Err != nil {
+					final = &chunk
+					continue
+				}
+				for _, content := range chunk.Content {
+					got = append(got, content.(mcp.TextContent).Text)
+				}
+			}
+			if len(got) != lines {
+				t.Fatalf("Run %d: expected %d streamed lines, got %d: %v", run, lines, len(got), got)
+			}
+			for i, text := range got {
+				if want := fmt.Sprintf("line %d", i+1); text != want {
+					t.Fatalf("Run %d: expected %q at position %d, got %q", run, want, i, text)
+				}
+			}
+			if final == nil || final.Err != nil {
+				t.Fatalf("Run %d: expected a successful final chunk, got %+v", run, final)
+			}
+		}
+	}
+
+	t.Run("stdio", func(t *testing.T) {
+		serverReader, clientWriter := io.Pipe()
+		clientReader, serverWriter := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			_ = server.NewStdioServer(newServer()).Listen(ctx, serverReader, serverWriter)
+		}()
+
+		c := NewClient(transport.NewIO(clientReader, clientWriter, io.NopCloser(strings.NewReader(""))))
+		defer c.Close()
+		if err := c.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start client: %v", err)
+		}
+		checkStream(t, c)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		testServer := server.NewTestServer(newServer())
+		defer testServer.Close()
+
+		c, err := NewSSEMCPClient(testServer.URL + "/sse")
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		defer c.Close()
+		if err := c.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start client: %v", err)
+		}
+		checkStream(t, c)
+	})
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// toolCache holds the tools of the last full ListTools call, keyed by name.
+// The generation is bumped on invalidation so that a listing that was in
+// flight while the tool list changed does not repopulate the cache.
+type toolCache struct {
+	tools      map[string]mcp.Tool
+	generation uint64
+}
+
+// GetTool returns the tool with the given name from the tool cache.
+// The cache is populated by the first ListTools call that lists all tools and
+// is invalidated when the server sends notifications/tools/list_changed;
+// until then, or while invalidated, GetTool reports false. Use RefreshTools
+// to fetch the tools again.
+func (c *Client) GetTool(name string) (*mcp.Tool, bool) {
+	c.toolsMu.RLock()
+	defer c.toolsMu.RUnlock()
+	tool, ok := c.toolCache.tools[name]
+	if !ok {
+		return nil, false
+	}
+	return &tool, true
+}
+
+// RefreshTools lists the tools from the server and replaces the tool cache.
+func (c *Client) RefreshTools(ctx context.Context) error {
+	_, err := c.ListTools(ctx, mcp.ListToolsRequest{})
+	return err
+}
+
+// ValidateToolArgs validates the arguments of a call to the named tool
+// against its input schema, without calling the tool. The schema is taken from
+// the tool cache, which is refreshed from the server if the tool is not cached.
+//
+// An unknown tool is reported as ErrUnknownTool. Invalid arguments are
+// reported as mcp.SchemaValidationErrors, listing every violation:
+//
+//	var violations mcp.SchemaValidationErrors
+//	if errors.As(err, &violations) {
+//		for _, v := range violations {
+//			fmt.Println(v.Path, v.Message)
+//		}
+//	}
+func (c *Client) ValidateToolArgs(ctx context.Context, name string, args any) error {
+	tool, ok := c.GetTool(name)
+	if !ok {
+		if err := c.RefreshTools(ctx); err != nil {
+			return fmt.Errorf("failed to fetch tools: %w", err)
+		}
+		if tool, ok = c.GetTool(name); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownTool, name)
+		}
+	}
+
+	var schema any = tool.InputSchema
+	if tool.RawInputSchema != nil {
+		schema = tool.RawInputSchema
+	}
+	if args == nil {
+		// Calls without arguments are sent as an empty object
+		args = map[string]any{}
+	}
+	if err := mcp.ValidateSchemaAll(schema, args); err != nil {
+		return fmt.Errorf("invalid arguments for tool %s: %w", name, err)
+	}
+	return nil
+}
+
+// toolCacheGeneration returns the current generation of the tool cache.
+func (c *Client) toolCacheGeneration() uint64 {
+	c.toolsMu.RLock()
+	defer c.toolsMu.RUnlock()
+	return c.toolCache.generation
+}
+
+// storeTools replaces the tool cache, unless it was invalidated after generation was read.
+func (c *Client) storeTools(generation uint64, tools []mcp.Tool) {
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+	if c.toolCache.generation != generation {
+		return
+	}
+	c.toolCache.tools = make(map[string]mcp.Tool, len(tools))
+	for _, tool := range tools {
+		c.toolCache.tools[tool.Name] = tool
+	}
+}
+
+// invalidateTools clears the tool cache.
+func (c *Client) invalidateTools() {
+	c.toolsMu.Lock()
+	defer c.toolsMu.Unlock()
+	c.toolCache.tools = nil
+	c.toolCache.generation++
+}
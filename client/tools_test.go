@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/client/transport"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// newToolsTestClient returns an initialized client connected to a mock
+// transport whose server offers the tools capability.
+func newToolsTestClient(t *testing.T) (*Client, *transport.MockTransport) {
+	t.Helper()
+	mock := transport.NewMockTransport()
+	initResult := mcp.InitializeResult{
+		ProtocolVersion: mcp.LATEST_PROTOCOL_VERSION,
+		ServerInfo:      mcp.Implementation{Name: "mock", Version: "1.0.0"},
+	}
+	initResult.Capabilities.Tools = &struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	}{ListChanged: true}
+	if err := mock.RespondWith("initialize", initResult); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+
+	c := NewClient(mock)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := c.Initialize(context.Background(), request); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return c, mock
+}
+
+func TestClientToolCache(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	ctx := context.Background()
+	respondWithTools := func(tools ...mcp.Tool) {
+		t.Helper()
+		if err := mock.RespondWith("tools/list", mcp.ListToolsResult{Tools: tools}); err != nil {
+			t.Fatalf("RespondWith failed: %v", err)
+		}
+	}
+	respondWithTools(
+		mcp.NewTool("echo", mcp.WithDescription("Echoes the input")),
+		mcp.NewTool("add"),
+	)
+
+	if _, ok := c.GetTool("echo"); ok {
+		t.Error("Expected no cached tool before ListTools")
+	}
+
+	if _, err := c.ListTools(ctx, mcp.ListToolsRequest{}); err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	tool, ok := c.GetTool("echo")
+	if !ok {
+		t.Fatal("Expected echo to be cached after ListTools")
+	}
+	if tool.Description != "Echoes the input" {
+		t.Errorf("Unexpected description: %q", tool.Description)
+	}
+	tool.Description = "modified"
+	if tool, _ := c.GetTool("echo"); tool.Description != "Echoes the input" {
+		t.Error("Expected modifying a returned tool to leave the cache unchanged")
+	}
+	if _, ok := c.GetTool("missing"); ok {
+		t.Error("Expected unknown tool to be reported as missing")
+	}
+
+	// The cache is served without another request
+	if got := len(mock.RequestsWithMethod("tools/list")); got != 1 {
+		t.Errorf("Expected 1 tools/list request, got %d", got)
+	}
+
+	mock.SimulateNotification(mcp.MethodNotificationToolsListChanged, nil)
+	if _, ok := c.GetTool("echo"); ok {
+		t.Error("Expected list_changed to invalidate the cache")
+	}
+
+	respondWithTools(mcp.NewTool("multiply"))
+	if err := c.RefreshTools(ctx); err != nil {
+		t.Fatalf("RefreshTools failed: %v", err)
+	}
+	if _, ok := c.GetTool("multiply"); !ok {
+		t.Error("Expected multiply to be cached after RefreshTools")
+	}
+	if _, ok := c.GetTool("echo"); ok {
+		t.Error("Expected removed tool to be dropped from the cache")
+	}
+}
+
+func TestClientToolCacheAnnotations(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	if err := mock.RespondWith("tools/list", mcp.ListToolsResult{Tools: []mcp.Tool{
+		mcp.NewTool("read", mcp.WithToolAnnotations(mcp.ToolAnnotations{
+			Title:        "Read",
+			ReadOnlyHint: mcp.ToBoolPtr(true),
+		})),
+		mcp.NewTool("plain"),
+	}}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	if err := c.RefreshTools(context.Background()); err != nil {
+		t.Fatalf("RefreshTools failed: %v", err)
+	}
+
+	read, ok := c.GetTool("read")
+	if !ok {
+		t.Fatal("Expected read to be cached")
+	}
+	if read.Annotations.Title != "Read" || !read.Annotations.IsReadOnly() || read.Annotations.DestructiveHint != nil {
+		t.Errorf("Unexpected annotations: %+v", read.Annotations)
+	}
+	plain, ok := c.GetTool("plain")
+	if !ok {
+		t.Fatal("Expected plain to be cached")
+	}
+	if plain.Annotations != (mcp.ToolAnnotations{}) {
+		t.Errorf("Expected unset annotations, got %+v", plain.Annotations)
+	}
+	if !plain.Annotations.IsDestructive() {
+		t.Error("Expected tools without hints to be treated as destructive")
+	}
+}
+
+func TestClientToolCacheIgnoresPartialListings(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	if err := mock.RespondWith("tools/list", mcp.ListToolsResult{Tools: []mcp.Tool{mcp.NewTool("echo")}}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+
+	if _, err := c.ListToolsByPage(context.Background(), mcp.ListToolsRequest{}); err != nil {
+		t.Fatalf("ListToolsByPage failed: %v", err)
+	}
+	request := mcp.ListToolsRequest{}
+	request.Params.Cursor = "page-2"
+	if _, err := c.ListTools(context.Background(), request); err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if _, ok := c.GetTool("echo"); ok {
+		t.Error("Expected listings that don't start at the first page to leave the cache empty")
+	}
+}
+
+func TestClientValidateToolArgs(t *testing.T) {
+	c, mock := newToolsTestClient(t)
+	ctx := context.Background()
+	if err := mock.RespondWith("tools/list", mcp.ListToolsResult{Tools: []mcp.Tool{
+		mcp.NewTool("search",
+			mcp.WithString("query", mcp.Required(), mcp.MinLength(1)),
+			mcp.WithNumber("limit", mcp.Max(100)),
+		),
+	}}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+
+	// The schema is fetched once and then served from the cache
+	if err := c.ValidateToolArgs(ctx, "search", map[string]any{"query": "go"}); err != nil {
+		t.Errorf("Expected valid arguments, got %v", err)
+	}
+	err := c.ValidateToolArgs(ctx, "search", map[string]any{"limit": 500})
+	var violations mcp.SchemaValidationErrors
+	if !errors.As(err, &violations) {
+		t.Fatalf("Expected SchemaValidationErrors, got %v", err)
+	}
+	if len(violations) != 2 || violations[0].Path != "$.query" || violations[1].Path != "$.limit" {
+		t.Errorf("Unexpected violations: %v", violations)
+	}
+	if err := c.ValidateToolArgs(ctx, "search", nil); err == nil {
+		t.Error("Expected missing arguments to be invalid")
+	}
+	if got := len(mock.RequestsWithMethod("tools/list")); got != 1 {
+		t.Errorf("Expected 1 tools/list request, got %d", got)
+	}
+
+	err = c.ValidateToolArgs(ctx, "missing", map[string]any{})
+	if !errors.Is(err, ErrUnknownTool) {
+		t.Errorf("Expected ErrUnknownTool, got %v", err)
+	}
+	if got := len(mock.RequestsWithMethod("tools/list")); got != 2 {
+		t.Errorf("Expected unknown tool to refresh the cache, got %d tools/list requests", got)
+	}
+	if got := len(mock.RequestsWithMethod("tools/call")); got != 0 {
+		t.Errorf("Expected no tools/call request, got %d", got)
+	}
+}
@@ -0,0 +1,160 @@
+package transport
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestStreamableHTTP_ResponseDecompression(t *testing.T) {
+	compressors := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw-deflate": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+	}
+
+	for name, compress := range compressors {
+		for _, contentType := range []string{"application/json", "text/event-stream"} {
+			t.Run(name+" "+contentType, func(t *testing.T) {
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if got := r.Header.Get("Accept-Encoding"); got != "gzip, deflate" {
+						t.Errorf("Expected Accept-Encoding %q, got %q", "gzip, deflate", got)
+					}
+					var request JSONRPCRequest
+					if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+						t.Errorf("Failed to decode request: %v", err)
+						return
+					}
+					response, _ := json.Marshal(NewJSONRPCResultResponse(request.ID, json.RawMessage(`{"echo":"hello"}`)))
+					if contentType == "text/event-stream" {
+						response = []byte(fmt.Sprintf("event: message\ndata: %s\n\n", response))
+					}
+
+					encoding := name
+					if name == "raw-deflate" {
+						encoding = "deflate"
+					}
+					w.Header().Set("Content-Type", contentType)
+					w.Header().Set("Content-Encoding", encoding)
+					cw := compress(w)
+					_, _ = cw.Write(response)
+					_ = cw.Close()
+				}))
+				defer server.Close()
+
+				trans, err := NewStreamableHTTP(server.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer trans.Close()
+
+				response, err := trans.SendRequest(context.Background(), JSONRPCRequest{
+					JSONRPC: "2.0",
+					ID:      mcp.NewRequestId(int64(1)),
+					Method:  "tools/list",
+				})
+				if err != nil {
+					t.Fatalf("SendRequest failed: %v", err)
+				}
+				if string(response.Result) != `{"echo":"hello"}` {
+					t.Errorf("Unexpected result: %s", response.Result)
+				}
+			})
+		}
+	}
+}
+
+func TestStreamableHTTP_RequestCompression(t *testing.T) {
+	type received struct {
+		encoding string
+		method   string
+	}
+	requests := make(chan received, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := io.Reader(r.Body)
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Failed to read gzip body: %v", err)
+				return
+			}
+			body = zr
+		}
+		var request JSONRPCRequest
+		if err := json.NewDecoder(body).Decode(&request); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+			return
+		}
+		requests <- received{encoding: r.Header.Get("Content-Encoding"), method: request.Method}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(NewJSONRPCResultResponse(request.ID, json.RawMessage(`{}`)))
+	}))
+	defer server.Close()
+
+	send := func(t *testing.T, trans *StreamableHTTP, params any) received {
+		t.Helper()
+		_, err := trans.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  "tools/call",
+			Params:  params,
+		})
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		return <-requests
+	}
+	large := map[string]any{"text": strings.Repeat("a", 2*requestCompressionThreshold)}
+
+	t.Run("compresses large bodies", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(server.URL, WithRequestCompression(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		if got := send(t, trans, large); got.encoding != "gzip" || got.method != "tools/call" {
+			t.Errorf("Expected a gzip encoded tools/call, got %+v", got)
+		}
+		if got := send(t, trans, map[string]any{"text": "small"}); got.encoding != "" {
+			t.Errorf("Expected small body to be sent uncompressed, got encoding %q", got.encoding)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		trans, err := NewStreamableHTTP(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer trans.Close()
+
+		if got := send(t, trans, large); got.encoding != "" {
+			t.Errorf("Expected uncompressed body, got encoding %q", got.encoding)
+		}
+	})
+}
+
+func TestDecompressResponseUnsupportedEncoding(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"br"}},
+		Body:   io.NopCloser(bytes.NewReader(nil)),
+	}
+	if err := decompressResponse(resp); err == nil {
+		t.Error("Expected an error for unsupported encoding")
+	}
+}
@@ -5,28 +5,310 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/mathiasXie/mcp-go/mcp"
 	"github.com/mathiasXie/mcp-go/server"
 )
 
+// InProcessOption configures an InProcessTransport.
+type InProcessOption func(*InProcessTransport)
+
+// WithInProcessLatency delays every message by the given duration in each
+// direction: requests and notifications to the server, and responses,
+// notifications and requests from the server. Messages keep their order. It
+// makes tests exercise the asynchronous behavior of networked transports.
+func WithInProcessLatency(latency time.Duration) InProcessOption {
+	return func(c *InProcessTransport) {
+		c.latency = latency
+	}
+}
+
+// InProcessTransport connects a client directly to an MCPServer in the same
+// process. Once started, it registers a session on the server, so that the
+// notifications and requests sent by the server to the client, such as
+// progress or list_changed notifications, are delivered to the client as they
+// would be over a networked transport.
 type InProcessTransport struct {
-	server *server.MCPServer
+	server  *server.MCPServer
+	latency time.Duration
+
+	session  *inProcessSession
+	done     chan struct{}
+	flushes  chan chan struct{}
+	wg       sync.WaitGroup
+	closeMu  sync.Mutex
+	started  bool
+	closed   bool
+	requests requestHandlerHolder
 
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
 }
 
-func NewInProcessTransport(server *server.MCPServer) *InProcessTransport {
-	return &InProcessTransport{
-		server: server,
+var _ BidirectionalInterface = (*InProcessTransport)(nil)
+
+func NewInProcessTransport(server *server.MCPServer, opts ...InProcessOption) *InProcessTransport {
+	c := &InProcessTransport{
+		server:  server,
+		done:    make(chan struct{}),
+		flushes: make(chan chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
+// Start registers the session of the client on the server and starts
+// delivering the messages sent by the server to the client.
 func (c *InProcessTransport) Start(ctx context.Context) error {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+	if c.started {
+		return fmt.Errorf("in-process transport already started")
+	}
+	if c.closed {
+		return fmt.Errorf("in-process transport is closed")
+	}
+
+	session := newInProcessSession()
+	if err := c.server.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("failed to register session: %w", err)
+	}
+	c.session = session
+	c.started = true
+
+	notifications := make(chan delivery)
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		c.forward(session, notifications)
+	}()
+	go func() {
+		defer c.wg.Done()
+		for item := range notifications {
+			if item.flushed != nil {
+				close(item.flushed)
+				continue
+			}
+			c.notify(item.notification)
+		}
+	}()
 	return nil
 }
 
+// delivery is a notification to deliver to the client once the latency has
+// elapsed, or a marker closed once the notifications before it were delivered.
+type delivery struct {
+	notification mcp.JSONRPCNotification
+	deliverAt    time.Time
+	flushed      chan struct{}
+}
+
+// forward reads the messages sent by the server to the session until the
+// transport is closed, passing notifications on in order once the latency has
+// elapsed, and handling requests concurrently.
+func (c *InProcessTransport) forward(session *inProcessSession, notifications chan<- delivery) {
+	defer close(notifications)
+	queue := make(chan delivery, cap(session.notifications))
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer close(queue)
+		enqueue := func(item delivery) bool {
+			select {
+			case queue <- item:
+				return true
+			case <-c.done:
+				return false
+			}
+		}
+		enqueueNotification := func(sent mcp.JSONRPCNotification) bool {
+			// Notifications are passed through JSON, like over the wire
+			var notification mcp.JSONRPCNotification
+			if err := roundTripJSON(sent, &notification); err != nil {
+				return true
+			}
+			return enqueue(delivery{notification: notification, deliverAt: time.Now().Add(c.latency)})
+		}
+		for {
+			select {
+			case sent := <-session.notifications:
+				if !enqueueNotification(sent) {
+					return
+				}
+			case flushed := <-c.flushes:
+				// Queue the notifications sent so far ahead of the marker
+				for drained := false; !drained; {
+					select {
+					case sent := <-session.notifications:
+						if !enqueueNotification(sent) {
+							return
+						}
+					default:
+						drained = true
+					}
+				}
+				if !enqueue(delivery{deliverAt: time.Now().Add(c.latency), flushed: flushed}) {
+					return
+				}
+			case request := <-session.requests:
+				c.wg.Add(1)
+				go func() {
+					defer c.wg.Done()
+					c.handleServerRequest(request)
+				}()
+			case <-c.done:
+				return
+			}
+		}
+	}()
+
+	for item := range queue {
+		if !c.sleepUntil(item.deliverAt) {
+			return
+		}
+		select {
+		case notifications <- item:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// handleServerRequest answers a request sent by the server with the request
+// handler of the client.
+func (c *InProcessTransport) handleServerRequest(request mcp.JSONRPCRequest) {
+	if !c.sleepUntil(time.Now().Add(c.latency)) {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var clientRequest JSONRPCRequest
+	if err := roundTripJSON(request, &clientRequest); err != nil {
+		return
+	}
+	response := c.requests.handle(ctx, clientRequest)
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	if !c.sleepUntil(time.Now().Add(c.latency)) {
+		return
+	}
+	c.server.HandleMessage(c.server.WithContext(ctx, c.session), responseBytes)
+}
+
+// roundTripJSON encodes a message to JSON and decodes it into out.
+func roundTripJSON(message any, out any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *InProcessTransport) notify(notification mcp.JSONRPCNotification) {
+	c.notifyMu.RLock()
+	handler := c.onNotification
+	c.notifyMu.RUnlock()
+	if handler != nil {
+		handler(notification)
+	}
+}
+
+// sleepUntil waits until the given time, and reports false if the transport
+// was closed in the meantime.
+func (c *InProcessTransport) sleepUntil(deadline time.Time) bool {
+	delay := time.Until(deadline)
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// delay waits for the latency, and returns an error if the context is done
+// or the transport is closed in the meantime.
+func (c *InProcessTransport) delay(ctx context.Context) error {
+	return c.delayUntil(ctx, time.Now().Add(c.latency))
+}
+
+// delayUntil waits until the given time, and returns an error if the context
+// is done or the transport is closed in the meantime.
+func (c *InProcessTransport) delayUntil(ctx context.Context, deadline time.Time) error {
+	delay := time.Until(deadline)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("in-process transport is closed")
+	}
+}
+
+// flush waits until the notifications sent by the server to the session so far
+// were delivered to the notification handler.
+func (c *InProcessTransport) flush(ctx context.Context) error {
+	c.closeMu.Lock()
+	started := c.started
+	c.closeMu.Unlock()
+	if !started {
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case c.flushes <- flushed:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("in-process transport is closed")
+	}
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("in-process transport is closed")
+	}
+}
+
+// withSession returns the context to handle a message of the client with.
+func (c *InProcessTransport) withSession(ctx context.Context) context.Context {
+	c.closeMu.Lock()
+	session := c.session
+	c.closeMu.Unlock()
+	if session == nil {
+		return ctx
+	}
+	return c.server.WithContext(ctx, session)
+}
+
 func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
@@ -34,7 +316,11 @@ func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCReq
 	}
 	requestBytes = append(requestBytes, '\n')
 
-	respMessage := c.server.HandleMessage(ctx, requestBytes)
+	if err := c.delay(ctx); err != nil {
+		return nil, err
+	}
+	respMessage := c.server.HandleMessage(c.withSession(ctx), requestBytes)
+	respondAt := time.Now().Add(c.latency)
 	respByte, err := json.Marshal(respMessage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal response message: %w", err)
@@ -44,6 +330,13 @@ func (c *InProcessTransport) SendRequest(ctx context.Context, request JSONRPCReq
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response message: %w", err)
 	}
+	// Notifications sent while handling the request are delivered before its response
+	if err := c.flush(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.delayUntil(ctx, respondAt); err != nil {
+		return nil, err
+	}
 
 	return &rpcResp, nil
 }
@@ -54,7 +347,10 @@ func (c *InProcessTransport) SendNotification(ctx context.Context, notification
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 	notificationBytes = append(notificationBytes, '\n')
-	c.server.HandleMessage(ctx, notificationBytes)
+	if err := c.delay(ctx); err != nil {
+		return err
+	}
+	c.server.HandleMessage(c.withSession(ctx), notificationBytes)
 
 	return nil
 }
@@ -65,6 +361,109 @@ func (c *InProcessTransport) SetNotificationHandler(handler func(notification mc
 	c.onNotification = handler
 }
 
-func (*InProcessTransport) Close() error {
+// SetRequestHandler sets the handler for requests sent by the server, such as
+// sampling or elicitation requests.
+func (c *InProcessTransport) SetRequestHandler(handler RequestHandler) {
+	c.requests.set(handler)
+}
+
+// Close unregisters the session from the server and stops delivering messages.
+func (c *InProcessTransport) Close() error {
+	c.closeMu.Lock()
+	if c.closed {
+		c.closeMu.Unlock()
+		return nil
+	}
+	c.closed = true
+	session := c.session
+	close(c.done)
+	c.closeMu.Unlock()
+
+	c.wg.Wait()
+	if session != nil {
+		c.server.UnregisterSession(context.Background(), session.SessionID())
+	}
 	return nil
 }
+
+// inProcessSession is the session of an in-process client on the server.
+type inProcessSession struct {
+	sessionID     string
+	notifications chan mcp.JSONRPCNotification
+	requests      chan mcp.JSONRPCRequest
+	initialized   atomic.Bool
+	loggingLevel  atomic.Value
+	clientInfo    atomic.Value // stores session-specific client info
+	subscriptions sync.Map     // stores subscribed resource URIs
+}
+
+func newInProcessSession() *inProcessSession {
+	return &inProcessSession{
+		sessionID:     "inprocess-" + uuid.New().String(),
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+		requests:      make(chan mcp.JSONRPCRequest, 100),
+	}
+}
+
+func (s *inProcessSession) SessionID() string {
+	return s.sessionID
+}
+
+func (s *inProcessSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *inProcessSession) RequestChannel() chan<- mcp.JSONRPCRequest {
+	return s.requests
+}
+
+func (s *inProcessSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+func (s *inProcessSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+func (s *inProcessSession) GetClientInfo() mcp.Implementation {
+	if clientInfo, ok := s.clientInfo.Load().(mcp.Implementation); ok {
+		return clientInfo
+	}
+	return mcp.Implementation{}
+}
+
+func (s *inProcessSession) SetClientInfo(clientInfo mcp.Implementation) {
+	s.clientInfo.Store(clientInfo)
+}
+
+func (s *inProcessSession) SetLogLevel(level mcp.LoggingLevel) {
+	s.loggingLevel.Store(level)
+}
+
+func (s *inProcessSession) GetLogLevel() mcp.LoggingLevel {
+	if level, ok := s.loggingLevel.Load().(mcp.LoggingLevel); ok {
+		return level
+	}
+	return mcp.LoggingLevelInfo
+}
+
+func (s *inProcessSession) SubscribeResource(uri string) {
+	s.subscriptions.Store(uri, struct{}{})
+}
+
+func (s *inProcessSession) UnsubscribeResource(uri string) {
+	s.subscriptions.Delete(uri)
+}
+
+func (s *inProcessSession) IsSubscribedToResource(uri string) bool {
+	_, ok := s.subscriptions.Load(uri)
+	return ok
+}
+
+var (
+	_ server.ClientSession                    = (*inProcessSession)(nil)
+	_ server.SessionWithLogging               = (*inProcessSession)(nil)
+	_ server.SessionWithClientInfo            = (*inProcessSession)(nil)
+	_ server.SessionWithResourceSubscriptions = (*inProcessSession)(nil)
+	_ server.SessionWithRequests              = (*inProcessSession)(nil)
+)
@@ -3,6 +3,7 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"sync"
 
 	"github.com/mathiasXie/mcp-go/mcp"
 )
@@ -31,6 +32,20 @@ type Interface interface {
 	Close() error
 }
 
+// RequestHandler handles a request sent by the server to the client and returns
+// the response to send back. Returning an error sends an internal error response.
+type RequestHandler func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error)
+
+// BidirectionalInterface is a transport that can also receive requests from the server,
+// such as roots/list or sampling/createMessage.
+type BidirectionalInterface interface {
+	Interface
+
+	// SetRequestHandler sets the handler for requests sent by the server.
+	// Requests received before the handler is set are answered with a method not found error.
+	SetRequestHandler(handler RequestHandler)
+}
+
 type JSONRPCRequest struct {
 	JSONRPC string        `json:"jsonrpc"`
 	ID      mcp.RequestId `json:"id"`
@@ -41,10 +56,88 @@ type JSONRPCRequest struct {
 type JSONRPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      mcp.RequestId   `json:"id"`
-	Result  json.RawMessage `json:"result"`
+	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *struct {
 		Code    int             `json:"code"`
 		Message string          `json:"message"`
-		Data    json.RawMessage `json:"data"`
-	} `json:"error"`
+		Data    json.RawMessage `json:"data,omitempty"`
+	} `json:"error,omitempty"`
+}
+
+// NewJSONRPCResultResponse creates a successful response for the request with the given ID.
+func NewJSONRPCResultResponse(id mcp.RequestId, result json.RawMessage) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Result:  result,
+	}
+}
+
+// NewJSONRPCErrorResponse creates an error response for the request with the given ID.
+func NewJSONRPCErrorResponse(id mcp.RequestId, code int, message string) *JSONRPCResponse {
+	response := &JSONRPCResponse{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+	}
+	response.Error = &struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data,omitempty"`
+	}{
+		Code:    code,
+		Message: message,
+	}
+	return response
+}
+
+// incomingRequest is the subset of a JSON-RPC message needed to tell a request
+// from a response or notification.
+type incomingRequest struct {
+	ID     mcp.RequestId `json:"id"`
+	Method string        `json:"method"`
+}
+
+// parseIncomingRequest reports whether data is a JSON-RPC request, and decodes it if so.
+func parseIncomingRequest(data []byte) (JSONRPCRequest, bool) {
+	var probe incomingRequest
+	if err := json.Unmarshal(data, &probe); err != nil || probe.ID.IsNil() || probe.Method == "" {
+		return JSONRPCRequest{}, false
+	}
+	var request JSONRPCRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return JSONRPCRequest{}, false
+	}
+	return request, true
+}
+
+// requestHandlerHolder stores the handler for server requests and turns its
+// result into the response to send back.
+type requestHandlerHolder struct {
+	mu      sync.RWMutex
+	handler RequestHandler
+}
+
+func (h *requestHandlerHolder) set(handler RequestHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handler = handler
+}
+
+// handle runs the handler for the request and always returns a response.
+func (h *requestHandlerHolder) handle(ctx context.Context, request JSONRPCRequest) *JSONRPCResponse {
+	h.mu.RLock()
+	handler := h.handler
+	h.mu.RUnlock()
+
+	if handler == nil {
+		return NewJSONRPCErrorResponse(request.ID, mcp.METHOD_NOT_FOUND, "no handler for server requests")
+	}
+	response, err := handler(ctx, request)
+	if err != nil {
+		return NewJSONRPCErrorResponse(request.ID, mcp.INTERNAL_ERROR, err.Error())
+	}
+	if response == nil {
+		return NewJSONRPCErrorResponse(request.ID, mcp.INTERNAL_ERROR, "no response from request handler")
+	}
+	return response
 }
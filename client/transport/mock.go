@@ -0,0 +1,206 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// MockHandler answers a request sent to a MockTransport.
+// Returning an error makes SendRequest fail with that error, as a transport failure would.
+type MockHandler func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error)
+
+// MockTransport is an in-memory transport for testing client code without a server.
+// Responses are registered per method; requests and notifications sent by the client
+// are recorded so tests can assert on them. Requests for methods without a registered
+// response are answered with a method not found error.
+type MockTransport struct {
+	mu            sync.Mutex
+	handlers      map[string]MockHandler
+	requests      []JSONRPCRequest
+	notifications []mcp.JSONRPCNotification
+	closed        bool
+
+	onNotification func(mcp.JSONRPCNotification)
+	notifyMu       sync.RWMutex
+	requestHandler requestHandlerHolder
+}
+
+var _ BidirectionalInterface = (*MockTransport)(nil)
+
+// NewMockTransport creates a MockTransport without any registered responses.
+func NewMockTransport() *MockTransport {
+	return &MockTransport{
+		handlers: make(map[string]MockHandler),
+	}
+}
+
+// Handle registers handler to answer requests for method, replacing any previous response.
+func (m *MockTransport) Handle(method string, handler MockHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[method] = handler
+}
+
+// RespondWith answers requests for method with result, marshaled as JSON.
+func (m *MockTransport) RespondWith(method string, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	m.Handle(method, func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		return NewJSONRPCResultResponse(request.ID, data), nil
+	})
+	return nil
+}
+
+// RespondWithError answers requests for method with a JSON-RPC error response.
+func (m *MockTransport) RespondWithError(method string, code int, message string) {
+	m.Handle(method, func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		return NewJSONRPCErrorResponse(request.ID, code, message), nil
+	})
+}
+
+// RespondWithDelay answers requests for method with result after delay.
+// If the request context is done first, SendRequest returns the context error,
+// which makes it possible to test timeouts and cancellation.
+func (m *MockTransport) RespondWithDelay(method string, delay time.Duration, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	m.Handle(method, func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return NewJSONRPCResultResponse(request.ID, data), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	return nil
+}
+
+// FailWith makes requests for method fail with err, simulating a transport failure.
+func (m *MockTransport) FailWith(method string, err error) {
+	m.Handle(method, func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		return nil, err
+	})
+}
+
+// Start implements Interface.
+func (m *MockTransport) Start(ctx context.Context) error {
+	return nil
+}
+
+// SendRequest records the request and answers it with the response registered for its method.
+func (m *MockTransport) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("transport closed")
+	}
+	m.requests = append(m.requests, request)
+	handler, ok := m.handlers[request.Method]
+	m.mu.Unlock()
+
+	if !ok {
+		return NewJSONRPCErrorResponse(
+			request.ID,
+			mcp.METHOD_NOT_FOUND,
+			fmt.Sprintf("method not found: %s", request.Method),
+		), nil
+	}
+	return handler(ctx, request)
+}
+
+// SendNotification records the notification sent by the client.
+func (m *MockTransport) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return fmt.Errorf("transport closed")
+	}
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+// SetNotificationHandler implements Interface.
+func (m *MockTransport) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	m.notifyMu.Lock()
+	defer m.notifyMu.Unlock()
+	m.onNotification = handler
+}
+
+// SetRequestHandler implements BidirectionalInterface.
+func (m *MockTransport) SetRequestHandler(handler RequestHandler) {
+	m.requestHandler.set(handler)
+}
+
+// Close marks the transport as closed; later requests and notifications fail.
+func (m *MockTransport) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	return nil
+}
+
+// Requests returns the requests sent by the client, in order.
+func (m *MockTransport) Requests() []JSONRPCRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]JSONRPCRequest(nil), m.requests...)
+}
+
+// RequestsWithMethod returns the requests sent by the client for method, in order.
+func (m *MockTransport) RequestsWithMethod(method string) []JSONRPCRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var requests []JSONRPCRequest
+	for _, request := range m.requests {
+		if request.Method == method {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// Notifications returns the notifications sent by the client, in order.
+func (m *MockTransport) Notifications() []mcp.JSONRPCNotification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mcp.JSONRPCNotification(nil), m.notifications...)
+}
+
+// SimulateNotification delivers a notification to the client as if the server had sent it.
+func (m *MockTransport) SimulateNotification(method string, params map[string]any) {
+	notification := mcp.JSONRPCNotification{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{
+			Method: method,
+			Params: mcp.NotificationParams{
+				AdditionalFields: params,
+			},
+		},
+	}
+
+	m.notifyMu.RLock()
+	defer m.notifyMu.RUnlock()
+	if m.onNotification != nil {
+		m.onNotification(notification)
+	}
+}
+
+// SimulateRequest sends a request to the client as if the server had sent it,
+// and returns the client's response.
+func (m *MockTransport) SimulateRequest(ctx context.Context, request JSONRPCRequest) *JSONRPCResponse {
+	if request.JSONRPC == "" {
+		request.JSONRPC = mcp.JSONRPC_VERSION
+	}
+	return m.requestHandler.handle(ctx, request)
+}
@@ -0,0 +1,145 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestMockTransport(t *testing.T) {
+	newRequest := func(id int64, method string) JSONRPCRequest {
+		return JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(id),
+			Method:  method,
+		}
+	}
+
+	t.Run("CannedResponses", func(t *testing.T) {
+		mock := NewMockTransport()
+		if err := mock.RespondWith("tools/list", mcp.ListToolsResult{Tools: []mcp.Tool{mcp.NewTool("echo")}}); err != nil {
+			t.Fatalf("RespondWith failed: %v", err)
+		}
+		mock.RespondWithError("prompts/get", mcp.INVALID_PARAMS, "unknown prompt")
+
+		response, err := mock.SendRequest(context.Background(), newRequest(1, "tools/list"))
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		var result mcp.ListToolsResult
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if len(result.Tools) != 1 || result.Tools[0].Name != "echo" {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+		if response.ID.String() != mcp.NewRequestId(int64(1)).String() {
+			t.Errorf("Expected response ID to match request, got %v", response.ID)
+		}
+
+		response, err = mock.SendRequest(context.Background(), newRequest(2, "prompts/get"))
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if response.Error == nil || response.Error.Code != mcp.INVALID_PARAMS {
+			t.Errorf("Expected invalid params error, got %+v", response.Error)
+		}
+
+		response, err = mock.SendRequest(context.Background(), newRequest(3, "resources/list"))
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if response.Error == nil || response.Error.Code != mcp.METHOD_NOT_FOUND {
+			t.Errorf("Expected method not found error, got %+v", response.Error)
+		}
+
+		if got := len(mock.Requests()); got != 3 {
+			t.Errorf("Expected 3 recorded requests, got %d", got)
+		}
+		if got := len(mock.RequestsWithMethod("tools/list")); got != 1 {
+			t.Errorf("Expected 1 tools/list request, got %d", got)
+		}
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		mock := NewMockTransport()
+		errBroken := errors.New("connection reset")
+		mock.FailWith("ping", errBroken)
+
+		if _, err := mock.SendRequest(context.Background(), newRequest(1, "ping")); !errors.Is(err, errBroken) {
+			t.Errorf("Expected transport error, got %v", err)
+		}
+
+		if err := mock.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if _, err := mock.SendRequest(context.Background(), newRequest(2, "ping")); err == nil {
+			t.Error("Expected error after close")
+		}
+	})
+
+	t.Run("DelayedResponses", func(t *testing.T) {
+		mock := NewMockTransport()
+		if err := mock.RespondWithDelay("tools/call", 50*time.Millisecond, mcp.NewToolResultText("done")); err != nil {
+			t.Fatalf("RespondWithDelay failed: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := mock.SendRequest(ctx, newRequest(1, "tools/call")); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+
+		start := time.Now()
+		response, err := mock.SendRequest(context.Background(), newRequest(2, "tools/call"))
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("Expected response after delay, got it after %v", elapsed)
+		}
+		if response.Error != nil {
+			t.Errorf("Unexpected error response: %+v", response.Error)
+		}
+	})
+
+	t.Run("ServerMessages", func(t *testing.T) {
+		mock := NewMockTransport()
+
+		var received []mcp.JSONRPCNotification
+		mock.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+			received = append(received, notification)
+		})
+		mock.SimulateNotification("notifications/tools/list_changed", nil)
+		if len(received) != 1 || received[0].Method != "notifications/tools/list_changed" {
+			t.Errorf("Unexpected notifications: %+v", received)
+		}
+
+		response := mock.SimulateRequest(context.Background(), newRequest(1, "roots/list"))
+		if response.Error == nil || response.Error.Code != mcp.METHOD_NOT_FOUND {
+			t.Errorf("Expected method not found without request handler, got %+v", response)
+		}
+
+		mock.SetRequestHandler(func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+			return NewJSONRPCResultResponse(request.ID, json.RawMessage(`{"roots":[]}`)), nil
+		})
+		response = mock.SimulateRequest(context.Background(), newRequest(2, "roots/list"))
+		if response.Error != nil || string(response.Result) != `{"roots":[]}` {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+
+		if err := mock.SendNotification(context.Background(), mcp.JSONRPCNotification{
+			JSONRPC:      mcp.JSONRPC_VERSION,
+			Notification: mcp.Notification{Method: "notifications/initialized"},
+		}); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+		if notifications := mock.Notifications(); len(notifications) != 1 {
+			t.Errorf("Expected 1 recorded notification, got %d", len(notifications))
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// BackoffFunc returns the delay before a retry; attempt is 1 for the first retry.
+type BackoffFunc func(attempt int) time.Duration
+
+// ExponentialBackoff returns a BackoffFunc that doubles the delay with each
+// retry, starting at base and capped at maxDelay.
+func ExponentialBackoff(base, maxDelay time.Duration) BackoffFunc {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		return min(delay, maxDelay)
+	}
+}
+
+// DefaultBackoff is the backoff used by WithRetry when none is given.
+var DefaultBackoff = ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+
+// sendRequestHTTP posts a request, retrying it on transient failures when
+// retries are enabled and the request is idempotent.
+func (c *StreamableHTTP) sendRequestHTTP(
+	ctx context.Context,
+	request JSONRPCRequest,
+	body []byte,
+) (*http.Response, error) {
+	attempts := 1
+	if c.retryAttempts > 1 && c.isIdempotent(request) {
+		attempts = c.retryAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.sendHTTP(ctx, http.MethodPost, body, "application/json, text/event-stream")
+		if attempt >= attempts || !isTransientFailure(resp, err) {
+			return resp, err
+		}
+
+		delay := c.retryBackoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			resp.Body.Close()
+		}
+		c.logger.Infof("retrying %s request after transient failure (attempt %d of %d)", request.Method, attempt+1, attempts)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isIdempotent reports whether a request can safely be sent more than once.
+func (c *StreamableHTTP) isIdempotent(request JSONRPCRequest) bool {
+	switch mcp.MCPMethod(request.Method) {
+	case mcp.MethodInitialize, mcp.MethodPing, mcp.MethodResourcesRead:
+		return true
+	case mcp.MethodToolsCall:
+		if len(c.retryTools) == 0 {
+			return false
+		}
+		data, err := json.Marshal(request.Params)
+		if err != nil {
+			return false
+		}
+		var params struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &params); err != nil {
+			return false
+		}
+		_, ok := c.retryTools[params.Name]
+		return ok
+	}
+	return strings.HasSuffix(request.Method, "/list")
+}
+
+// isTransientFailure reports whether a request failed in a way that may
+// succeed when retried: a 502, 503 or 504 status or a reset connection.
+func isTransientFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
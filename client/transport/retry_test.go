@@ -0,0 +1,179 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// startFlakyServer starts a server that answers the first failures requests of
+// each method with the given status, and succeeds afterwards.
+func startFlakyServer(t *testing.T, status, failures int, retryAfter string) (*httptest.Server, map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		attempts[request.Method]++
+		failed := attempts[request.Method] <= failures
+		mu.Unlock()
+		if failed {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  map[string]any{},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, attempts
+}
+
+func TestStreamableHTTP_Retry(t *testing.T) {
+	noDelay := func(int) time.Duration { return 0 }
+	request := func(method string, params any) JSONRPCRequest {
+		return JSONRPCRequest{JSONRPC: "2.0", ID: mcp.NewRequestId(int64(1)), Method: method, Params: params}
+	}
+
+	t.Run("Retries idempotent requests", func(t *testing.T) {
+		server, attempts := startFlakyServer(t, http.StatusServiceUnavailable, 2, "")
+		trans, err := NewStreamableHTTP(server.URL, WithRetry(3, noDelay))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+
+		for _, method := range []string{"ping", "tools/list", "resources/templates/list", "resources/read"} {
+			response, err := trans.SendRequest(context.Background(), request(method, nil))
+			if err != nil {
+				t.Fatalf("%s failed: %v", method, err)
+			}
+			if response.Error != nil {
+				t.Errorf("%s: unexpected error response %+v", method, response.Error)
+			}
+			if attempts[method] != 3 {
+				t.Errorf("%s: expected 3 attempts, got %d", method, attempts[method])
+			}
+		}
+	})
+
+	t.Run("Gives up after maxAttempts", func(t *testing.T) {
+		server, attempts := startFlakyServer(t, http.StatusBadGateway, 5, "")
+		trans, err := NewStreamableHTTP(server.URL, WithRetry(3, noDelay))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+		if _, err := trans.SendRequest(context.Background(), request("tools/list", nil)); err == nil {
+			t.Error("Expected the request to fail")
+		}
+		if attempts["tools/list"] != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts["tools/list"])
+		}
+	})
+
+	t.Run("Does not retry tool calls unless opted in", func(t *testing.T) {
+		server, attempts := startFlakyServer(t, http.StatusGatewayTimeout, 1, "")
+		trans, err := NewStreamableHTTP(server.URL, WithRetry(3, noDelay), WithRetryableTools("search"))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+
+		if _, err := trans.SendRequest(context.Background(), request("tools/call", mcp.CallToolParams{Name: "delete"})); err == nil {
+			t.Error("Expected the call of a tool that was not opted in to fail")
+		}
+		if attempts["tools/call"] != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts["tools/call"])
+		}
+
+		if _, err := trans.SendRequest(context.Background(), request("tools/call", mcp.CallToolParams{Name: "search"})); err != nil {
+			t.Errorf("Expected the call of an opted in tool to be retried, got %v", err)
+		}
+	})
+
+	t.Run("Does not retry other statuses", func(t *testing.T) {
+		server, attempts := startFlakyServer(t, http.StatusInternalServerError, 1, "")
+		trans, err := NewStreamableHTTP(server.URL, WithRetry(3, noDelay))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+		if _, err := trans.SendRequest(context.Background(), request("tools/list", nil)); err == nil {
+			t.Error("Expected the request to fail")
+		}
+		if attempts["tools/list"] != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts["tools/list"])
+		}
+	})
+
+	t.Run("Retry-After overrides the backoff", func(t *testing.T) {
+		server, _ := startFlakyServer(t, http.StatusServiceUnavailable, 1, "0")
+		trans, err := NewStreamableHTTP(server.URL, WithRetry(2, func(int) time.Duration { return time.Hour }))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := trans.SendRequest(ctx, request("ping", nil)); err != nil {
+			t.Errorf("Expected the retry to happen after Retry-After, got %v", err)
+		}
+	})
+
+	t.Run("Waiting is cancelled with the context", func(t *testing.T) {
+		server, _ := startFlakyServer(t, http.StatusServiceUnavailable, 1, "")
+		trans, err := NewStreamableHTTP(server.URL, WithRetry(2, func(int) time.Duration { return time.Hour }))
+		if err != nil {
+			t.Fatalf("NewStreamableHTTP failed: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := trans.SendRequest(ctx, request("ping", nil)); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, want := range expected {
+		if got := backoff(i + 1); got != want {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("3"); !ok || d != 3*time.Second {
+		t.Errorf("Expected 3s, got %v %v", d, ok)
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(future); !ok || d <= 0 || d > time.Minute {
+		t.Errorf("Expected a delay of at most a minute, got %v %v", d, ok)
+	}
+	for _, value := range []string{"", "soon", "-1"} {
+		if _, ok := parseRetryAfter(value); ok {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"strings"
@@ -30,6 +31,7 @@ type SSE struct {
 	mu             sync.RWMutex
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+	requestHandler requestHandlerHolder
 	endpointChan   chan struct{}
 	headers        map[string]string
 	headerFunc     HTTPHeaderFunc
@@ -40,8 +42,30 @@ type SSE struct {
 
 	// OAuth support
 	oauthHandler *OAuthHandler
+
+	// Reconnection support
+	autoReconnect    bool
+	maxRetries       int
+	reconnectBackoff time.Duration
+	connectionLost   atomic.Bool
+	connectionGone   atomic.Bool              // the connection was lost and will not be re-established
+	initRequest      *JSONRPCRequest          // last successful initialize request, replayed on reconnect
+	initNotification *mcp.JSONRPCNotification // initialized notification, replayed on reconnect
 }
 
+// ErrConnectionLost is returned for requests that were in flight, or issued while
+// the SSE connection to the server is down. Such requests may be retried once the
+// connection has been re-established.
+var ErrConnectionLost = errors.New("SSE connection lost")
+
+// ErrConnectionTerminated is returned for requests once the SSE connection was
+// lost without automatic reconnection, or all reconnection attempts failed.
+// Such requests cannot succeed on this transport; a new one has to be started.
+var ErrConnectionTerminated = errors.New("SSE connection lost and not re-established")
+
+// maxReconnectBackoff caps the delay between two reconnection attempts.
+const maxReconnectBackoff = 30 * time.Second
+
 type ClientOption func(*SSE)
 
 func WithHeaders(headers map[string]string) ClientOption {
@@ -68,6 +92,22 @@ func WithOAuth(config OAuthConfig) ClientOption {
 	}
 }
 
+// WithAutoReconnect re-establishes the SSE stream when it ends unexpectedly.
+//
+// Reconnection is attempted up to maxRetries times (or until the transport is closed
+// if maxRetries is not positive), with an exponential backoff starting at backoff plus jitter.
+// Once the stream is back, the initialize handshake is replayed so the server
+// recognizes the new session. Requests in flight when the connection drops, or sent
+// while it is being re-established, fail with ErrConnectionLost. Once all attempts
+// failed, requests fail with ErrConnectionTerminated.
+func WithAutoReconnect(maxRetries int, backoff time.Duration) ClientOption {
+	return func(sc *SSE) {
+		sc.autoReconnect = true
+		sc.maxRetries = maxRetries
+		sc.reconnectBackoff = backoff
+	}
+}
+
 // NewSSE creates a new SSE-based MCP client with the given base URL.
 // Returns an error if the URL is invalid.
 func NewSSE(baseURL string, options ...ClientOption) (*SSE, error) {
@@ -109,10 +149,27 @@ func (c *SSE) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	c.cancelSSEStream = cancel
 
+	endpointChan, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.waitForEndpoint(ctx, endpointChan); err != nil {
+		cancel()
+		return err
+	}
+
+	c.started.Store(true)
+	return nil
+}
+
+// connect opens the SSE stream and starts reading it in the background.
+// The returned channel is closed once the server has sent the endpoint event.
+func (c *SSE) connect(ctx context.Context) (<-chan struct{}, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL.String(), nil)
 
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
@@ -135,47 +192,172 @@ func (c *SSE) Start(ctx context.Context) error {
 		if err != nil {
 			// If we get an authorization error, return a specific error that can be handled by the client
 			if err.Error() == "no valid token available, authorization required" {
-				return &OAuthAuthorizationRequiredError{
+				return nil, &OAuthAuthorizationRequiredError{
 					Handler: c.oauthHandler,
 				}
 			}
-			return fmt.Errorf("failed to get authorization header: %w", err)
+			return nil, fmt.Errorf("failed to get authorization header: %w", err)
 		}
 		req.Header.Set("Authorization", authHeader)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SSE stream: %w", err)
+		return nil, fmt.Errorf("failed to connect to SSE stream: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		resp.Body.Close()
 		// Handle OAuth unauthorized error
 		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
-			return &OAuthAuthorizationRequiredError{
+			return nil, &OAuthAuthorizationRequiredError{
 				Handler: c.oauthHandler,
 			}
 		}
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	go c.readSSE(resp.Body)
+	endpointChan := make(chan struct{})
+	c.mu.Lock()
+	c.endpointChan = endpointChan
+	c.mu.Unlock()
+
+	go func() {
+		c.readSSE(resp.Body)
+		c.handleConnectionLost(ctx)
+	}()
 
-	// Wait for the endpoint to be received
+	return endpointChan, nil
+}
+
+// waitForEndpoint waits until the endpoint event has been received on the current stream.
+func (c *SSE) waitForEndpoint(ctx context.Context, endpointChan <-chan struct{}) error {
 	timeout := time.NewTimer(30 * time.Second)
 	defer timeout.Stop()
 	select {
-	case <-c.endpointChan:
+	case <-endpointChan:
 		// Endpoint received, proceed
+		return nil
 	case <-ctx.Done():
-		return fmt.Errorf("context cancelled while waiting for endpoint")
+		return fmt.Errorf("context cancelled while waiting for endpoint: %w", ctx.Err())
 	case <-timeout.C: // Add a timeout
-		cancel()
 		return fmt.Errorf("timeout waiting for endpoint")
 	}
+}
 
-	c.started.Store(true)
+// handleConnectionLost is called when the SSE stream ends. Unless the transport is
+// shutting down, pending requests are failed and, if enabled, the stream is re-established.
+func (c *SSE) handleConnectionLost(ctx context.Context) {
+	if c.closed.Load() || ctx.Err() != nil || !c.started.Load() {
+		return
+	}
+
+	c.connectionLost.Store(true)
+	if !c.autoReconnect {
+		c.connectionGone.Store(true)
+	}
+
+	// Responses for pending requests would have arrived on the lost stream
+	c.mu.Lock()
+	for _, ch := range c.responses {
+		close(ch)
+	}
+	c.responses = make(map[string]chan *JSONRPCResponse)
+	c.mu.Unlock()
+
+	if c.autoReconnect {
+		c.reconnect(ctx)
+	}
+}
+
+// reconnect re-establishes the SSE stream with exponential backoff and replays the
+// initialize handshake on success.
+func (c *SSE) reconnect(ctx context.Context) {
+	for attempt := 0; c.maxRetries <= 0 || attempt < c.maxRetries; attempt++ {
+		timer := time.NewTimer(reconnectDelay(c.reconnectBackoff, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if c.closed.Load() {
+			return
+		}
+
+		endpointChan, err := c.connect(ctx)
+		if err != nil {
+			continue
+		}
+		if err := c.waitForEndpoint(ctx, endpointChan); err != nil {
+			continue
+		}
+		if err := c.replayInitialize(ctx); err != nil {
+			continue
+		}
+
+		c.connectionLost.Store(false)
+		return
+	}
+	if !c.closed.Load() && ctx.Err() == nil {
+		c.connectionGone.Store(true)
+	}
+}
+
+// connectionError returns the error for requests while the connection is down,
+// or nil while it is up.
+func (c *SSE) connectionError() error {
+	if c.connectionGone.Load() {
+		return ErrConnectionTerminated
+	}
+	if c.connectionLost.Load() {
+		return ErrConnectionLost
+	}
+	return nil
+}
+
+// reconnectDelay returns the exponential backoff delay for the given attempt,
+// randomized to within ±50% to avoid synchronized reconnects.
+func reconnectDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxReconnectBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxReconnectBackoff {
+		delay = maxReconnectBackoff
+	}
+	return delay/2 + time.Duration(rand.Int64N(int64(delay)))
+}
+
+// replayInitialize repeats the initialize handshake recorded on the previous connection.
+func (c *SSE) replayInitialize(ctx context.Context) error {
+	c.mu.RLock()
+	initRequest := c.initRequest
+	initNotification := c.initNotification
+	c.mu.RUnlock()
+
+	if initRequest == nil {
+		return nil
+	}
+
+	request := *initRequest
+	request.ID = mcp.NewRequestId(fmt.Sprintf("reconnect-%s", initRequest.ID.String()))
+	response, err := c.sendRequest(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to replay initialize request: %w", err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("failed to replay initialize request: %s", response.Error.Message)
+	}
+
+	if initNotification != nil {
+		if err := c.sendNotification(ctx, *initNotification); err != nil {
+			return fmt.Errorf("failed to replay initialized notification: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -247,10 +429,23 @@ func (c *SSE) handleSSEEvent(event, data string) {
 			fmt.Printf("Endpoint origin does not match connection origin\n")
 			return
 		}
+		c.mu.Lock()
 		c.endpoint = endpoint
-		close(c.endpointChan)
+		select {
+		case <-c.endpointChan:
+			// endpoint already received on this stream
+		default:
+			close(c.endpointChan)
+		}
+		c.mu.Unlock()
 
 	case "message":
+		// Handle request from the server
+		if request, ok := parseIncomingRequest([]byte(data)); ok {
+			go c.handleServerRequest(request)
+			return
+		}
+
 		var baseMessage JSONRPCResponse
 		if err := json.Unmarshal([]byte(data), &baseMessage); err != nil {
 			fmt.Printf("Error unmarshaling message: %v\n", err)
@@ -293,6 +488,32 @@ func (c *SSE) SetNotificationHandler(handler func(notification mcp.JSONRPCNotifi
 	c.onNotification = handler
 }
 
+// SetRequestHandler sets the handler called for requests sent by the server.
+func (c *SSE) SetRequestHandler(handler RequestHandler) {
+	c.requestHandler.set(handler)
+}
+
+var _ BidirectionalInterface = (*SSE)(nil)
+
+// handleServerRequest runs the request handler and posts the response to the message endpoint.
+func (c *SSE) handleServerRequest(request JSONRPCRequest) {
+	response := c.requestHandler.handle(context.Background(), request)
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		fmt.Printf("Error marshaling response: %v\n", err)
+		return
+	}
+	endpoint := c.GetEndpoint()
+	if endpoint == nil {
+		fmt.Printf("Error sending response: endpoint not received\n")
+		return
+	}
+	if err := c.postMessage(context.Background(), endpoint, responseBytes); err != nil {
+		fmt.Printf("Error sending response: %v\n", err)
+	}
+}
+
 // SendRequest sends a JSON-RPC request to the server and waits for a response.
 // Returns the raw JSON response message or an error if the request fails.
 func (c *SSE) SendRequest(
@@ -306,7 +527,30 @@ func (c *SSE) SendRequest(
 	if c.closed.Load() {
 		return nil, fmt.Errorf("transport has been closed")
 	}
-	if c.endpoint == nil {
+	if err := c.connectionError(); err != nil {
+		return nil, err
+	}
+
+	response, err := c.sendRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Method == string(mcp.MethodInitialize) && response.Error == nil {
+		c.mu.Lock()
+		c.initRequest = &request
+		c.mu.Unlock()
+	}
+	return response, nil
+}
+
+// sendRequest posts the request to the message endpoint and waits for its response on the SSE stream.
+func (c *SSE) sendRequest(
+	ctx context.Context,
+	request JSONRPCRequest,
+) (*JSONRPCResponse, error) {
+	endpoint := c.GetEndpoint()
+	if endpoint == nil {
 		return nil, fmt.Errorf("endpoint not received")
 	}
 
@@ -317,7 +561,7 @@ func (c *SSE) SendRequest(
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint.String(), bytes.NewReader(requestBytes))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(requestBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -400,6 +644,9 @@ func (c *SSE) SendRequest(
 		if ok {
 			return response, nil
 		}
+		if !c.closed.Load() {
+			return nil, c.connectionError()
+		}
 		return nil, fmt.Errorf("connection has been closed")
 	}
 }
@@ -430,7 +677,26 @@ func (c *SSE) Close() error {
 
 // SendNotification sends a JSON-RPC notification to the server without expecting a response.
 func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
-	if c.endpoint == nil {
+	if err := c.connectionError(); err != nil {
+		return err
+	}
+
+	if err := c.sendNotification(ctx, notification); err != nil {
+		return err
+	}
+
+	if notification.Method == "notifications/initialized" {
+		c.mu.Lock()
+		c.initNotification = &notification
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// sendNotification posts the notification to the message endpoint.
+func (c *SSE) sendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	endpoint := c.GetEndpoint()
+	if endpoint == nil {
 		return fmt.Errorf("endpoint not received")
 	}
 
@@ -439,11 +705,17 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 		return fmt.Errorf("failed to marshal notification: %w", err)
 	}
 
+	return c.postMessage(ctx, endpoint, notificationBytes)
+}
+
+// postMessage posts a message that expects no response, such as a notification
+// or a response to a server request, to the message endpoint.
+func (c *SSE) postMessage(ctx context.Context, endpoint *url.URL, body []byte) error {
 	req, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		c.endpoint.String(),
-		bytes.NewReader(notificationBytes),
+		endpoint.String(),
+		bytes.NewReader(body),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create notification request: %w", err)
@@ -503,6 +775,8 @@ func (c *SSE) SendNotification(ctx context.Context, notification mcp.JSONRPCNoti
 
 // GetEndpoint returns the current endpoint URL for the SSE connection.
 func (c *SSE) GetEndpoint() *url.URL {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.endpoint
 }
 
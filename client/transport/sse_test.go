@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -625,3 +626,293 @@ func TestSSEErrors(t *testing.T) {
 	})
 
 }
+
+func TestSSEAutoReconnect(t *testing.T) {
+	var mu sync.Mutex
+	var connections int
+	var initializeCount int
+	var initializedCount int
+	events := make(map[int]chan []byte)
+	dropFirst := make(chan struct{})
+
+	sseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+
+		mu.Lock()
+		connections++
+		conn := connections
+		ch := make(chan []byte, 10)
+		events[conn] = ch
+		mu.Unlock()
+
+		fmt.Fprintf(w, "event: endpoint\ndata: /message?conn=%d\n\n", conn)
+		flusher.Flush()
+
+		var drop <-chan struct{}
+		if conn == 1 {
+			drop = dropFirst
+		}
+		for {
+			select {
+			case data := <-ch:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				flusher.Flush()
+			case <-drop:
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+
+	messageHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		var conn int
+		fmt.Sscanf(r.URL.Query().Get("conn"), "%d", &conn)
+
+		mu.Lock()
+		ch := events[conn]
+		switch request["method"] {
+		case "initialize":
+			initializeCount++
+		case "notifications/initialized":
+			initializedCount++
+		}
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusAccepted)
+		if request["id"] == nil || request["method"] == "debug/hang" {
+			return
+		}
+		data, _ := json.Marshal(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      request["id"],
+			"result":  request["method"],
+		})
+		ch <- data
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/", sseHandler)
+	mux.Handle("/message", messageHandler)
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	trans, err := NewSSE(testServer.URL, WithAutoReconnect(5, 10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := trans.Start(ctx); err != nil {
+		t.Fatalf("Failed to start transport: %v", err)
+	}
+	defer trans.Close()
+
+	if _, err := trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "initialize",
+	}); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	if err := trans.SendNotification(ctx, mcp.JSONRPCNotification{
+		JSONRPC:      "2.0",
+		Notification: mcp.Notification{Method: "notifications/initialized"},
+	}); err != nil {
+		t.Fatalf("initialized notification failed: %v", err)
+	}
+
+	// A request in flight when the stream drops fails with a retryable error
+	inFlight := make(chan error, 1)
+	go func() {
+		_, err := trans.SendRequest(ctx, JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(2)),
+			Method:  "debug/hang",
+		})
+		inFlight <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(dropFirst)
+
+	select {
+	case err := <-inFlight:
+		if !errors.Is(err, ErrConnectionLost) {
+			t.Errorf("Expected ErrConnectionLost, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("In-flight request did not fail after connection loss")
+	}
+
+	// Wait until the transport has reconnected and replayed the handshake
+	deadline := time.Now().Add(5 * time.Second)
+	for trans.connectionLost.Load() || trans.GetEndpoint().Query().Get("conn") != "2" {
+		if time.Now().After(deadline) {
+			t.Fatal("Transport did not reconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	if initializeCount != 2 || initializedCount != 2 {
+		t.Errorf("Expected initialize handshake to be replayed, got %d initialize and %d initialized", initializeCount, initializedCount)
+	}
+	mu.Unlock()
+
+	response, err := trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(3)),
+		Method:  "debug/echo",
+	})
+	if err != nil {
+		t.Fatalf("SendRequest after reconnect failed: %v", err)
+	}
+	var result string
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	if result != "debug/echo" {
+		t.Errorf("Expected 'debug/echo', got '%s'", result)
+	}
+}
+
+func TestSSEConnectionTerminated(t *testing.T) {
+	// newServer serves a single SSE stream, which ends when drop is closed
+	newServer := func(drop <-chan struct{}) *httptest.Server {
+		var connections atomic.Int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			if connections.Add(1) > 1 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: endpoint\ndata: /message\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-drop:
+			case <-r.Context().Done():
+			}
+		}))
+	}
+	request := JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "ping",
+	}
+
+	for name, options := range map[string][]ClientOption{
+		"without auto-reconnect":    nil,
+		"after reconnection failed": {WithAutoReconnect(2, time.Millisecond)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			drop := make(chan struct{})
+			testServer := newServer(drop)
+			defer testServer.Close()
+
+			trans, err := NewSSE(testServer.URL, options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			if err := trans.Start(ctx); err != nil {
+				t.Fatalf("Failed to start transport: %v", err)
+			}
+			defer trans.Close()
+			close(drop)
+
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				_, err := trans.SendRequest(ctx, request)
+				if errors.Is(err, ErrConnectionTerminated) {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("Expected ErrConnectionTerminated, got: %v", err)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			err = trans.SendNotification(ctx, mcp.JSONRPCNotification{
+				JSONRPC:      "2.0",
+				Notification: mcp.Notification{Method: "notifications/initialized"},
+			})
+			if !errors.Is(err, ErrConnectionTerminated) {
+				t.Errorf("Expected ErrConnectionTerminated for notifications, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestSSEReconnectDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt := 0; attempt < 10; attempt++ {
+		expected := base << attempt
+		if expected > maxReconnectBackoff {
+			expected = maxReconnectBackoff
+		}
+		delay := reconnectDelay(base, attempt)
+		if delay < expected/2 || delay >= expected/2+expected {
+			t.Errorf("attempt %d: delay %v outside of [%v, %v)", attempt, delay, expected/2, expected/2+expected)
+		}
+	}
+}
+
+func TestSSEServerRequest(t *testing.T) {
+	responses := make(chan map[string]any, 1)
+
+	sseHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		fmt.Fprint(w, "event: endpoint\ndata: /message\n\n")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":\"srv-1\",\"method\":\"roots/list\"}\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	})
+	messageHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		responses <- message
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/", sseHandler)
+	mux.Handle("/message", messageHandler)
+	testServer := httptest.NewServer(mux)
+	defer testServer.Close()
+
+	trans, err := NewSSE(testServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trans.SetRequestHandler(func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		return NewJSONRPCResultResponse(request.ID, json.RawMessage(`{"roots":[]}`)), nil
+	})
+	if err := trans.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start transport: %v", err)
+	}
+	defer trans.Close()
+
+	select {
+	case response := <-responses:
+		if response["id"] != "srv-1" {
+			t.Errorf("Expected response id srv-1, got %v", response["id"])
+		}
+		if _, ok := response["result"]; !ok {
+			t.Errorf("Expected result in response, got %v", response)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for response to server request")
+	}
+}
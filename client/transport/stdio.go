@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/mathiasXie/mcp-go/mcp"
@@ -18,9 +20,12 @@ import (
 // using JSON-RPC messages. The client handles message routing between requests and
 // responses, and supports asynchronous notifications.
 type Stdio struct {
-	command string
-	args    []string
-	env     []string
+	command      string
+	args         []string
+	env          []string
+	inheritEnv   bool
+	dir          string
+	stderrWriter io.Writer
 
 	cmd            *exec.Cmd
 	stdin          io.WriteCloser
@@ -29,8 +34,10 @@ type Stdio struct {
 	responses      map[string]chan *JSONRPCResponse
 	mu             sync.RWMutex
 	done           chan struct{}
+	exited         chan struct{} // closed when reading from the subprocess stops
 	onNotification func(mcp.JSONRPCNotification)
 	notifyMu       sync.RWMutex
+	requestHandler requestHandlerHolder
 }
 
 // NewIO returns a new stdio-based transport using existing input, output, and
@@ -44,6 +51,43 @@ func NewIO(input io.Reader, output io.WriteCloser, logging io.ReadCloser) *Stdio
 
 		responses: make(map[string]chan *JSONRPCResponse),
 		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+}
+
+// StdioOption defines a function that configures a Stdio transport instance.
+type StdioOption func(*Stdio)
+
+// WithCommandEnv adds environment variables, in "KEY=value" form, to the subprocess environment.
+// By default they are merged with the parent process environment; see WithInheritEnv.
+func WithCommandEnv(env []string) StdioOption {
+	return func(s *Stdio) {
+		s.env = append(slices.Clone(s.env), env...)
+	}
+}
+
+// WithInheritEnv controls whether the subprocess inherits the parent process environment.
+// When false, the subprocess only sees the variables passed to the constructor and WithCommandEnv.
+// The default is true.
+func WithInheritEnv(inherit bool) StdioOption {
+	return func(s *Stdio) {
+		s.inheritEnv = inherit
+	}
+}
+
+// WithCommandDir sets the working directory of the subprocess.
+// The default is the working directory of the parent process.
+func WithCommandDir(dir string) StdioOption {
+	return func(s *Stdio) {
+		s.dir = dir
+	}
+}
+
+// WithStderr copies the stderr output of the subprocess to w, which is useful for debugging.
+// When set, the reader returned by Stderr is empty.
+func WithStderr(w io.Writer) StdioOption {
+	return func(s *Stdio) {
+		s.stderrWriter = w
 	}
 }
 
@@ -55,14 +99,31 @@ func NewStdio(
 	env []string,
 	args ...string,
 ) *Stdio {
+	return NewStdioWithOptions(command, env, args)
+}
+
+// NewStdioWithOptions creates a new stdio transport to communicate with a subprocess,
+// configured with the given options.
+func NewStdioWithOptions(
+	command string,
+	env []string,
+	args []string,
+	opts ...StdioOption,
+) *Stdio {
 
 	client := &Stdio{
-		command: command,
-		args:    args,
-		env:     env,
+		command:    command,
+		args:       args,
+		env:        env,
+		inheritEnv: true,
 
 		responses: make(map[string]chan *JSONRPCResponse),
 		done:      make(chan struct{}),
+		exited:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(client)
 	}
 
 	return client
@@ -91,10 +152,15 @@ func (c *Stdio) spawnCommand(ctx context.Context) error {
 
 	cmd := exec.CommandContext(ctx, c.command, c.args...)
 
-	mergedEnv := os.Environ()
+	// A nil Env makes the subprocess inherit the parent environment
+	mergedEnv := []string{}
+	if c.inheritEnv {
+		mergedEnv = os.Environ()
+	}
 	mergedEnv = append(mergedEnv, c.env...)
 
 	cmd.Env = mergedEnv
+	cmd.Dir = c.dir
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -106,9 +172,15 @@ func (c *Stdio) spawnCommand(ctx context.Context) error {
 		return fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	var stderr io.ReadCloser
+	if c.stderrWriter != nil {
+		cmd.Stderr = c.stderrWriter
+		stderr = io.NopCloser(strings.NewReader(""))
+	} else {
+		stderr, err = cmd.StderrPipe()
+		if err != nil {
+			return fmt.Errorf("failed to create stderr pipe: %w", err)
+		}
 	}
 
 	c.cmd = cmd
@@ -158,10 +230,18 @@ func (c *Stdio) SetNotificationHandler(
 	c.onNotification = handler
 }
 
+// SetRequestHandler sets the handler called for requests sent by the server.
+func (c *Stdio) SetRequestHandler(handler RequestHandler) {
+	c.requestHandler.set(handler)
+}
+
+var _ BidirectionalInterface = (*Stdio)(nil)
+
 // readResponses continuously reads and processes responses from the server's stdout.
-// It handles both responses to requests and notifications, routing them appropriately.
+// It handles responses to requests, notifications and requests from the server, routing them appropriately.
 // Runs until the done channel is closed or an error occurs reading from stdout.
 func (c *Stdio) readResponses() {
+	defer close(c.exited)
 	for {
 		select {
 		case <-c.done:
@@ -175,6 +255,12 @@ func (c *Stdio) readResponses() {
 				return
 			}
 
+			// Handle request from the server
+			if request, ok := parseIncomingRequest([]byte(line)); ok {
+				go c.handleServerRequest(request)
+				continue
+			}
+
 			var baseMessage JSONRPCResponse
 			if err := json.Unmarshal([]byte(line), &baseMessage); err != nil {
 				continue
@@ -211,6 +297,33 @@ func (c *Stdio) readResponses() {
 	}
 }
 
+// alive reports whether the transport is still reading from the subprocess,
+// that is, whether the subprocess has not exited or closed its stdout.
+func (c *Stdio) alive() bool {
+	select {
+	case <-c.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// handleServerRequest runs the request handler and writes the response to stdin.
+func (c *Stdio) handleServerRequest(request JSONRPCRequest) {
+	response := c.requestHandler.handle(context.Background(), request)
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		fmt.Printf("Error marshaling response: %v\n", err)
+		return
+	}
+	responseBytes = append(responseBytes, '\n')
+
+	if _, err := c.stdin.Write(responseBytes); err != nil {
+		fmt.Printf("Error writing response: %v\n", err)
+	}
+}
+
 // SendRequest sends a JSON-RPC request to the server and waits for a response.
 // It creates a unique request ID, sends the request over stdin, and waits for
 // the corresponding response or context cancellation.
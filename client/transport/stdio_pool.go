@@ -0,0 +1,342 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// ErrStdioPoolClosed is returned by StdioPool.Get after the pool is closed.
+var ErrStdioPoolClosed = errors.New("stdio pool closed")
+
+const (
+	defaultStdioPoolSize        = 4
+	defaultStdioPoolPingTimeout = 5 * time.Second
+)
+
+// StdioPoolOption configures a StdioPool.
+type StdioPoolOption func(*StdioPool)
+
+// WithPoolSize sets how many idle subprocesses the pool keeps warm. Processes
+// returned while the pool is full are shut down. The default is 4.
+func WithPoolSize(size int) StdioPoolOption {
+	return func(p *StdioPool) {
+		if size >= 0 {
+			p.size = size
+		}
+	}
+}
+
+// WithPoolPingTimeout sets how long the pool waits for a process to answer the
+// ping sent when it is returned. Processes that don't answer in time are
+// discarded. The default is 5 seconds.
+func WithPoolPingTimeout(timeout time.Duration) StdioPoolOption {
+	return func(p *StdioPool) {
+		p.pingTimeout = timeout
+	}
+}
+
+// WithPoolStdioOptions sets the options of the stdio transports of the
+// subprocesses, such as WithCommandDir. The stderr output of pooled
+// subprocesses is discarded unless WithStderr is given.
+func WithPoolStdioOptions(opts ...StdioOption) StdioPoolOption {
+	return func(p *StdioPool) {
+		p.stdioOptions = append(p.stdioOptions, opts...)
+	}
+}
+
+// StdioPoolStats reports the state of a StdioPool.
+type StdioPoolStats struct {
+	// Idle is the number of warm processes waiting in the pool.
+	Idle int
+	// Active is the number of processes handed out and not returned yet.
+	Active int
+	// Spawned is the total number of processes started by the pool.
+	Spawned int64
+	// Discarded is the total number of processes dropped because they had
+	// exited or failed the health check.
+	Discarded int64
+}
+
+// StdioPool reuses subprocesses across short-lived clients of the same
+// command, saving the cost of spawning a process for every client:
+//
+//	pool := transport.NewStdioPool("./server", nil, nil, transport.WithPoolSize(2))
+//	defer pool.Close()
+//
+//	trans, err := pool.Get(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	c := client.NewClient(trans)
+//	defer c.Close() // returns the process to the pool
+//
+// Closing a transport returned by Get returns its process to the pool, after
+// checking with a ping that it is still healthy. Processes that exited, failed
+// the ping or have a request abandoned by its caller, whose response could
+// reach the next client, are discarded instead; replacements are spawned
+// when needed by Get.
+//
+// Each client initializes the MCP session again on the process it gets, so
+// the server must accept repeated initialize requests. Per-session state, such
+// as the logging level or resource subscriptions, carries over.
+type StdioPool struct {
+	command      string
+	env          []string
+	args         []string
+	stdioOptions []StdioOption
+	size         int
+	pingTimeout  time.Duration
+
+	mu        sync.Mutex
+	idle      []*Stdio
+	active    int
+	spawned   int64
+	discarded int64
+	closed    bool
+
+	pings atomic.Int64
+}
+
+// NewStdioPool creates a pool of subprocesses running command with the given
+// environment and arguments, as passed to NewStdioWithOptions. Processes are
+// spawned on demand by Get, or ahead of time by Warm.
+func NewStdioPool(command string, env []string, args []string, opts ...StdioPoolOption) *StdioPool {
+	p := &StdioPool{
+		command:      command,
+		env:          env,
+		args:         args,
+		stdioOptions: []StdioOption{WithStderr(io.Discard)},
+		size:         defaultStdioPoolSize,
+		pingTimeout:  defaultStdioPoolPingTimeout,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Get returns a transport to an idle process of the pool, spawning a new
+// process if none is idle. The transport is already started; Start is a no-op.
+func (p *StdioPool) Get(ctx context.Context) (*PooledStdio, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrStdioPoolClosed
+		}
+		if n := len(p.idle); n > 0 {
+			// The most recently returned process is the least likely to have exited
+			stdio := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			if !stdio.alive() {
+				p.discarded++
+				p.mu.Unlock()
+				_ = stdio.Close()
+				continue
+			}
+			p.active++
+			p.mu.Unlock()
+			return &PooledStdio{pool: p, stdio: stdio}, nil
+		}
+		p.active++
+		p.mu.Unlock()
+
+		stdio, err := p.spawn(ctx)
+		if err != nil {
+			p.mu.Lock()
+			p.active--
+			p.mu.Unlock()
+			return nil, err
+		}
+		return &PooledStdio{pool: p, stdio: stdio}, nil
+	}
+}
+
+// Warm spawns processes until the pool holds as many idle processes as its size.
+func (p *StdioPool) Warm(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		full := p.closed || len(p.idle) >= p.size
+		p.mu.Unlock()
+		if full {
+			return nil
+		}
+
+		stdio, err := p.spawn(ctx)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		if p.closed || len(p.idle) >= p.size {
+			p.mu.Unlock()
+			_ = stdio.Close()
+			return nil
+		}
+		p.idle = append(p.idle, stdio)
+		p.mu.Unlock()
+	}
+}
+
+// Stats returns the current state of the pool.
+func (p *StdioPool) Stats() StdioPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return StdioPoolStats{
+		Idle:      len(p.idle),
+		Active:    p.active,
+		Spawned:   p.spawned,
+		Discarded: p.discarded,
+	}
+}
+
+// Close shuts down the idle processes. Processes handed out are shut down
+// when their transport is closed, and Get fails from now on.
+func (p *StdioPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, stdio := range idle {
+		if err := stdio.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// spawn starts a new process. The process outlives ctx, which only bounds
+// how long spawning may take.
+func (p *StdioPool) spawn(ctx context.Context) (*Stdio, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	stdio := NewStdioWithOptions(p.command, p.env, p.args, p.stdioOptions...)
+	if err := stdio.Start(context.WithoutCancel(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to spawn pooled process: %w", err)
+	}
+	p.mu.Lock()
+	p.spawned++
+	p.mu.Unlock()
+	return stdio, nil
+}
+
+// release takes back a process handed out by Get, keeping it if it is healthy
+// and the pool is not full.
+func (p *StdioPool) release(stdio *Stdio, healthy bool) {
+	// The handlers of the previous client must not see later messages
+	stdio.SetNotificationHandler(nil)
+	stdio.SetRequestHandler(nil)
+	healthy = healthy && stdio.alive() && p.ping(stdio) == nil
+
+	p.mu.Lock()
+	p.active--
+	keep := healthy && !p.closed && len(p.idle) < p.size
+	if keep {
+		p.idle = append(p.idle, stdio)
+	} else if !healthy {
+		p.discarded++
+	}
+	p.mu.Unlock()
+
+	if !keep {
+		_ = stdio.Close()
+	}
+}
+
+// ping checks that the process still answers requests.
+func (p *StdioPool) ping(stdio *Stdio) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.pingTimeout)
+	defer cancel()
+
+	// String IDs never collide with the numeric IDs used by clients
+	response, err := stdio.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(fmt.Sprintf("stdio-pool-ping-%d", p.pings.Add(1))),
+		Method:  string(mcp.MethodPing),
+	})
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("ping failed: %s", response.Error.Message)
+	}
+	return nil
+}
+
+// PooledStdio is a stdio transport to a process of a StdioPool. Closing it
+// returns the process to the pool instead of shutting it down.
+type PooledStdio struct {
+	pool      *StdioPool
+	stdio     *Stdio
+	closed    atomic.Bool
+	abandoned atomic.Bool // a request was given up before its response arrived
+}
+
+var _ BidirectionalInterface = (*PooledStdio)(nil)
+
+var errPooledStdioClosed = errors.New("pooled stdio transport is closed")
+
+// Start does nothing, since the process of the pool is already running.
+func (t *PooledStdio) Start(ctx context.Context) error {
+	if t.closed.Load() {
+		return errPooledStdioClosed
+	}
+	return nil
+}
+
+func (t *PooledStdio) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	if t.closed.Load() {
+		return nil, errPooledStdioClosed
+	}
+	response, err := t.stdio.SendRequest(ctx, request)
+	if err != nil && ctx.Err() != nil {
+		t.abandoned.Store(true)
+	}
+	return response, err
+}
+
+func (t *PooledStdio) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	if t.closed.Load() {
+		return errPooledStdioClosed
+	}
+	return t.stdio.SendNotification(ctx, notification)
+}
+
+func (t *PooledStdio) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	if t.closed.Load() {
+		return
+	}
+	t.stdio.SetNotificationHandler(handler)
+}
+
+// SetRequestHandler sets the handler called for requests sent by the server.
+func (t *PooledStdio) SetRequestHandler(handler RequestHandler) {
+	if t.closed.Load() {
+		return
+	}
+	t.stdio.SetRequestHandler(handler)
+}
+
+// Close returns the process to the pool. It is discarded if it is no longer
+// healthy.
+func (t *PooledStdio) Close() error {
+	if !t.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	t.pool.release(t.stdio, !t.abandoned.Load())
+	return nil
+}
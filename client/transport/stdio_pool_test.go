@@ -0,0 +1,153 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestStdioPool(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "mockstdio_server")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tempFile.Close()
+	mockServerPath := tempFile.Name()
+
+	// Add .exe suffix on Windows
+	if runtime.GOOS == "windows" {
+		os.Remove(mockServerPath) // Remove the empty file first
+		mockServerPath += ".exe"
+	}
+
+	if compileErr := compileTestServer(mockServerPath); compileErr != nil {
+		t.Fatalf("Failed to compile mock server: %v", compileErr)
+	}
+	defer os.Remove(mockServerPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pool := NewStdioPool(mockServerPath, nil, nil, WithPoolSize(1))
+	defer pool.Close()
+
+	expectStats := func(t *testing.T, want StdioPoolStats) {
+		t.Helper()
+		if got := pool.Stats(); got != want {
+			t.Errorf("Expected stats %+v, got %+v", want, got)
+		}
+	}
+	ping := func(t *testing.T, trans *PooledStdio) {
+		t.Helper()
+		response, err := trans.SendRequest(ctx, JSONRPCRequest{
+			JSONRPC: mcp.JSONRPC_VERSION,
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  string(mcp.MethodPing),
+		})
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if response.Error != nil {
+			t.Fatalf("Unexpected error response: %s", response.Error.Message)
+		}
+	}
+	// exit makes the process exit and waits until the transport notices
+	exit := func(t *testing.T, stdio *Stdio) {
+		t.Helper()
+		notification := mcp.JSONRPCNotification{JSONRPC: mcp.JSONRPC_VERSION}
+		notification.Method = "debug/exit"
+		if err := stdio.SendNotification(ctx, notification); err != nil {
+			t.Fatalf("SendNotification failed: %v", err)
+		}
+		select {
+		case <-stdio.exited:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the process to exit")
+		}
+	}
+
+	if err := pool.Warm(ctx); err != nil {
+		t.Fatalf("Warm failed: %v", err)
+	}
+	expectStats(t, StdioPoolStats{Idle: 1, Spawned: 1})
+
+	// A returned process is handed out again
+	first, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if err := first.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	ping(t, first)
+	expectStats(t, StdioPoolStats{Active: 1, Spawned: 1})
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := first.SendRequest(ctx, JSONRPCRequest{Method: "ping"}); err == nil {
+		t.Error("Expected SendRequest to fail after Close")
+	}
+	expectStats(t, StdioPoolStats{Idle: 1, Spawned: 1})
+
+	second, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if second.stdio != first.stdio {
+		t.Error("Expected the idle process to be reused")
+	}
+	ping(t, second)
+
+	// Processes beyond the pool size are spawned on demand and shut down when returned
+	third, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	ping(t, third)
+	expectStats(t, StdioPoolStats{Active: 2, Spawned: 2})
+	second.Close()
+	third.Close()
+	expectStats(t, StdioPoolStats{Idle: 1, Spawned: 2})
+
+	// A process that exits while in use is discarded when returned
+	fourth, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	exit(t, fourth.stdio)
+	fourth.Close()
+	expectStats(t, StdioPoolStats{Spawned: 2, Discarded: 1})
+
+	// A process that exits while idle is replaced lazily by Get
+	fifth, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	fifth.Close()
+	expectStats(t, StdioPoolStats{Idle: 1, Spawned: 3, Discarded: 1})
+	exit(t, fifth.stdio)
+	sixth, err := pool.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if sixth.stdio == fifth.stdio {
+		t.Error("Expected the exited process to be replaced")
+	}
+	ping(t, sixth)
+	expectStats(t, StdioPoolStats{Active: 1, Spawned: 4, Discarded: 2})
+
+	// Processes handed out are shut down once returned to a closed pool
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := pool.Get(ctx); !errors.Is(err, ErrStdioPoolClosed) {
+		t.Errorf("Expected ErrStdioPoolClosed, got %v", err)
+	}
+	sixth.Close()
+	expectStats(t, StdioPoolStats{Spawned: 4, Discarded: 2})
+}
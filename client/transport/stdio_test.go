@@ -1,12 +1,16 @@
 package transport
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -485,3 +489,143 @@ func TestStdioErrors(t *testing.T) {
 	})
 
 }
+
+func TestStdioServerRequest(t *testing.T) {
+	serverOut, clientIn := io.Pipe()
+	clientOut, serverIn := io.Pipe()
+	stdio := NewIO(serverOut, serverIn, io.NopCloser(&strings.Reader{}))
+	defer stdio.Close()
+
+	stdio.SetRequestHandler(func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		if request.Method != "roots/list" {
+			return nil, fmt.Errorf("unexpected method %s", request.Method)
+		}
+		return NewJSONRPCResultResponse(request.ID, json.RawMessage(`{"roots":[]}`)), nil
+	})
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start transport: %v", err)
+	}
+
+	go func() {
+		fmt.Fprintln(clientIn, `{"jsonrpc":"2.0","id":7,"method":"roots/list"}`)
+	}()
+
+	line, err := bufio.NewReader(clientOut).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	var response map[string]any
+	if err := json.Unmarshal([]byte(line), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response["id"] != float64(7) {
+		t.Errorf("Expected response id 7, got %v", response["id"])
+	}
+	if _, ok := response["error"]; ok {
+		t.Errorf("Expected no error in response, got %s", line)
+	}
+	if result, ok := response["result"].(map[string]any); !ok || result["roots"] == nil {
+		t.Errorf("Expected roots result, got %s", line)
+	}
+}
+
+// syncBuffer is a strings.Builder safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStdioCommandOptions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on a POSIX shell")
+	}
+	t.Setenv("MCP_PARENT_VAR", "parent")
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	script := `echo "$MCP_TEST_VAR:${MCP_PARENT_VAR:-unset}:$(pwd)" >&2`
+
+	tests := []struct {
+		name     string
+		inherit  bool
+		expected string
+	}{
+		{name: "MergedEnv", inherit: true, expected: "child:parent:" + dir},
+		{name: "ReplacedEnv", inherit: false, expected: "child:unset:" + dir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr syncBuffer
+			stdio := NewStdioWithOptions(
+				"/bin/sh", nil, []string{"-c", script},
+				WithCommandEnv([]string{"MCP_TEST_VAR=child"}),
+				WithInheritEnv(tt.inherit),
+				WithCommandDir(dir),
+				WithStderr(&stderr),
+			)
+			if err := stdio.Start(context.Background()); err != nil {
+				t.Fatalf("Failed to start transport: %v", err)
+			}
+			if err := stdio.Close(); err != nil {
+				t.Fatalf("Failed to close transport: %v", err)
+			}
+
+			if got := strings.TrimSpace(stderr.String()); got != tt.expected {
+				t.Errorf("Expected stderr %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStdioEmptyEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test relies on a POSIX shell")
+	}
+	t.Setenv("MCP_PARENT_VAR", "parent")
+
+	var stderr syncBuffer
+	stdio := NewStdioWithOptions(
+		"/bin/sh", nil, []string{"-c", `echo "${MCP_PARENT_VAR:-unset}" >&2`},
+		WithInheritEnv(false),
+		WithStderr(&stderr),
+	)
+	if err := stdio.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start transport: %v", err)
+	}
+	if err := stdio.Close(); err != nil {
+		t.Fatalf("Failed to close transport: %v", err)
+	}
+
+	if got := strings.TrimSpace(stderr.String()); got != "unset" {
+		t.Errorf("Expected an empty environment, got MCP_PARENT_VAR=%q", got)
+	}
+}
+
+func TestStdioWithCommandEnvDoesNotAlias(t *testing.T) {
+	env := make([]string, 1, 2)
+	env[0] = "A=1"
+	first := NewStdioWithOptions("cmd", env, nil, WithCommandEnv([]string{"B=2"}))
+	second := NewStdioWithOptions("cmd", env, nil, WithCommandEnv([]string{"C=3"}))
+
+	if got := strings.Join(first.env, ","); got != "A=1,B=2" {
+		t.Errorf("Expected first env A=1,B=2, got %s", got)
+	}
+	if got := strings.Join(second.env, ","); got != "A=1,C=3" {
+		t.Errorf("Expected second env A=1,C=3, got %s", got)
+	}
+}
@@ -35,10 +35,18 @@ func WithContinuousListening() StreamableHTTPCOption {
 	}
 }
 
-// WithHTTPClient sets a custom HTTP client on the StreamableHTTP transport.
+// WithHTTPBasicClient sets a custom HTTP client on the StreamableHTTP transport,
+// e.g. to configure a proxy, TLS settings or timeouts. It is the StreamableHTTP
+// counterpart of WithHTTPClient. Without it, a client with default settings is used.
+//
+// The client's Timeout applies to regular requests only; the long-lived GET
+// connection of WithContinuousListening is sent with the same Transport but
+// without the Timeout, so that it is not cut off while idle.
 func WithHTTPBasicClient(client *http.Client) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
-		sc.httpClient = client
+		if client != nil {
+			sc.httpClient = client
+		}
 	}
 }
 
@@ -54,10 +62,54 @@ func WithHTTPHeaderFunc(headerFunc HTTPHeaderFunc) StreamableHTTPCOption {
 	}
 }
 
+// WithRequestCompression gzips request bodies of at least 1 KiB and sets their
+// Content-Encoding header. Only enable it for servers that accept compressed
+// requests; smaller bodies are always sent uncompressed.
+func WithRequestCompression(enabled bool) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.requestCompression = enabled
+	}
+}
+
+// WithRetry retries idempotent requests that fail with a transient error: a
+// 502, 503 or 504 status or a reset connection. A request is sent at most
+// maxAttempts times; backoff gives the delay before each retry, unless the
+// server sent a Retry-After header. A nil backoff uses DefaultBackoff.
+//
+// The initialize, ping and resources/read requests and all */list requests are
+// idempotent. Tool calls are not retried, since tools may have side effects,
+// unless the tool was opted in with WithRetryableTools.
+func WithRetry(maxAttempts int, backoff BackoffFunc) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		if backoff == nil {
+			backoff = DefaultBackoff
+		}
+		sc.retryAttempts = maxAttempts
+		sc.retryBackoff = backoff
+	}
+}
+
+// WithRetryableTools marks the calls of the named tools as idempotent, so that
+// they are retried as configured by WithRetry.
+func WithRetryableTools(names ...string) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		if sc.retryTools == nil {
+			sc.retryTools = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			sc.retryTools[name] = struct{}{}
+		}
+	}
+}
+
 // WithHTTPTimeout sets the timeout for a HTTP request and stream.
+// The continuous listening connection is not subject to this timeout.
 func WithHTTPTimeout(timeout time.Duration) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
-		sc.httpClient.Timeout = timeout
+		// copy the client so that a client passed to WithHTTPBasicClient is not modified
+		client := *sc.httpClient
+		client.Timeout = timeout
+		sc.httpClient = &client
 	}
 }
 
@@ -68,6 +120,21 @@ func WithHTTPOAuth(config OAuthConfig) StreamableHTTPCOption {
 	}
 }
 
+// WithTokenProvider sets a function that supplies the bearer token for each request.
+//
+// The provider is invoked before every HTTP request and its result is sent as
+// "Authorization: Bearer <token>". If the server answers with 401 Unauthorized,
+// the provider is invoked once more to force a refresh and the request is retried
+// a single time before the error is surfaced. Concurrent invocations are collapsed
+// into a single call, so the provider is not stampeded by parallel requests.
+//
+// The token provider takes precedence over the Authorization header set by WithHTTPOAuth.
+func WithTokenProvider(provider func(ctx context.Context) (string, error)) StreamableHTTPCOption {
+	return func(sc *StreamableHTTP) {
+		sc.tokenProvider = provider
+	}
+}
+
 func WithLogger(logger util.Logger) StreamableHTTPCOption {
 	return func(sc *StreamableHTTP) {
 		sc.logger = logger
@@ -83,17 +150,21 @@ func WithLogger(logger util.Logger) StreamableHTTPCOption {
 // https://modelcontextprotocol.io/specification/2025-03-26/basic/transports
 //
 // The current implementation does not support the following features:
-//   - batching
 //   - resuming stream
 //     (https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#resumability-and-redelivery)
-//   - server -> client request
 type StreamableHTTP struct {
 	serverURL           *url.URL
 	httpClient          *http.Client
+	streamClient        *http.Client // httpClient without Timeout, for the listening connection
 	headers             map[string]string
 	headerFunc          HTTPHeaderFunc
 	logger              util.Logger
 	getListeningEnabled bool
+	requestCompression  bool
+
+	retryAttempts int
+	retryBackoff  BackoffFunc
+	retryTools    map[string]struct{} // tools whose calls are retried
 
 	sessionID atomic.Value // string
 
@@ -102,11 +173,24 @@ type StreamableHTTP struct {
 
 	notificationHandler func(mcp.JSONRPCNotification)
 	notifyMu            sync.RWMutex
+	requestHandler      requestHandlerHolder
 
 	closed chan struct{}
 
 	// OAuth support
 	oauthHandler *OAuthHandler
+
+	// Bearer token support
+	tokenProvider func(ctx context.Context) (string, error)
+	tokenMu       sync.Mutex
+	tokenCall     *tokenCall // in-flight token provider call, if any
+}
+
+// tokenCall represents an in-flight call to the token provider shared by concurrent requests.
+type tokenCall struct {
+	done  chan struct{}
+	token string
+	err   error
 }
 
 // NewStreamableHTTP creates a new Streamable HTTP transport with the given server URL.
@@ -133,6 +217,12 @@ func NewStreamableHTTP(serverURL string, options ...StreamableHTTPCOption) (*Str
 		}
 	}
 
+	// The listening connection stays open indefinitely, so it must not be
+	// bound by the client's Timeout
+	streamClient := *smc.httpClient
+	streamClient.Timeout = 0
+	smc.streamClient = &streamClient
+
 	// If OAuth is configured, set the base URL for metadata discovery
 	if smc.oauthHandler != nil {
 		// Extract base URL from server URL for metadata discovery
@@ -234,7 +324,7 @@ func (c *StreamableHTTP) SendRequest(
 	ctx, cancel := c.contextAwareOfClientClose(ctx)
 	defer cancel()
 
-	resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(requestBody), "application/json, text/event-stream")
+	resp, err := c.sendRequestHTTP(ctx, request, requestBody)
 	if err != nil {
 		if errors.Is(err, errSessionTerminated) && request.Method == string(mcp.MethodInitialize) {
 			// If the request is initialize, should not return a SessionTerminated error
@@ -303,15 +393,251 @@ func (c *StreamableHTTP) SendRequest(
 	}
 }
 
+// SendBatch sends several JSON-RPC messages to the server in a single HTTP request
+// and waits for all of their responses.
+//
+// Elements whose ID is nil are sent as notifications and have no response. The
+// responses are demultiplexed by ID and returned in the order of the requests
+// they answer, regardless of the order the server sent them in.
+// The initialize request must not be part of a batch; use SendRequest instead.
+func (c *StreamableHTTP) SendBatch(
+	ctx context.Context,
+	requests []JSONRPCRequest,
+) ([]JSONRPCResponse, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("batch must contain at least one request")
+	}
+
+	// Marshal the batch, keeping track of the IDs we expect a response for
+	batch := make([]any, 0, len(requests))
+	var expected []string
+	for _, request := range requests {
+		if request.ID.IsNil() {
+			// Notifications are sent without an id
+			batch = append(batch, struct {
+				JSONRPC string `json:"jsonrpc"`
+				Method  string `json:"method"`
+				Params  any    `json:"params,omitempty"`
+			}{request.JSONRPC, request.Method, request.Params})
+			continue
+		}
+		batch = append(batch, request)
+		expected = append(expected, request.ID.String())
+	}
+	requestBody, err := json.Marshal(batch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch: %w", err)
+	}
+
+	ctx, cancel := c.contextAwareOfClientClose(ctx)
+	defer cancel()
+
+	resp, err := c.sendHTTP(ctx, http.MethodPost, requestBody, "application/json, text/event-stream")
+	if err != nil {
+		return nil, fmt.Errorf("failed to send batch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		if resp.StatusCode == http.StatusUnauthorized && c.oauthHandler != nil {
+			return nil, &OAuthAuthorizationRequiredError{
+				Handler: c.oauthHandler,
+			}
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("batch failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	if len(expected) == 0 {
+		// Only notifications, no response expected
+		return []JSONRPCResponse{}, nil
+	}
+
+	received := make(map[string]JSONRPCResponse, len(expected))
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		responses, err := decodeBatchResponse(body)
+		if err != nil {
+			return nil, err
+		}
+		for _, response := range responses {
+			if !response.ID.IsNil() {
+				received[response.ID.String()] = response
+			}
+		}
+
+	case "text/event-stream":
+		if err := c.handleSSEBatchResponse(ctx, resp.Body, len(expected), received); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unexpected content type: %s", resp.Header.Get("Content-Type"))
+	}
+
+	// Return the responses in request order
+	responses := make([]JSONRPCResponse, 0, len(expected))
+	var missing []string
+	for _, id := range expected {
+		response, ok := received[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		responses = append(responses, response)
+	}
+	if len(missing) > 0 {
+		return responses, fmt.Errorf("missing responses for requests: %s", strings.Join(missing, ", "))
+	}
+	return responses, nil
+}
+
+// decodeBatchResponse decodes a batch response body, which may also be a single response.
+func decodeBatchResponse(body []byte) ([]JSONRPCResponse, error) {
+	var responses []JSONRPCResponse
+	if err := json.Unmarshal(body, &responses); err == nil {
+		return responses, nil
+	}
+	var response JSONRPCResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return []JSONRPCResponse{response}, nil
+}
+
+// handleSSEBatchResponse reads responses from an SSE stream until count responses
+// have been received, the stream ends, or the context is done.
+// Notifications received on the stream are dispatched to the notification handler.
+func (c *StreamableHTTP) handleSSEBatchResponse(
+	ctx context.Context,
+	reader io.ReadCloser,
+	count int,
+	received map[string]JSONRPCResponse,
+) error {
+	responseChan := make(chan JSONRPCResponse, count)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		defer close(responseChan)
+
+		c.readSSE(ctx, reader, func(event, data string) {
+			messages := []json.RawMessage{json.RawMessage(data)}
+			if strings.HasPrefix(strings.TrimSpace(data), "[") {
+				if err := json.Unmarshal([]byte(data), &messages); err != nil {
+					c.logger.Errorf("failed to unmarshal batch message: %v", err)
+					return
+				}
+			}
+
+			for _, raw := range messages {
+				if request, ok := parseIncomingRequest(raw); ok {
+					go c.handleServerRequest(request)
+					continue
+				}
+
+				var message JSONRPCResponse
+				if err := json.Unmarshal(raw, &message); err != nil {
+					c.logger.Errorf("failed to unmarshal message: %v", err)
+					continue
+				}
+
+				if message.ID.IsNil() {
+					var notification mcp.JSONRPCNotification
+					if err := json.Unmarshal(raw, &notification); err != nil {
+						c.logger.Errorf("failed to unmarshal notification: %v", err)
+						continue
+					}
+					c.notifyMu.RLock()
+					if c.notificationHandler != nil {
+						c.notificationHandler(notification)
+					}
+					c.notifyMu.RUnlock()
+					continue
+				}
+
+				select {
+				case responseChan <- message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}()
+
+	for len(received) < count {
+		select {
+		case response, ok := <-responseChan:
+			if !ok {
+				// stream ended, missing responses are reported by the caller
+				return nil
+			}
+			received[response.ID.String()] = response
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 func (c *StreamableHTTP) sendHTTP(
 	ctx context.Context,
 	method string,
-	body io.Reader,
+	body []byte,
 	acceptType string,
 ) (resp *http.Response, err error) {
 
+	resp, err = c.doHTTP(ctx, method, body, acceptType, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Force a token refresh and retry once if the server rejected the token
+	if resp.StatusCode == http.StatusUnauthorized && c.tokenProvider != nil {
+		resp.Body.Close()
+		rejected := strings.TrimPrefix(resp.Request.Header.Get("Authorization"), "Bearer ")
+		resp, err = c.doHTTP(ctx, method, body, acceptType, rejected)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// doHTTP sends a single HTTP request. rejectedToken is the bearer token the
+// server rejected for a previous attempt of the request, if any.
+func (c *StreamableHTTP) doHTTP(
+	ctx context.Context,
+	method string,
+	body []byte,
+	acceptType string,
+	rejectedToken string,
+) (resp *http.Response, err error) {
+
+	compressed := false
+	if c.requestCompression && len(body) >= requestCompressionThreshold {
+		gzipped, err := gzipBody(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress request: %w", err)
+		}
+		body = gzipped
+		compressed = true
+	}
+
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, method, c.serverURL.String(), body)
+	req, err := http.NewRequestWithContext(ctx, method, c.serverURL.String(), bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -319,6 +645,10 @@ func (c *StreamableHTTP) sendHTTP(
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", acceptType)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	sessionID := c.sessionID.Load().(string)
 	if sessionID != "" {
 		req.Header.Set(headerKeySessionID, sessionID)
@@ -342,6 +672,15 @@ func (c *StreamableHTTP) sendHTTP(
 		req.Header.Set("Authorization", authHeader)
 	}
 
+	// Add bearer token from the token provider if configured
+	if c.tokenProvider != nil {
+		token, err := c.fetchToken(ctx, rejectedToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
 	if c.headerFunc != nil {
 		for k, v := range c.headerFunc(ctx) {
 			req.Header.Set(k, v)
@@ -349,11 +688,21 @@ func (c *StreamableHTTP) sendHTTP(
 	}
 
 	// Send request
-	resp, err = c.httpClient.Do(req)
+	client := c.httpClient
+	if method == http.MethodGet {
+		// GET is only used for the long-lived listening connection
+		client = c.streamClient
+	}
+	resp, err = client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
+	if err := decompressResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	// universal handling for session terminated
 	if resp.StatusCode == http.StatusNotFound {
 		c.sessionID.CompareAndSwap(sessionID, "")
@@ -363,6 +712,61 @@ func (c *StreamableHTTP) sendHTTP(
 	return resp, nil
 }
 
+// tokenFetchTimeout bounds a call to the token provider, which is shared by
+// concurrent callers and so does not end with the context of any of them.
+const tokenFetchTimeout = 30 * time.Second
+
+// fetchToken calls the token provider. Concurrent callers share a single
+// in-flight call; each of them only gives up when its own ctx is done. A
+// joined call may have started before the server rejected the token of the
+// caller, so when it returns that rejected token, the provider is called again.
+func (c *StreamableHTTP) fetchToken(ctx context.Context, rejected string) (string, error) {
+	call, joined := c.tokenProviderCall(ctx)
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if !joined || call.err != nil || rejected == "" || call.token != rejected {
+		return call.token, call.err
+	}
+
+	// Calls started from now on began after the token was rejected
+	call, _ = c.tokenProviderCall(ctx)
+	select {
+	case <-call.done:
+		return call.token, call.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// tokenProviderCall returns the in-flight call to the token provider, starting
+// one if there is none, and reports whether the call was already in flight.
+func (c *StreamableHTTP) tokenProviderCall(ctx context.Context) (*tokenCall, bool) {
+	c.tokenMu.Lock()
+	call := c.tokenCall
+	joined := call != nil
+	if !joined {
+		call = &tokenCall{done: make(chan struct{})}
+		c.tokenCall = call
+
+		// The first caller giving up must not fail the call for the others
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tokenFetchTimeout)
+		go func() {
+			defer cancel()
+			call.token, call.err = c.tokenProvider(fetchCtx)
+
+			c.tokenMu.Lock()
+			c.tokenCall = nil
+			c.tokenMu.Unlock()
+			close(call.done)
+		}()
+	}
+	c.tokenMu.Unlock()
+	return call, joined
+}
+
 // handleSSEResponse processes an SSE stream for a specific request.
 // It returns the final result for the request once received, or an error.
 // If ignoreResponse is true, it won't return when a response messge is received. This is for continuous listening.
@@ -383,6 +787,12 @@ func (c *StreamableHTTP) handleSSEResponse(ctx context.Context, reader io.ReadCl
 
 			// (unsupported: batching)
 
+			// Handle request from the server
+			if request, ok := parseIncomingRequest([]byte(data)); ok {
+				go c.handleServerRequest(request)
+				return
+			}
+
 			var message JSONRPCResponse
 			if err := json.Unmarshal([]byte(data), &message); err != nil {
 				c.logger.Errorf("failed to unmarshal message: %v", err)
@@ -494,7 +904,7 @@ func (c *StreamableHTTP) SendNotification(ctx context.Context, notification mcp.
 	ctx, cancel := c.contextAwareOfClientClose(ctx)
 	defer cancel()
 
-	resp, err := c.sendHTTP(ctx, http.MethodPost, bytes.NewReader(requestBody), "application/json, text/event-stream")
+	resp, err := c.sendHTTP(ctx, http.MethodPost, requestBody, "application/json, text/event-stream")
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -525,6 +935,39 @@ func (c *StreamableHTTP) SetNotificationHandler(handler func(mcp.JSONRPCNotifica
 	c.notificationHandler = handler
 }
 
+// SetRequestHandler sets the handler called for requests sent by the server.
+func (c *StreamableHTTP) SetRequestHandler(handler RequestHandler) {
+	c.requestHandler.set(handler)
+}
+
+var _ BidirectionalInterface = (*StreamableHTTP)(nil)
+
+// handleServerRequest runs the request handler and posts the response to the server.
+func (c *StreamableHTTP) handleServerRequest(request JSONRPCRequest) {
+	ctx, cancel := c.contextAwareOfClientClose(context.Background())
+	defer cancel()
+
+	response := c.requestHandler.handle(ctx, request)
+
+	responseBody, err := json.Marshal(response)
+	if err != nil {
+		c.logger.Errorf("failed to marshal response: %v", err)
+		return
+	}
+
+	resp, err := c.sendHTTP(ctx, http.MethodPost, responseBody, "application/json, text/event-stream")
+	if err != nil {
+		c.logger.Errorf("failed to send response: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		c.logger.Errorf("response failed with status %d: %s", resp.StatusCode, body)
+	}
+}
+
 func (c *StreamableHTTP) GetSessionId() string {
 	return c.sessionID.Load().(string)
 }
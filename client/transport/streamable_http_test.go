@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -769,6 +771,131 @@ func TestContinuousListeningMethodNotAllowed(t *testing.T) {
 	}
 }
 
+// countingRoundTripper counts the requests per HTTP method before delegating to http.DefaultTransport.
+type countingRoundTripper struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (rt *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.counts[req.Method]++
+	rt.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (rt *countingRoundTripper) count(method string) int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.counts[method]
+}
+
+func TestStreamableHTTP_CustomHTTPClient(t *testing.T) {
+	retryInterval = 10 * time.Millisecond
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			var request map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				http.Error(w, "Invalid JSON", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Mcp-Session-Id", "test-session")
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"jsonrpc": "2.0",
+				"id":      request["id"],
+				"result":  "initialized",
+			})
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+
+			// Stay idle for longer than the client's Timeout before sending
+			select {
+			case <-time.After(300 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n",
+				`{"jsonrpc":"2.0","method":"test/notification","params":{}}`)
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	rt := &countingRoundTripper{counts: make(map[string]int)}
+	httpClient := &http.Client{Transport: rt, Timeout: 100 * time.Millisecond}
+
+	trans, err := NewStreamableHTTP(server.URL, WithHTTPBasicClient(httpClient), WithContinuousListening())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trans.Close()
+
+	notificationReceived := make(chan struct{}, 1)
+	trans.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		select {
+		case notificationReceived <- struct{}{}:
+		default:
+		}
+	})
+
+	if err := trans.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = trans.SendRequest(ctx, JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(0)),
+		Method:  "initialize",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-notificationReceived:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for notification on the listening connection")
+	}
+
+	if got := rt.count(http.MethodPost); got != 1 {
+		t.Errorf("Expected the custom client to send 1 POST request, got %d", got)
+	}
+	if got := rt.count(http.MethodGet); got != 1 {
+		t.Errorf("Expected a single listening connection that outlives the client Timeout, got %d GET requests", got)
+	}
+	if httpClient.Timeout != 100*time.Millisecond {
+		t.Errorf("Expected the provided client to be left unmodified, got Timeout %v", httpClient.Timeout)
+	}
+}
+
+func TestStreamableHTTP_HTTPTimeoutDoesNotModifyClient(t *testing.T) {
+	httpClient := &http.Client{}
+	trans, err := NewStreamableHTTP("http://localhost", WithHTTPBasicClient(httpClient), WithHTTPTimeout(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if httpClient.Timeout != 0 {
+		t.Errorf("Expected the provided client to be left unmodified, got Timeout %v", httpClient.Timeout)
+	}
+	if trans.httpClient.Timeout != time.Second {
+		t.Errorf("Expected request timeout of 1s, got %v", trans.httpClient.Timeout)
+	}
+	if trans.streamClient.Timeout != 0 {
+		t.Errorf("Expected no timeout on the listening connection, got %v", trans.streamClient.Timeout)
+	}
+}
+
 // testLogger is a simple logger for testing
 type testLogger struct {
 	logChan chan string
@@ -781,3 +908,483 @@ func (l *testLogger) Infof(format string, args ...any) {
 func (l *testLogger) Errorf(format string, args ...any) {
 	l.logChan <- fmt.Sprintf(format, args...)
 }
+
+// ---- token provider tests ----
+
+func TestStreamableHTTP_TokenProvider(t *testing.T) {
+	t.Run("SetsAuthorizationHeader", func(t *testing.T) {
+		var authHeader string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":"ok"}`)
+		}))
+		defer server.Close()
+
+		trans, err := NewStreamableHTTP(server.URL, WithTokenProvider(func(ctx context.Context) (string, error) {
+			return "fresh-token", nil
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		_, err = trans.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  "test",
+		})
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+		if authHeader != "Bearer fresh-token" {
+			t.Errorf("Expected Authorization header 'Bearer fresh-token', got '%s'", authHeader)
+		}
+	})
+
+	t.Run("RefreshesAndRetriesOnUnauthorized", func(t *testing.T) {
+		var mu sync.Mutex
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			var request map[string]any
+			if err := json.Unmarshal(body, &request); err != nil {
+				http.Error(w, "request body was not replayed", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":%q}`, request["method"])
+		}))
+		defer server.Close()
+
+		trans, err := NewStreamableHTTP(server.URL, WithTokenProvider(func(ctx context.Context) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls++
+			return fmt.Sprintf("token-%d", calls), nil
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		response, err := trans.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  "test",
+		})
+		if err != nil {
+			t.Fatalf("SendRequest failed: %v", err)
+		}
+
+		var result string
+		if err := json.Unmarshal(response.Result, &result); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		if result != "test" {
+			t.Errorf("Expected result 'test', got '%s'", result)
+		}
+		if calls != 2 {
+			t.Errorf("Expected token provider to be called 2 times, got %d", calls)
+		}
+	})
+
+	t.Run("SurfacesErrorAfterSingleRetry", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		trans, err := NewStreamableHTTP(server.URL, WithTokenProvider(func(ctx context.Context) (string, error) {
+			return "expired", nil
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		_, err = trans.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  "test",
+		})
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "401") {
+			t.Errorf("Expected error to mention status 401, got: %v", err)
+		}
+		if got := requests.Load(); got != 2 {
+			t.Errorf("Expected 2 HTTP requests, got %d", got)
+		}
+	})
+
+	t.Run("ProviderError", func(t *testing.T) {
+		trans, err := NewStreamableHTTP("http://localhost:1", WithTokenProvider(func(ctx context.Context) (string, error) {
+			return "", errors.New("provider unavailable")
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		_, err = trans.SendRequest(context.Background(), JSONRPCRequest{
+			JSONRPC: "2.0",
+			ID:      mcp.NewRequestId(int64(1)),
+			Method:  "test",
+		})
+		if err == nil || !strings.Contains(err.Error(), "provider unavailable") {
+			t.Errorf("Expected provider error, got: %v", err)
+		}
+	})
+
+	t.Run("ConcurrentCallsShareRefresh", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls atomic.Int32
+
+		trans, err := NewStreamableHTTP("http://example.com", WithTokenProvider(func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			<-release
+			return "shared-token", nil
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		const workers = 10
+		var wg sync.WaitGroup
+		tokens := make(chan string, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := trans.fetchToken(context.Background(), "")
+				if err != nil {
+					t.Errorf("fetchToken failed: %v", err)
+				}
+				tokens <- token
+			}()
+		}
+
+		// Wait until the first call is in flight before releasing it
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(tokens)
+
+		for token := range tokens {
+			if token != "shared-token" {
+				t.Errorf("Expected 'shared-token', got '%s'", token)
+			}
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("Expected token provider to be called once, got %d", got)
+		}
+	})
+
+	t.Run("RetryDoesNotReuseRejectedToken", func(t *testing.T) {
+		release := make(chan struct{})
+		var calls atomic.Int32
+		trans, err := NewStreamableHTTP("http://example.com", WithTokenProvider(func(ctx context.Context) (string, error) {
+			n := calls.Add(1)
+			if n == 1 {
+				<-release
+			}
+			return fmt.Sprintf("token-%d", n), nil
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		// A call started before the rejection is still in flight when the retry joins it
+		first := make(chan string, 1)
+		go func() {
+			token, _ := trans.fetchToken(context.Background(), "")
+			first <- token
+		}()
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		retried := make(chan string, 1)
+		go func() {
+			token, err := trans.fetchToken(context.Background(), "token-1")
+			if err != nil {
+				t.Errorf("fetchToken failed: %v", err)
+			}
+			retried <- token
+		}()
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+
+		if token := <-first; token != "token-1" {
+			t.Errorf("Expected 'token-1' for the first caller, got '%s'", token)
+		}
+		if token := <-retried; token != "token-2" {
+			t.Errorf("Expected the retry to fetch 'token-2', got '%s'", token)
+		}
+	})
+
+	t.Run("CancelledCallerDoesNotFailOthers", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		var startOnce sync.Once
+		trans, err := NewStreamableHTTP("http://example.com", WithTokenProvider(func(ctx context.Context) (string, error) {
+			startOnce.Do(func() { close(started) })
+			select {
+			case <-release:
+				return "shared-token", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}))
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := trans.fetchToken(firstCtx, "")
+			firstErr <- err
+		}()
+		<-started
+
+		secondToken := make(chan string, 1)
+		go func() {
+			token, err := trans.fetchToken(context.Background(), "")
+			if err != nil {
+				t.Errorf("fetchToken failed: %v", err)
+			}
+			secondToken <- token
+		}()
+
+		cancelFirst()
+		if err := <-firstErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the first caller to be cancelled, got %v", err)
+		}
+		close(release)
+		if token := <-secondToken; token != "shared-token" {
+			t.Errorf("Expected 'shared-token', got '%s'", token)
+		}
+	})
+}
+
+func TestStreamableHTTP_SendBatch(t *testing.T) {
+	// batchServer answers every request in the batch in reverse order, and records notifications
+	newBatchServer := func(stream bool, notifications *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var batch []map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+				http.Error(w, "expected a batch", http.StatusBadRequest)
+				return
+			}
+
+			var responses []string
+			for i := len(batch) - 1; i >= 0; i-- {
+				id, ok := batch[i]["id"]
+				if !ok {
+					notifications.Add(1)
+					continue
+				}
+				idJSON, _ := json.Marshal(id)
+				responses = append(responses, fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%q}`, idJSON, batch[i]["method"]))
+			}
+			if len(responses) == 0 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+
+			if !stream {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, "[%s]", strings.Join(responses, ","))
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			for _, response := range responses {
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", response)
+			}
+		}))
+	}
+
+	requests := []JSONRPCRequest{
+		{JSONRPC: "2.0", ID: mcp.NewRequestId(int64(1)), Method: "first"},
+		{JSONRPC: "2.0", Method: "notifications/initialized"},
+		{JSONRPC: "2.0", ID: mcp.NewRequestId(int64(2)), Method: "second"},
+		{JSONRPC: "2.0", ID: mcp.NewRequestId("three"), Method: "third"},
+	}
+
+	checkResponses := func(t *testing.T, responses []JSONRPCResponse) {
+		t.Helper()
+		expected := []string{"first", "second", "third"}
+		if len(responses) != len(expected) {
+			t.Fatalf("Expected %d responses, got %d", len(expected), len(responses))
+		}
+		for i, response := range responses {
+			var result string
+			if err := json.Unmarshal(response.Result, &result); err != nil {
+				t.Fatalf("Failed to unmarshal result: %v", err)
+			}
+			if result != expected[i] {
+				t.Errorf("Expected response %d to be %q, got %q", i, expected[i], result)
+			}
+		}
+	}
+
+	for _, stream := range []bool{false, true} {
+		name := "JSON"
+		if stream {
+			name = "SSE"
+		}
+		t.Run(name, func(t *testing.T) {
+			var notifications atomic.Int32
+			server := newBatchServer(stream, &notifications)
+			defer server.Close()
+
+			trans, err := NewStreamableHTTP(server.URL)
+			if err != nil {
+				t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+			}
+
+			var streamNotifications atomic.Int32
+			trans.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+				streamNotifications.Add(1)
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			responses, err := trans.SendBatch(ctx, requests)
+			if err != nil {
+				t.Fatalf("SendBatch failed: %v", err)
+			}
+			checkResponses(t, responses)
+
+			if got := notifications.Load(); got != 1 {
+				t.Errorf("Expected server to receive 1 notification, got %d", got)
+			}
+			if stream {
+				if got := streamNotifications.Load(); got != 1 {
+					t.Errorf("Expected 1 notification from the stream, got %d", got)
+				}
+			}
+		})
+	}
+
+	t.Run("OnlyNotifications", func(t *testing.T) {
+		var notifications atomic.Int32
+		server := newBatchServer(false, &notifications)
+		defer server.Close()
+
+		trans, err := NewStreamableHTTP(server.URL)
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		responses, err := trans.SendBatch(context.Background(), []JSONRPCRequest{
+			{JSONRPC: "2.0", Method: "notifications/one"},
+			{JSONRPC: "2.0", Method: "notifications/two"},
+		})
+		if err != nil {
+			t.Fatalf("SendBatch failed: %v", err)
+		}
+		if len(responses) != 0 {
+			t.Errorf("Expected no responses, got %d", len(responses))
+		}
+		if got := notifications.Load(); got != 2 {
+			t.Errorf("Expected server to receive 2 notifications, got %d", got)
+		}
+	})
+
+	t.Run("MissingResponse", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"jsonrpc":"2.0","id":1,"result":"first"}]`)
+		}))
+		defer server.Close()
+
+		trans, err := NewStreamableHTTP(server.URL)
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+
+		_, err = trans.SendBatch(context.Background(), []JSONRPCRequest{
+			{JSONRPC: "2.0", ID: mcp.NewRequestId(int64(1)), Method: "first"},
+			{JSONRPC: "2.0", ID: mcp.NewRequestId(int64(2)), Method: "second"},
+		})
+		if err == nil {
+			t.Fatal("Expected error for missing response, got nil")
+		}
+		if !strings.Contains(err.Error(), "int64:2") {
+			t.Errorf("Expected error to mention the missing request, got: %v", err)
+		}
+	})
+
+	t.Run("EmptyBatch", func(t *testing.T) {
+		trans, err := NewStreamableHTTP("http://localhost")
+		if err != nil {
+			t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+		}
+		if _, err := trans.SendBatch(context.Background(), nil); err == nil {
+			t.Error("Expected error for empty batch, got nil")
+		}
+	})
+}
+
+func TestStreamableHTTP_ServerRequest(t *testing.T) {
+	responses := make(chan map[string]any, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+
+		// a response to the server request
+		if _, ok := message["method"]; !ok {
+			w.WriteHeader(http.StatusAccepted)
+			responses <- message
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":\"srv-1\",\"method\":\"roots/list\"}\n\n")
+		fmt.Fprintf(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":%v,\"result\":\"ok\"}\n\n", message["id"])
+	}))
+	defer server.Close()
+
+	trans, err := NewStreamableHTTP(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create StreamableHTTP transport: %v", err)
+	}
+	trans.SetRequestHandler(func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		return NewJSONRPCResultResponse(request.ID, json.RawMessage(`{"roots":[]}`)), nil
+	})
+
+	_, err = trans.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      mcp.NewRequestId(int64(1)),
+		Method:  "tools/call",
+	})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+
+	select {
+	case response := <-responses:
+		if response["id"] != "srv-1" {
+			t.Errorf("Expected response id srv-1, got %v", response["id"])
+		}
+		if _, ok := response["result"]; !ok {
+			t.Errorf("Expected result in response, got %v", response)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for response to server request")
+	}
+}
@@ -0,0 +1,140 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// redactedValue replaces the values of redacted fields in logged messages.
+const redactedValue = "[REDACTED]"
+
+// WithWireLogger wraps a transport, such as Stdio, SSE or StreamableHTTP, so
+// that every JSON-RPC message it sends or receives is logged to logger at
+// debug level. Each record has the direction ("outgoing" or "incoming"), the
+// message type, its method and request ID when it has them, and the message
+// as JSON.
+//
+// The values of the JSON fields named in redactFields are masked wherever they
+// appear in a message, for example to hide secrets in tool arguments.
+//
+// If logger is nil, t is returned unchanged. Messages are only encoded when
+// the logger is enabled for debug level.
+func WithWireLogger(t Interface, logger *slog.Logger, redactFields []string) Interface {
+	if logger == nil {
+		return t
+	}
+	w := &wireLogger{
+		Interface: t,
+		logger:    logger,
+		redact:    make(map[string]struct{}, len(redactFields)),
+	}
+	for _, field := range redactFields {
+		w.redact[field] = struct{}{}
+	}
+	return w
+}
+
+// wireLogger is a transport that logs the messages of the transport it wraps.
+type wireLogger struct {
+	Interface
+	logger *slog.Logger
+	redact map[string]struct{}
+}
+
+var _ BidirectionalInterface = (*wireLogger)(nil)
+
+func (w *wireLogger) SendRequest(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+	w.log(ctx, "outgoing", "request", request.Method, &request.ID, request)
+	response, err := w.Interface.SendRequest(ctx, request)
+	if response != nil {
+		w.log(ctx, "incoming", "response", request.Method, &response.ID, response)
+	}
+	return response, err
+}
+
+func (w *wireLogger) SendNotification(ctx context.Context, notification mcp.JSONRPCNotification) error {
+	w.log(ctx, "outgoing", "notification", notification.Method, nil, notification)
+	return w.Interface.SendNotification(ctx, notification)
+}
+
+func (w *wireLogger) SetNotificationHandler(handler func(notification mcp.JSONRPCNotification)) {
+	w.Interface.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		w.log(context.Background(), "incoming", "notification", notification.Method, nil, notification)
+		handler(notification)
+	})
+}
+
+// SetRequestHandler implements BidirectionalInterface. It does nothing if the
+// wrapped transport cannot receive requests from the server.
+func (w *wireLogger) SetRequestHandler(handler RequestHandler) {
+	bidirectional, ok := w.Interface.(BidirectionalInterface)
+	if !ok {
+		return
+	}
+	bidirectional.SetRequestHandler(func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		w.log(ctx, "incoming", "request", request.Method, &request.ID, request)
+		response, err := handler(ctx, request)
+		if response != nil {
+			w.log(ctx, "outgoing", "response", request.Method, &response.ID, response)
+		}
+		return response, err
+	})
+}
+
+// log logs a message with its redacted fields masked.
+func (w *wireLogger) log(ctx context.Context, direction, kind, method string, id *mcp.RequestId, message any) {
+	if !w.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("direction", direction),
+		slog.String("type", kind),
+		slog.String("method", method),
+	}
+	if id != nil && !id.IsNil() {
+		attrs = append(attrs, slog.Any("id", id.Value()))
+	}
+	data, err := w.encode(message)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	} else {
+		attrs = append(attrs, slog.String("message", string(data)))
+	}
+	w.logger.LogAttrs(ctx, slog.LevelDebug, "mcp message", attrs...)
+}
+
+// encode marshals a message to JSON with its redacted fields masked.
+func (w *wireLogger) encode(message any) ([]byte, error) {
+	data, err := json.Marshal(message)
+	if err != nil || len(w.redact) == 0 {
+		return data, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(w.mask(value))
+}
+
+// mask replaces the values of redacted fields in a decoded JSON value.
+func (w *wireLogger) mask(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if _, ok := w.redact[key]; ok {
+				v[key] = redactedValue
+			} else {
+				v[key] = w.mask(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = w.mask(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestWireLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	mock := NewMockTransport()
+	if err := mock.RespondWith("tools/call", map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": "ok"}},
+		"token":   "secret-result",
+	}); err != nil {
+		t.Fatalf("RespondWith failed: %v", err)
+	}
+	trans := WithWireLogger(mock, logger, []string{"apiKey", "token"})
+
+	var notified []string
+	trans.SetNotificationHandler(func(notification mcp.JSONRPCNotification) {
+		notified = append(notified, notification.Method)
+	})
+	trans.(BidirectionalInterface).SetRequestHandler(func(ctx context.Context, request JSONRPCRequest) (*JSONRPCResponse, error) {
+		return NewJSONRPCResultResponse(request.ID, json.RawMessage(`{"roots":[]}`)), nil
+	})
+
+	_, err := trans.SendRequest(context.Background(), JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      mcp.NewRequestId(int64(7)),
+		Method:  "tools/call",
+		Params: map[string]any{
+			"name":      "search",
+			"arguments": map[string]any{"query": "q", "nested": []any{map[string]any{"apiKey": "secret-key"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendRequest failed: %v", err)
+	}
+	mock.SimulateNotification(mcp.MethodNotificationToolsListChanged, nil)
+	mock.SimulateRequest(context.Background(), JSONRPCRequest{ID: mcp.NewRequestId("r1"), Method: "roots/list"})
+
+	if len(notified) != 1 {
+		t.Errorf("Expected the notification to reach the handler, got %v", notified)
+	}
+	if strings.Contains(buf.String(), "secret-") {
+		t.Errorf("Expected redacted fields to be masked, got %s", buf.String())
+	}
+
+	type record struct {
+		Level     string `json:"level"`
+		Direction string `json:"direction"`
+		Type      string `json:"type"`
+		Method    string `json:"method"`
+		ID        any    `json:"id"`
+		Message   string `json:"message"`
+	}
+	var records []record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Failed to parse log record %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+
+	expected := []record{
+		{Level: "DEBUG", Direction: "outgoing", Type: "request", Method: "tools/call", ID: float64(7)},
+		{Level: "DEBUG", Direction: "incoming", Type: "response", Method: "tools/call", ID: float64(7)},
+		{Level: "DEBUG", Direction: "incoming", Type: "notification", Method: mcp.MethodNotificationToolsListChanged},
+		{Level: "DEBUG", Direction: "incoming", Type: "request", Method: "roots/list", ID: "r1"},
+		{Level: "DEBUG", Direction: "outgoing", Type: "response", Method: "roots/list", ID: "r1"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d log records, got %d: %s", len(expected), len(records), buf.String())
+	}
+	for i, r := range records {
+		message := r.Message
+		r.Message = ""
+		if r != expected[i] {
+			t.Errorf("Record %d: expected %+v, got %+v", i, expected[i], r)
+		}
+		if !json.Valid([]byte(message)) {
+			t.Errorf("Record %d: expected the message as JSON, got %q", i, message)
+		}
+	}
+	if !strings.Contains(records[0].Message, `"apiKey":"[REDACTED]"`) {
+		t.Errorf("Expected nested apiKey to be redacted, got %s", records[0].Message)
+	}
+}
+
+func TestWireLoggerDisabled(t *testing.T) {
+	mock := NewMockTransport()
+	if trans := WithWireLogger(mock, nil, []string{"token"}); trans != Interface(mock) {
+		t.Error("Expected the transport to be returned unchanged without a logger")
+	}
+
+	// Messages are not encoded when debug level is disabled
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	trans := WithWireLogger(mock, logger, nil)
+	if err := trans.SendNotification(context.Background(), mcp.JSONRPCNotification{
+		JSONRPC:      mcp.JSONRPC_VERSION,
+		Notification: mcp.Notification{Method: mcp.MethodNotificationInitialized},
+	}); err != nil {
+		t.Fatalf("SendNotification failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log output, got %s", buf.String())
+	}
+	if len(mock.Notifications()) != 1 {
+		t.Error("Expected the notification to be sent")
+	}
+}
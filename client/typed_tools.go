@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// CallToolTyped invokes the named tool and decodes its result into T.
+//
+// The structured content of the result is preferred; if the tool did not return
+// any, the first text content is decoded as JSON instead. If the tool reports
+// IsError, the returned error wraps a *ToolError; use errors.As to get its code.
+func CallToolTyped[T any](ctx context.Context, c MCPClient, name string, args any) (T, error) {
+	var zero T
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = args
+
+	result, err := c.CallTool(ctx, request)
+	if err != nil {
+		return zero, err
+	}
+
+	return decodeToolResult[T](name, result)
+}
+
+// decodeToolResult decodes the structured content, or the first text content,
+// of a tool result into T.
+func decodeToolResult[T any](name string, result *mcp.CallToolResult) (T, error) {
+	var value T
+
+	if toolErr := AsToolError(result); toolErr != nil {
+		return value, fmt.Errorf("tool %q returned an error: %w", name, toolErr)
+	}
+
+	var data []byte
+	if result.StructuredContent != nil {
+		raw, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return value, fmt.Errorf("failed to marshal structured content of tool %q: %w", name, err)
+		}
+		data = raw
+	} else {
+		for _, content := range result.Content {
+			if text, ok := mcp.AsTextContent(content); ok {
+				data = []byte(text.Text)
+				break
+			}
+		}
+	}
+	if data == nil {
+		return value, fmt.Errorf("tool %q returned no structured or text content", name)
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("failed to decode result of tool %q: %w", name, err)
+	}
+	return value, nil
+}
+
+// toolResultText joins all text content of a tool result.
+func toolResultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+	"github.com/mathiasXie/mcp-go/server"
+)
+
+type weatherReport struct {
+	City        string  `json:"city"`
+	Temperature float64 `json:"temperature"`
+}
+
+func TestCallToolTyped(t *testing.T) {
+	mcpServer := server.NewMCPServer("test-server", "1.0.0", server.WithToolCapabilities(true))
+
+	mcpServer.AddTool(mcp.NewTool("structured"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		report := weatherReport{City: request.GetString("city", ""), Temperature: 21.5}
+		return mcp.NewToolResultStructured(report, "fallback text is not JSON"), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("text"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		data, _ := json.Marshal(weatherReport{City: "Paris", Temperature: 18})
+		return mcp.NewToolResultText(string(data)), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("failing"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("city not found"), nil
+	})
+	mcpServer.AddTool(mcp.NewTool("not-json"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("sunny"), nil
+	})
+
+	client, err := NewInProcessClient(mcpServer)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Start(ctx); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "test-client", Version: "1.0.0"}
+	if _, err := client.Initialize(ctx, initRequest); err != nil {
+		t.Fatalf("Failed to initialize: %v", err)
+	}
+
+	t.Run("StructuredContent", func(t *testing.T) {
+		report, err := CallToolTyped[weatherReport](ctx, client, "structured", map[string]any{"city": "Berlin"})
+		if err != nil {
+			t.Fatalf("CallToolTyped failed: %v", err)
+		}
+		if report.City != "Berlin" || report.Temperature != 21.5 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("TextContent", func(t *testing.T) {
+		report, err := CallToolTyped[weatherReport](ctx, client, "text", nil)
+		if err != nil {
+			t.Fatalf("CallToolTyped failed: %v", err)
+		}
+		if report.City != "Paris" || report.Temperature != 18 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+	})
+
+	t.Run("ToolError", func(t *testing.T) {
+		_, err := CallToolTyped[weatherReport](ctx, client, "failing", nil)
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+		if !strings.Contains(err.Error(), "city not found") {
+			t.Errorf("Expected error to contain tool error text, got: %v", err)
+		}
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		_, err := CallToolTyped[weatherReport](ctx, client, "not-json", nil)
+		if err == nil {
+			t.Fatal("Expected decode error, got nil")
+		}
+	})
+}
@@ -0,0 +1,23 @@
+// Package metadata passes the original values of request metadata from
+// in-process clients to the server they share the request context with.
+package metadata
+
+import "context"
+
+// valuesKey is the context key for the metadata values passed with a request
+type valuesKey struct{}
+
+// ContextWithValues returns a context carrying the original values of the
+// metadata of a request. The server uses them instead of the values decoded
+// from the _meta of the request handled with this context, for the keys that
+// are in that _meta.
+func ContextWithValues(ctx context.Context, values map[string]any) context.Context {
+	return context.WithValue(ctx, valuesKey{}, values)
+}
+
+// ValuesFromContext returns the metadata values passed with
+// ContextWithValues, or nil if there are none.
+func ValuesFromContext(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(valuesKey{}).(map[string]any)
+	return values
+}
@@ -1,5 +1,7 @@
 package mcp
 
+import "encoding/json"
+
 /* Prompts */
 
 // ListPromptsRequest is sent from the client to request a list of prompts and
@@ -50,6 +52,9 @@ type Prompt struct {
 	// A list of arguments to use for templating the prompt.
 	// The presence of arguments indicates this is a template prompt.
 	Arguments []PromptArgument `json:"arguments,omitempty"`
+	// Completion functions for the arguments, keyed by argument name.
+	// They answer completion/complete requests and are not sent to clients.
+	ArgumentCompleters map[string]ArgumentCompleterFunc `json:"-"`
 }
 
 // GetName returns the name of the prompt.
@@ -88,6 +93,21 @@ type PromptMessage struct {
 	Content Content `json:"content"` // Can be TextContent, ImageContent, AudioContent or EmbeddedResource
 }
 
+// UnmarshalJSON decodes a prompt message, including its content, which is
+// decoded into the concrete content type named by its "type" field.
+func (m *PromptMessage) UnmarshalJSON(data []byte) error {
+	var messageMap map[string]any
+	if err := json.Unmarshal(data, &messageMap); err != nil {
+		return err
+	}
+	message, err := ParsePromptMessage(messageMap)
+	if err != nil {
+		return err
+	}
+	*m = message
+	return nil
+}
+
 // PromptListChangedNotification is an optional notification from the server
 // to the client, informing it that the list of prompts it offers has changed. This
 // may be issued by servers without any previous subscription from the client.
@@ -149,6 +169,18 @@ func WithArgument(name string, opts ...ArgumentOption) PromptOption {
 	}
 }
 
+// WithArgumentCompleter registers a completion function for the named argument.
+// The server calls it to answer completion/complete requests for this prompt;
+// results are truncated to MaxCompletionValues.
+func WithArgumentCompleter(name string, completer ArgumentCompleterFunc) PromptOption {
+	return func(p *Prompt) {
+		if p.ArgumentCompleters == nil {
+			p.ArgumentCompleters = make(map[string]ArgumentCompleterFunc)
+		}
+		p.ArgumentCompleters[name] = completer
+	}
+}
+
 //
 // Argument Options
 //
@@ -86,6 +86,18 @@ func WithTemplateMIMEType(mimeType string) ResourceTemplateOption {
 	}
 }
 
+// WithTemplateArgumentCompleter registers a completion function for the named template variable.
+// The server calls it to answer completion/complete requests for this template;
+// results are truncated to MaxCompletionValues.
+func WithTemplateArgumentCompleter(name string, completer ArgumentCompleterFunc) ResourceTemplateOption {
+	return func(t *ResourceTemplate) {
+		if t.ArgumentCompleters == nil {
+			t.ArgumentCompleters = make(map[string]ArgumentCompleterFunc)
+		}
+		t.ArgumentCompleters[name] = completer
+	}
+}
+
 // WithTemplateAnnotations adds annotations to the ResourceTemplate.
 // Annotations can provide additional metadata about the template's intended use.
 func WithTemplateAnnotations(audience []Role, priority float64) ResourceTemplateOption {
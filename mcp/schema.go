@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SchemaBuilder builds the JSON Schema of an object, such as a tool's input
+// schema, from typed property declarations:
+//
+//	schema, err := mcp.NewSchema().
+//		String("path", mcp.Required(), mcp.Description("File to read")).
+//		Number("limit", mcp.Default(10)).
+//		Enum("mode", []string{"text", "binary"}).
+//		Build()
+//
+// Properties take the same PropertyOption values as WithString and friends.
+// Errors, such as a property declared twice or a required property that is
+// not declared, are reported by Build.
+type SchemaBuilder struct {
+	properties map[string]any
+	required   []string
+	errs       []error
+}
+
+// NewSchema creates an empty object schema builder.
+func NewSchema() *SchemaBuilder {
+	return &SchemaBuilder{properties: make(map[string]any)}
+}
+
+// String declares a string property.
+func (b *SchemaBuilder) String(name string, opts ...PropertyOption) *SchemaBuilder {
+	return b.property(name, map[string]any{"type": "string"}, opts)
+}
+
+// Number declares a number property.
+func (b *SchemaBuilder) Number(name string, opts ...PropertyOption) *SchemaBuilder {
+	return b.property(name, map[string]any{"type": "number"}, opts)
+}
+
+// Integer declares an integer property.
+func (b *SchemaBuilder) Integer(name string, opts ...PropertyOption) *SchemaBuilder {
+	return b.property(name, map[string]any{"type": "integer"}, opts)
+}
+
+// Boolean declares a boolean property.
+func (b *SchemaBuilder) Boolean(name string, opts ...PropertyOption) *SchemaBuilder {
+	return b.property(name, map[string]any{"type": "boolean"}, opts)
+}
+
+// Enum declares a string property restricted to the given values.
+func (b *SchemaBuilder) Enum(name string, values []string, opts ...PropertyOption) *SchemaBuilder {
+	if len(values) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("property %q: enum has no values", name))
+	}
+	return b.property(name, map[string]any{"type": "string", "enum": values}, opts)
+}
+
+// Object declares a nested object property with the properties of schema.
+func (b *SchemaBuilder) Object(name string, schema *SchemaBuilder, opts ...PropertyOption) *SchemaBuilder {
+	object, err := schema.build()
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("property %q: %w", name, err))
+	}
+	return b.property(name, object, opts)
+}
+
+// Array declares an array property whose items match the items schema. The
+// items schema is either a *SchemaBuilder for arrays of objects, or a schema
+// map such as map[string]any{"type": "string"}.
+func (b *SchemaBuilder) Array(name string, items any, opts ...PropertyOption) *SchemaBuilder {
+	if builder, ok := items.(*SchemaBuilder); ok {
+		object, err := builder.build()
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("property %q: items: %w", name, err))
+		}
+		items = object
+	}
+	if items == nil {
+		b.errs = append(b.errs, fmt.Errorf("property %q: array has no items schema", name))
+	}
+	return b.property(name, map[string]any{"type": "array", "items": items}, opts)
+}
+
+// Require marks already or later declared properties as required. It is an
+// alternative to passing Required() to the property.
+func (b *SchemaBuilder) Require(names ...string) *SchemaBuilder {
+	for _, name := range names {
+		b.require(name)
+	}
+	return b
+}
+
+// Build returns the input schema, or an error if a property is declared more
+// than once or a required property is not declared.
+func (b *SchemaBuilder) Build() (ToolInputSchema, error) {
+	if err := b.validate(); err != nil {
+		return ToolInputSchema{}, err
+	}
+	return ToolInputSchema{
+		Type:       "object",
+		Properties: b.properties,
+		Required:   b.required,
+	}, nil
+}
+
+// MustBuild is like Build but panics on error. It is meant for schemas
+// declared at package initialization, where an error is a programming error.
+func (b *SchemaBuilder) MustBuild() ToolInputSchema {
+	schema, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// build returns the schema as a map, for nesting in another schema.
+func (b *SchemaBuilder) build() (map[string]any, error) {
+	if b == nil {
+		return nil, errors.New("schema is nil")
+	}
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	object := map[string]any{
+		"type":       "object",
+		"properties": b.properties,
+	}
+	if len(b.required) > 0 {
+		object["required"] = b.required
+	}
+	return object, nil
+}
+
+func (b *SchemaBuilder) validate() error {
+	errs := b.errs
+	for _, name := range b.required {
+		if _, ok := b.properties[name]; !ok {
+			errs = append(errs, fmt.Errorf("required property %q is not declared", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *SchemaBuilder) property(name string, schema map[string]any, opts []PropertyOption) *SchemaBuilder {
+	for _, opt := range opts {
+		opt(schema)
+	}
+
+	// Remove required from property schema and add to the object's required
+	if required, ok := schema["required"].(bool); ok {
+		delete(schema, "required")
+		if required {
+			b.require(name)
+		}
+	}
+
+	if _, ok := b.properties[name]; ok {
+		b.errs = append(b.errs, fmt.Errorf("property %q is declared more than once", name))
+	}
+	b.properties[name] = schema
+	return b
+}
+
+func (b *SchemaBuilder) require(name string) {
+	for _, required := range b.required {
+		if required == name {
+			return
+		}
+	}
+	b.required = append(b.required, name)
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	schema, err := NewSchema().
+		String("path", Required(), Description("File to read")).
+		Number("limit", Default(10)).
+		Integer("depth", Min(0)).
+		Boolean("recursive").
+		Enum("mode", []string{"text", "binary"}, Required()).
+		Object("owner", NewSchema().
+			String("name", Required()).
+			String("email")).
+		Array("tags", map[string]any{"type": "string"}).
+		Array("ranges", NewSchema().Integer("start").Integer("end").Require("start", "end"), MinItems(1)).
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"path": {"type": "string", "description": "File to read"},
+			"limit": {"type": "number", "default": 10},
+			"depth": {"type": "integer", "minimum": 0},
+			"recursive": {"type": "boolean"},
+			"mode": {"type": "string", "enum": ["text", "binary"]},
+			"owner": {
+				"type": "object",
+				"properties": {"name": {"type": "string"}, "email": {"type": "string"}},
+				"required": ["name"]
+			},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"ranges": {
+				"type": "array",
+				"minItems": 1,
+				"items": {
+					"type": "object",
+					"properties": {"start": {"type": "integer"}, "end": {"type": "integer"}},
+					"required": ["start", "end"]
+				}
+			}
+		},
+		"required": ["path", "mode"]
+	}`, string(data))
+
+	// The built schema validates arguments
+	assert.NoError(t, ValidateSchema(schema, map[string]any{"path": "a.txt", "mode": "text"}))
+	assert.Error(t, ValidateSchema(schema, map[string]any{"path": "a.txt", "mode": "other"}))
+	assert.Error(t, ValidateSchema(schema, map[string]any{"mode": "text"}))
+}
+
+func TestSchemaBuilder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *SchemaBuilder
+		message string
+	}{
+		{
+			name:    "undeclared required property",
+			builder: NewSchema().String("path").Require("path", "limit"),
+			message: `required property "limit" is not declared`,
+		},
+		{
+			name:    "duplicate property",
+			builder: NewSchema().String("path").Number("path"),
+			message: `property "path" is declared more than once`,
+		},
+		{
+			name:    "nested undeclared required property",
+			builder: NewSchema().Object("owner", NewSchema().Require("name")),
+			message: `property "owner": required property "name" is not declared`,
+		},
+		{
+			name:    "empty enum",
+			builder: NewSchema().Enum("mode", nil),
+			message: `property "mode": enum has no values`,
+		},
+		{
+			name:    "array without items",
+			builder: NewSchema().Array("tags", nil),
+			message: `property "tags": array has no items schema`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+			assert.Panics(t, func() { tt.builder.MustBuild() })
+		})
+	}
+}
+
+func TestWithInputSchema(t *testing.T) {
+	tool := NewTool("read_file",
+		WithDescription("Read a file"),
+		WithInputSchema(NewSchema().String("path", Required()).MustBuild()),
+	)
+	assert.Equal(t, []string{"path"}, tool.InputSchema.Required)
+	assert.Contains(t, tool.InputSchema.Properties, "path")
+
+	// An empty schema still lists no properties as an empty object
+	tool = NewTool("noop", WithInputSchema(NewSchema().MustBuild()))
+	data, err := json.Marshal(tool)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"inputSchema":{"properties":{},"type":"object"}`)
+}
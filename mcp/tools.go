@@ -36,16 +36,49 @@ type ListToolsResult struct {
 type CallToolResult struct {
 	Result
 	Content []Content `json:"content"` // Can be TextContent, ImageContent, AudioContent, or EmbeddedResource
+	// Structured content returned as a JSON object in the structuredContent field of a result.
+	// For backwards compatibility, a tool that returns structured content SHOULD also
+	// return the serialized JSON in a TextContent block.
+	StructuredContent any `json:"structuredContent,omitempty"`
 	// Whether the tool call ended in an error.
 	//
 	// If not set, this is assumed to be false (the call was successful).
 	IsError bool `json:"isError,omitempty"`
 }
 
+// ToolErrorContent is the machine-readable description of a tool failure. Error
+// results created with NewToolResultErrorWithCode carry it in the "error" field
+// of their structured content, alongside the message as text content for
+// clients that only read text.
+type ToolErrorContent struct {
+	// Code identifies the kind of failure, e.g. "NOT_FOUND" or "RATE_LIMITED".
+	Code string `json:"code"`
+	// Message is a human-readable description of the failure.
+	Message string `json:"message"`
+	// Details holds additional information about the failure, if any.
+	Details any `json:"details,omitempty"`
+}
+
+// ToolResultChunk is an element of the stream returned by Client.CallToolStream.
+// Chunks carry the content emitted by the tool as it runs; the last chunk
+// carries either the final Result or the Err that ended the call.
+type ToolResultChunk struct {
+	// Content emitted by the tool.
+	Content []Content
+	// Result is the final result of the tool call, set on the last chunk.
+	Result *CallToolResult
+	// Err is the error that ended the tool call, set on the last chunk.
+	Err error
+}
+
 // CallToolRequest is used by the client to invoke a tool provided by the server.
 type CallToolRequest struct {
 	Request
 	Params CallToolParams `json:"params"`
+	// InputSchema is the input schema of the called tool. The server sets it
+	// before invoking the tool handler; BindArguments uses it to check that
+	// all required arguments are present.
+	InputSchema *ToolInputSchema `json:"-"`
 }
 
 type CallToolParams struct {
@@ -71,24 +104,49 @@ func (r CallToolRequest) GetRawArguments() any {
 
 // BindArguments unmarshals the Arguments into the provided struct
 // This is useful for working with strongly-typed arguments
+// If the request carries the tool's InputSchema, an error is returned for the
+// first required argument that is missing or null.
 func (r CallToolRequest) BindArguments(target any) error {
 	if target == nil || reflect.ValueOf(target).Kind() != reflect.Ptr {
 		return fmt.Errorf("target must be a non-nil pointer")
 	}
 
 	// Fast-path: already raw JSON
-	if raw, ok := r.Params.Arguments.(json.RawMessage); ok {
-		return json.Unmarshal(raw, target)
+	data, ok := r.Params.Arguments.(json.RawMessage)
+	if !ok {
+		var err error
+		data, err = json.Marshal(r.Params.Arguments)
+		if err != nil {
+			return fmt.Errorf("failed to marshal arguments: %w", err)
+		}
 	}
 
-	data, err := json.Marshal(r.Params.Arguments)
-	if err != nil {
-		return fmt.Errorf("failed to marshal arguments: %w", err)
+	if err := r.checkRequiredArguments(data); err != nil {
+		return err
 	}
 
 	return json.Unmarshal(data, target)
 }
 
+// checkRequiredArguments returns an error for the first argument that the
+// input schema requires but the JSON encoded arguments lack.
+func (r CallToolRequest) checkRequiredArguments(data json.RawMessage) error {
+	if r.InputSchema == nil || len(r.InputSchema.Required) == 0 {
+		return nil
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal(data, &args); err != nil {
+		return fmt.Errorf("arguments must be an object: %w", err)
+	}
+	for _, key := range r.InputSchema.Required {
+		if args[key] == nil {
+			return fmt.Errorf("required argument %q not found", key)
+		}
+	}
+	return nil
+}
+
 // GetString returns a string argument by key, or the default value if not found
 func (r CallToolRequest) GetString(key string, defaultValue string) string {
 	args := r.GetArguments()
@@ -478,8 +536,11 @@ type Tool struct {
 	InputSchema ToolInputSchema `json:"inputSchema"`
 	// Alternative to InputSchema - allows arbitrary JSON Schema to be provided
 	RawInputSchema json.RawMessage `json:"-"` // Hide this from JSON marshaling
-	// Optional properties describing tool behavior
-	Annotations ToolAnnotation `json:"annotations"`
+	// An optional JSON Schema object defining the structure of the tool's output
+	// returned in the structuredContent field of a CallToolResult.
+	OutputSchema *ToolOutputSchema `json:"outputSchema,omitempty"`
+	// Optional properties describing tool behavior, omitted from JSON when unset
+	Annotations ToolAnnotations `json:"annotations,omitempty"`
 }
 
 // GetName returns the name of the tool.
@@ -510,7 +571,13 @@ func (t Tool) MarshalJSON() ([]byte, error) {
 		m["inputSchema"] = t.InputSchema
 	}
 
-	m["annotations"] = t.Annotations
+	if t.OutputSchema != nil {
+		m["outputSchema"] = t.OutputSchema
+	}
+
+	if t.Annotations != (ToolAnnotations{}) {
+		m["annotations"] = t.Annotations
+	}
 
 	return json.Marshal(m)
 }
@@ -538,7 +605,19 @@ func (tis ToolInputSchema) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m)
 }
 
-type ToolAnnotation struct {
+// ToolOutputSchema is a JSON Schema object describing the structured content returned by a tool.
+type ToolOutputSchema ToolInputSchema
+
+// MarshalJSON implements the json.Marshaler interface for ToolOutputSchema.
+func (tos ToolOutputSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ToolInputSchema(tos))
+}
+
+// ToolAnnotations are hints about the behavior of a tool, which clients use,
+// for instance, to ask the user for confirmation before destructive calls.
+// Unset hints are omitted from JSON; use the Is methods to read a hint with
+// the default of the specification applied.
+type ToolAnnotations struct {
 	// Human-readable title for the tool
 	Title string `json:"title,omitempty"`
 	// If true, the tool does not modify its environment
@@ -551,6 +630,43 @@ type ToolAnnotation struct {
 	OpenWorldHint *bool `json:"openWorldHint,omitempty"`
 }
 
+// ToolAnnotation is the former name of ToolAnnotations.
+//
+// Deprecated: Use ToolAnnotations.
+type ToolAnnotation = ToolAnnotations
+
+// IsReadOnly reports whether the tool does not modify its environment. The
+// default is false.
+func (a ToolAnnotations) IsReadOnly() bool {
+	return a.ReadOnlyHint != nil && *a.ReadOnlyHint
+}
+
+// IsDestructive reports whether the tool may perform destructive updates, as
+// opposed to only additive ones. Read-only tools are never destructive;
+// otherwise the default is true.
+func (a ToolAnnotations) IsDestructive() bool {
+	if a.IsReadOnly() {
+		return false
+	}
+	return a.DestructiveHint == nil || *a.DestructiveHint
+}
+
+// IsIdempotent reports whether repeated calls with the same arguments have no
+// additional effect. Read-only tools are always idempotent; otherwise the
+// default is false.
+func (a ToolAnnotations) IsIdempotent() bool {
+	if a.IsReadOnly() {
+		return true
+	}
+	return a.IdempotentHint != nil && *a.IdempotentHint
+}
+
+// IsOpenWorld reports whether the tool interacts with external entities. The
+// default is true.
+func (a ToolAnnotations) IsOpenWorld() bool {
+	return a.OpenWorldHint == nil || *a.OpenWorldHint
+}
+
 // ToolOption is a function that configures a Tool.
 // It provides a flexible way to set various properties of a Tool using the functional options pattern.
 type ToolOption func(*Tool)
@@ -574,13 +690,6 @@ func NewTool(name string, opts ...ToolOption) Tool {
 			Properties: make(map[string]any),
 			Required:   nil, // Will be omitted from JSON if empty
 		},
-		Annotations: ToolAnnotation{
-			Title:           "",
-			ReadOnlyHint:    ToBoolPtr(false),
-			DestructiveHint: ToBoolPtr(true),
-			IdempotentHint:  ToBoolPtr(false),
-			OpenWorldHint:   ToBoolPtr(true),
-		},
 	}
 
 	for _, opt := range opts {
@@ -615,13 +724,39 @@ func WithDescription(description string) ToolOption {
 	}
 }
 
-// WithToolAnnotation adds optional hints about the Tool.
-func WithToolAnnotation(annotation ToolAnnotation) ToolOption {
+// WithInputSchema replaces the input schema of the Tool, e.g. with one built by
+// NewSchema. Properties added by earlier options are discarded.
+func WithInputSchema(schema ToolInputSchema) ToolOption {
 	return func(t *Tool) {
-		t.Annotations = annotation
+		if schema.Properties == nil {
+			schema.Properties = make(map[string]any)
+		}
+		t.InputSchema = schema
 	}
 }
 
+// WithOutputSchema sets the JSON Schema of the structured content returned by the Tool.
+func WithOutputSchema(schema ToolOutputSchema) ToolOption {
+	return func(t *Tool) {
+		t.OutputSchema = &schema
+	}
+}
+
+// WithToolAnnotations sets the hints about the behavior of the Tool, replacing
+// any hints set before. Hints left unset are omitted from tools/list.
+func WithToolAnnotations(annotations ToolAnnotations) ToolOption {
+	return func(t *Tool) {
+		t.Annotations = annotations
+	}
+}
+
+// WithToolAnnotation adds optional hints about the Tool.
+//
+// Deprecated: Use WithToolAnnotations.
+func WithToolAnnotation(annotation ToolAnnotation) ToolOption {
+	return WithToolAnnotations(annotation)
+}
+
 // WithTitleAnnotation sets the Title field of the Tool's Annotations.
 // It provides a human-readable title for the tool.
 func WithTitleAnnotation(title string) ToolOption {
@@ -690,6 +825,14 @@ func Title(title string) PropertyOption {
 	}
 }
 
+// Default sets the default value of a property of any type.
+// This value will be used if the property is not explicitly provided.
+func Default(value any) PropertyOption {
+	return func(schema map[string]any) {
+		schema["default"] = value
+	}
+}
+
 //
 // String Property Options
 //
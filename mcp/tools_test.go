@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestToolWithBothSchemasError verifies that there will be feedback if the
@@ -335,6 +336,54 @@ func TestCallToolRequestBindArguments(t *testing.T) {
 	assert.Equal(t, "john@example.com", args.Email)
 }
 
+func TestCallToolRequestBindArgumentsRequired(t *testing.T) {
+	type TestArgs struct {
+		Path  string `json:"path"`
+		Count int    `json:"count"`
+	}
+	tool := NewTool("test-tool",
+		WithString("path", Required()),
+		WithNumber("count"),
+	)
+
+	tests := []struct {
+		name      string
+		arguments any
+		wantErr   string
+	}{
+		{name: "all required present", arguments: map[string]any{"path": "/tmp"}},
+		{name: "raw JSON", arguments: json.RawMessage(`{"path":"/tmp","count":2}`)},
+		{name: "missing required", arguments: map[string]any{"count": 2}, wantErr: `required argument "path" not found`},
+		{name: "null required", arguments: map[string]any{"path": nil}, wantErr: `required argument "path" not found`},
+		{name: "no arguments", arguments: nil, wantErr: `required argument "path" not found`},
+		{name: "not an object", arguments: []any{"/tmp"}, wantErr: "arguments must be an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CallToolRequest{InputSchema: &tool.InputSchema}
+			req.Params.Name = "test-tool"
+			req.Params.Arguments = tt.arguments
+
+			var args TestArgs
+			err := req.BindArguments(&args)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "/tmp", args.Path)
+		})
+	}
+
+	// Without a schema, missing arguments are left at their zero value
+	req := CallToolRequest{}
+	req.Params.Arguments = map[string]any{"count": 2}
+	var args TestArgs
+	require.NoError(t, req.BindArguments(&args))
+	assert.Equal(t, 2, args.Count)
+}
+
 func TestCallToolRequestHelperFunctions(t *testing.T) {
 	// Create a request with map arguments
 	req := CallToolRequest{}
@@ -712,3 +761,79 @@ func TestNewItemsAPICompatibility(t *testing.T) {
 		})
 	}
 }
+
+func TestCallToolResultStructuredContentRoundTrip(t *testing.T) {
+	result := NewToolResultStructured(map[string]any{"count": float64(3)}, `{"count":3}`)
+
+	data, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"structuredContent":{"count":3}`)
+
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"count": float64(3)}, parsed.StructuredContent)
+	assert.Len(t, parsed.Content, 1)
+
+	// structuredContent is omitted when not set
+	data, err = json.Marshal(NewToolResultText("plain"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "structuredContent")
+}
+
+func TestNewToolResultErrorWithCode(t *testing.T) {
+	result := NewToolResultErrorWithCode("NOT_FOUND", "user not found", map[string]any{"id": "42"})
+	assert.True(t, result.IsError)
+
+	data, err := json.Marshal(result)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"structuredContent":{"error":{"code":"NOT_FOUND","message":"user not found","details":{"id":"42"}}}`)
+
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	assert.NoError(t, err)
+	assert.True(t, parsed.IsError)
+	assert.Equal(t, "user not found", parsed.Content[0].(TextContent).Text)
+
+	// Details are omitted when not given
+	data, err = json.Marshal(NewToolResultErrorWithCode("RATE_LIMITED", "slow down", nil))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "details")
+}
+
+func TestToolAnnotations(t *testing.T) {
+	// Unset annotations are omitted
+	data, err := json.Marshal(NewTool("plain"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "annotations")
+
+	var plain Tool
+	require.NoError(t, json.Unmarshal(data, &plain))
+	assert.Equal(t, ToolAnnotations{}, plain.Annotations)
+	assert.False(t, plain.Annotations.IsReadOnly())
+	assert.True(t, plain.Annotations.IsDestructive())
+	assert.False(t, plain.Annotations.IsIdempotent())
+	assert.True(t, plain.Annotations.IsOpenWorld())
+
+	// Set hints round-trip, including explicit false values
+	tool := NewTool("delete_file", WithToolAnnotations(ToolAnnotations{
+		Title:           "Delete File",
+		DestructiveHint: ToBoolPtr(true),
+		OpenWorldHint:   ToBoolPtr(false),
+	}))
+	data, err = json.Marshal(tool)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"annotations":{"title":"Delete File","destructiveHint":true,"openWorldHint":false}`)
+
+	var decoded Tool
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, tool.Annotations, decoded.Annotations)
+	assert.True(t, decoded.Annotations.IsDestructive())
+	assert.False(t, decoded.Annotations.IsOpenWorld())
+
+	// Read-only tools are neither destructive nor have side effects to repeat
+	readOnly := NewTool("read_file", WithReadOnlyHintAnnotation(true), WithDestructiveHintAnnotation(true))
+	assert.True(t, readOnly.Annotations.IsReadOnly())
+	assert.False(t, readOnly.Annotations.IsDestructive())
+	assert.True(t, readOnly.Annotations.IsIdempotent())
+}
@@ -3,10 +3,12 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"maps"
 	"strconv"
+	"strings"
 
 	"github.com/yosida95/uritemplate/v3"
 )
@@ -34,6 +36,14 @@ const (
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/resources/
 	MethodResourcesRead MCPMethod = "resources/read"
 
+	// MethodResourcesSubscribe subscribes to updates of a specific resource.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
+	MethodResourcesSubscribe MCPMethod = "resources/subscribe"
+
+	// MethodResourcesUnsubscribe cancels a previous resource subscription.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#subscriptions
+	MethodResourcesUnsubscribe MCPMethod = "resources/unsubscribe"
+
 	// MethodPromptsList lists all available prompt templates.
 	// https://modelcontextprotocol.io/specification/2024-11-05/server/prompts/
 	MethodPromptsList MCPMethod = "prompts/list"
@@ -54,6 +64,31 @@ const (
 	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/logging
 	MethodSetLogLevel MCPMethod = "logging/setLevel"
 
+	// MethodCompletionComplete requests completion options for a prompt or resource template argument.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/completion
+	MethodCompletionComplete MCPMethod = "completion/complete"
+
+	// MethodRootsList is sent by the server to request the client's filesystem roots.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/roots
+	MethodRootsList MCPMethod = "roots/list"
+
+	// MethodSamplingCreateMessage is sent by the server to request an LLM completion from the client.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/sampling
+	MethodSamplingCreateMessage MCPMethod = "sampling/createMessage"
+
+	// MethodElicitationCreate is sent by the server to request additional information from the user via the client.
+	// https://modelcontextprotocol.io/specification/2025-06-18/client/elicitation
+	MethodElicitationCreate MCPMethod = "elicitation/create"
+
+	// MethodNotificationInitialized is sent by the client after the initialize response
+	// to signal that it is ready for normal operation.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/lifecycle#initialization
+	MethodNotificationInitialized = "notifications/initialized"
+
+	// MethodNotificationCancelled indicates that a previously-issued request is being cancelled.
+	// https://modelcontextprotocol.io/specification/2025-03-26/basic/utilities/cancellation
+	MethodNotificationCancelled = "notifications/cancelled"
+
 	// MethodNotificationResourcesListChanged notifies when the list of available resources changes.
 	// https://modelcontextprotocol.io/specification/2025-03-26/server/resources#list-changed-notification
 	MethodNotificationResourcesListChanged = "notifications/resources/list_changed"
@@ -67,6 +102,19 @@ const (
 	// MethodNotificationToolsListChanged notifies when the list of available tools changes.
 	// https://spec.modelcontextprotocol.io/specification/2024-11-05/server/tools/list_changed/
 	MethodNotificationToolsListChanged = "notifications/tools/list_changed"
+
+	// MethodNotificationRootsListChanged notifies the server when the client's list of roots changes.
+	// https://modelcontextprotocol.io/specification/2025-03-26/client/roots#root-list-changes
+	MethodNotificationRootsListChanged = "notifications/roots/list_changed"
+
+	// MethodNotificationMessage carries a log message from the server to the client.
+	// https://modelcontextprotocol.io/specification/2025-03-26/server/utilities/logging#log-message-notifications
+	MethodNotificationMessage = "notifications/message"
+
+	// MethodNotificationToolOutput carries content emitted by a streaming tool before
+	// its tools/call request completes. Its params hold the progressToken of the
+	// request and the emitted content. This is an extension to the MCP specification.
+	MethodNotificationToolOutput = "notifications/tools/output"
 )
 
 type URITemplate struct {
@@ -446,6 +494,8 @@ type ClientCapabilities struct {
 	} `json:"roots,omitempty"`
 	// Present if the client supports sampling from an LLM.
 	Sampling *struct{} `json:"sampling,omitempty"`
+	// Present if the client supports elicitation requests from the server.
+	Elicitation *struct{} `json:"elicitation,omitempty"`
 }
 
 // ServerCapabilities represents capabilities that a server may support. Known
@@ -456,6 +506,8 @@ type ServerCapabilities struct {
 	Experimental map[string]any `json:"experimental,omitempty"`
 	// Present if the server supports sending log messages to the client.
 	Logging *struct{} `json:"logging,omitempty"`
+	// Present if the server offers argument autocompletion.
+	Completions *struct{} `json:"completions,omitempty"`
 	// Present if the server offers any prompt templates.
 	Prompts *struct {
 		// Whether this server supports notifications for changes to the prompt list.
@@ -576,6 +628,35 @@ type ReadResourceParams struct {
 	URI string `json:"uri"`
 	// Arguments to pass to the resource handler
 	Arguments map[string]any `json:"arguments,omitempty"`
+	// Range requests only a slice of the resource contents. Servers that do
+	// not support ranges for the resource return the full contents, without
+	// a range in the result. This is an extension to the MCP specification.
+	Range *ByteRange `json:"range,omitempty"`
+}
+
+// ByteRange is a slice of the contents of a resource: Length bytes starting at
+// Offset. A Length of zero reaches the end of the resource.
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length,omitempty"`
+}
+
+// TemplateParams returns the variables extracted from the URI when the request
+// matched a resource template, e.g. {"id": "42"} for "db://users/42" and the
+// template "db://users/{id}". List values are joined with commas.
+func (r ReadResourceRequest) TemplateParams() map[string]string {
+	params := make(map[string]string, len(r.Params.Arguments))
+	for name, value := range r.Params.Arguments {
+		switch v := value.(type) {
+		case string:
+			params[name] = v
+		case []string:
+			params[name] = strings.Join(v, ",")
+		default:
+			params[name] = fmt.Sprint(v)
+		}
+	}
+	return params
 }
 
 // ReadResourceResult is the server's response to a resources/read request
@@ -583,6 +664,10 @@ type ReadResourceParams struct {
 type ReadResourceResult struct {
 	Result
 	Contents []ResourceContents `json:"contents"` // Can be TextResourceContents or BlobResourceContents
+	// Range is the requested range when the contents hold only that slice of
+	// the resource, and nil when they hold the full resource. The slice is
+	// shorter than requested if the resource ends first.
+	Range *ByteRange `json:"range,omitempty"`
 }
 
 // ResourceListChangedNotification is an optional notification from the server
@@ -674,6 +759,9 @@ type ResourceTemplate struct {
 	// The MIME type for all resources that match this template. This should only
 	// be included if all resources matching this template have the same type.
 	MIMEType string `json:"mimeType,omitempty"`
+	// Completion functions for the template variables, keyed by variable name.
+	// They answer completion/complete requests and are not sent to clients.
+	ArgumentCompleters map[string]ArgumentCompleterFunc `json:"-"`
 }
 
 // GetName returns the name of the resourceTemplate.
@@ -761,6 +849,32 @@ const (
 	LoggingLevelEmergency LoggingLevel = "emergency"
 )
 
+// loggingLevelSeverity orders the logging levels from least to most severe.
+var loggingLevelSeverity = map[LoggingLevel]int{
+	LoggingLevelDebug:     0,
+	LoggingLevelInfo:      1,
+	LoggingLevelNotice:    2,
+	LoggingLevelWarning:   3,
+	LoggingLevelError:     4,
+	LoggingLevelCritical:  5,
+	LoggingLevelAlert:     6,
+	LoggingLevelEmergency: 7,
+}
+
+// ShouldSendTo reports whether a message at this level should be sent to a
+// client that set minLevel as its minimum level. Unknown levels are never sent.
+func (l LoggingLevel) ShouldSendTo(minLevel LoggingLevel) bool {
+	severity, ok := loggingLevelSeverity[l]
+	if !ok {
+		return false
+	}
+	minSeverity, ok := loggingLevelSeverity[minLevel]
+	if !ok {
+		return false
+	}
+	return severity >= minSeverity
+}
+
 /* Sampling */
 
 // CreateMessageRequest is a request from the server to sample an LLM via the
@@ -866,6 +980,8 @@ type AudioContent struct {
 func (AudioContent) isContent() {}
 
 // ResourceLink represents a link to a resource that the client can access.
+// Tools can return resource links instead of embedding the resource contents;
+// the client can read the resource with resources/read.
 type ResourceLink struct {
 	Annotated
 	Type string `json:"type"` // Must be "resource_link"
@@ -874,9 +990,9 @@ type ResourceLink struct {
 	// The name of the resource.
 	Name string `json:"name"`
 	// The description of the resource.
-	Description string `json:"description"`
+	Description string `json:"description,omitempty"`
 	// The MIME type of the resource.
-	MIMEType string `json:"mimeType"`
+	MIMEType string `json:"mimeType,omitempty"`
 }
 
 func (ResourceLink) isContent() {}
@@ -958,6 +1074,55 @@ type ModelHint struct {
 	Name string `json:"name,omitempty"`
 }
 
+/* Elicitation */
+
+// ElicitRequest is a request from the server to ask the user for additional
+// information via the client. The client presents the message and a form for
+// the requested schema, and returns the user's action and input.
+type ElicitRequest struct {
+	Request
+	Params ElicitParams `json:"params"`
+}
+
+type ElicitParams struct {
+	// The message to present to the user.
+	Message string `json:"message"`
+	// The schema of the requested input. It is a flat object schema whose
+	// properties are of primitive types.
+	RequestedSchema ToolInputSchema `json:"requestedSchema"`
+}
+
+// ElicitationAction is the user's response to an elicitation request.
+type ElicitationAction string
+
+const (
+	// ElicitationActionAccept means the user submitted the requested input.
+	ElicitationActionAccept ElicitationAction = "accept"
+	// ElicitationActionDecline means the user explicitly declined to provide the input.
+	ElicitationActionDecline ElicitationAction = "decline"
+	// ElicitationActionCancel means the user dismissed the request without choosing.
+	ElicitationActionCancel ElicitationAction = "cancel"
+)
+
+// Valid reports whether the action is one of the defined elicitation actions.
+func (a ElicitationAction) Valid() bool {
+	switch a {
+	case ElicitationActionAccept, ElicitationActionDecline, ElicitationActionCancel:
+		return true
+	}
+	return false
+}
+
+// ElicitResult is the client's response to an elicitation/create request.
+type ElicitResult struct {
+	Result
+	// The user's action.
+	Action ElicitationAction `json:"action"`
+	// The submitted input, matching the requested schema. Only present when
+	// the action is "accept".
+	Content map[string]any `json:"content,omitempty"`
+}
+
 /* Autocomplete */
 
 // CompleteRequest is a request from the client to the server, to ask for completion options.
@@ -991,6 +1156,20 @@ type CompleteResult struct {
 	} `json:"completion"`
 }
 
+// MaxCompletionValues is the maximum number of values a completion result may contain.
+const MaxCompletionValues = 100
+
+const (
+	// RefTypePrompt is the reference type of a PromptReference.
+	RefTypePrompt = "ref/prompt"
+	// RefTypeResource is the reference type of a ResourceReference.
+	RefTypeResource = "ref/resource"
+)
+
+// ArgumentCompleterFunc returns the completion values for an argument, given
+// the partial value entered so far.
+type ArgumentCompleterFunc func(ctx context.Context, partial string) []string
+
 // ResourceReference is a reference to a resource or resource template definition.
 type ResourceReference struct {
 	Type string `json:"type"`
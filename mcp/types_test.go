@@ -68,3 +68,85 @@ func TestMetaMarshalling(t *testing.T) {
 		})
 	}
 }
+
+func TestLoggingLevelShouldSendTo(t *testing.T) {
+	assert.True(t, LoggingLevelInfo.ShouldSendTo(LoggingLevelInfo))
+	assert.True(t, LoggingLevelEmergency.ShouldSendTo(LoggingLevelDebug))
+	assert.True(t, LoggingLevelError.ShouldSendTo(LoggingLevelWarning))
+	assert.False(t, LoggingLevelDebug.ShouldSendTo(LoggingLevelInfo))
+	assert.False(t, LoggingLevelWarning.ShouldSendTo(LoggingLevelError))
+	assert.False(t, LoggingLevel("verbose").ShouldSendTo(LoggingLevelDebug))
+}
+
+func TestResourceLinkToolResult(t *testing.T) {
+	result := NewToolResultResourceLink("see the report", "file:///reports/q3.pdf", "q3.pdf", "", "application/pdf")
+
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"content": [
+			{"type": "text", "text": "see the report"},
+			{"type": "resource_link", "uri": "file:///reports/q3.pdf", "name": "q3.pdf", "mimeType": "application/pdf"}
+		]
+	}`, string(data))
+
+	raw := json.RawMessage(data)
+	parsed, err := ParseCallToolResult(&raw)
+	require.NoError(t, err)
+	require.Len(t, parsed.Content, 2)
+
+	link, ok := AsResourceLink(parsed.Content[1])
+	require.True(t, ok, "expected resource link, got %T", parsed.Content[1])
+	assert.Equal(t, "file:///reports/q3.pdf", link.URI)
+	assert.Equal(t, "q3.pdf", link.Name)
+	assert.Equal(t, "application/pdf", link.MIMEType)
+}
+
+func TestReadResourceRequestTemplateParams(t *testing.T) {
+	req := ReadResourceRequest{}
+	req.Params.Arguments = map[string]any{
+		"id":   []string{"42"},
+		"tags": []string{"a", "b"},
+		"name": "plain",
+	}
+	assert.Equal(t, map[string]string{"id": "42", "tags": "a,b", "name": "plain"}, req.TemplateParams())
+	assert.Empty(t, ReadResourceRequest{}.TemplateParams())
+}
+
+func TestGetPromptResultMultimodalRoundTrip(t *testing.T) {
+	image := NewPromptMessageImage(RoleUser, "iVBORw0KGgo=", "image/png")
+	audio := NewPromptMessageAudio(RoleAssistant, "UklGRiQAAABXQVZF", "audio/wav")
+	annotated := audio.Content.(AudioContent)
+	annotated.Annotations = &Annotations{Audience: []Role{RoleUser}, Priority: 0.5}
+	audio.Content = annotated
+	original := GetPromptResult{
+		Description: "multimodal",
+		Messages: []PromptMessage{
+			NewPromptMessage(RoleUser, NewTextContent("Describe these")),
+			image,
+			audio,
+		},
+	}
+
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	raw := json.RawMessage(data)
+	parsed, err := ParseGetPromptResult(&raw)
+	require.NoError(t, err)
+	assert.Equal(t, original.Messages, parsed.Messages)
+
+	var decoded GetPromptResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, original.Messages, decoded.Messages)
+}
+
+func TestParsePromptMessageValidatesBase64(t *testing.T) {
+	for _, contentType := range []string{"image", "audio"} {
+		t.Run(contentType, func(t *testing.T) {
+			var message PromptMessage
+			err := json.Unmarshal([]byte(`{"role": "user", "content": {"type": "`+contentType+`", "data": "not base64!", "mimeType": "application/octet-stream"}}`), &message)
+			assert.ErrorContains(t, err, contentType+" data is not valid base64")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 
@@ -88,6 +89,11 @@ func AsEmbeddedResource(content any) (*EmbeddedResource, bool) {
 	return asType[EmbeddedResource](content)
 }
 
+// AsResourceLink attempts to cast the given interface to ResourceLink
+func AsResourceLink(content any) (*ResourceLink, bool) {
+	return asType[ResourceLink](content)
+}
+
 // AsTextResourceContents attempts to cast the given interface to TextResourceContents
 func AsTextResourceContents(content any) (*TextResourceContents, bool) {
 	return asType[TextResourceContents](content)
@@ -176,7 +182,7 @@ func NewLoggingMessageNotification(
 ) LoggingMessageNotification {
 	return LoggingMessageNotification{
 		Notification: Notification{
-			Method: "notifications/message",
+			Method: MethodNotificationMessage,
 		},
 		Params: struct {
 			Level  LoggingLevel `json:"level"`
@@ -199,6 +205,20 @@ func NewPromptMessage(role Role, content Content) PromptMessage {
 	}
 }
 
+// NewPromptMessageImage
+// Helper function to create a new PromptMessage with image content.
+// The data must be base64-encoded.
+func NewPromptMessageImage(role Role, data, mimeType string) PromptMessage {
+	return NewPromptMessage(role, NewImageContent(data, mimeType))
+}
+
+// NewPromptMessageAudio
+// Helper function to create a new PromptMessage with audio content.
+// The data must be base64-encoded.
+func NewPromptMessageAudio(role Role, data, mimeType string) PromptMessage {
+	return NewPromptMessage(role, NewAudioContent(data, mimeType))
+}
+
 // NewTextContent
 // Helper function to create a new TextContent
 func NewTextContent(text string) TextContent {
@@ -258,6 +278,20 @@ func NewToolResultText(text string) *CallToolResult {
 	}
 }
 
+// NewToolResultStructured creates a new CallToolResult with structured content
+// and a text fallback for clients that do not support structured content
+func NewToolResultStructured(structured any, fallbackText string) *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{
+			TextContent{
+				Type: "text",
+				Text: fallbackText,
+			},
+		},
+		StructuredContent: structured,
+	}
+}
+
 // NewToolResultImage creates a new CallToolResult with both text and image content
 func NewToolResultImage(text, imageData, mimeType string) *CallToolResult {
 	return &CallToolResult{
@@ -311,6 +345,22 @@ func NewToolResultResource(
 	}
 }
 
+// NewToolResultResourceLink creates a new CallToolResult with a text content
+// and a link to a resource the client can read
+func NewToolResultResourceLink(
+	text, uri, name, description, mimeType string,
+) *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{
+			TextContent{
+				Type: "text",
+				Text: text,
+			},
+			NewResourceLink(uri, name, description, mimeType),
+		},
+	}
+}
+
 // NewToolResultError creates a new CallToolResult with an error message.
 // Any errors that originate from the tool SHOULD be reported inside the result object.
 func NewToolResultError(text string) *CallToolResult {
@@ -325,6 +375,29 @@ func NewToolResultError(text string) *CallToolResult {
 	}
 }
 
+// NewToolResultErrorWithCode creates a new CallToolResult with an error message
+// and a machine-readable error code. The code, message and details are returned
+// as the "error" field of the structured content, and the message as text
+// content for clients that do not read structured content.
+func NewToolResultErrorWithCode(code, message string, details any) *CallToolResult {
+	return &CallToolResult{
+		Content: []Content{
+			TextContent{
+				Type: "text",
+				Text: message,
+			},
+		},
+		StructuredContent: map[string]any{
+			"error": ToolErrorContent{
+				Code:    code,
+				Message: message,
+				Details: details,
+			},
+		},
+		IsError: true,
+	}
+}
+
 // NewToolResultErrorFromErr creates a new CallToolResult with an error message.
 // If an error is provided, its details will be appended to the text message.
 // Any errors that originate from the tool SHOULD be reported inside the result object.
@@ -483,11 +556,16 @@ func ExtractMap(data map[string]any, key string) map[string]any {
 
 func ParseContent(contentMap map[string]any) (Content, error) {
 	contentType := ExtractString(contentMap, "type")
+	annotated, err := parseAnnotated(contentMap)
+	if err != nil {
+		return nil, err
+	}
 
 	switch contentType {
 	case "text":
-		text := ExtractString(contentMap, "text")
-		return NewTextContent(text), nil
+		text := NewTextContent(ExtractString(contentMap, "text"))
+		text.Annotated = annotated
+		return text, nil
 
 	case "image":
 		data := ExtractString(contentMap, "data")
@@ -495,7 +573,9 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		if data == "" || mimeType == "" {
 			return nil, fmt.Errorf("image data or mimeType is missing")
 		}
-		return NewImageContent(data, mimeType), nil
+		image := NewImageContent(data, mimeType)
+		image.Annotated = annotated
+		return image, nil
 
 	case "audio":
 		data := ExtractString(contentMap, "data")
@@ -503,7 +583,9 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		if data == "" || mimeType == "" {
 			return nil, fmt.Errorf("audio data or mimeType is missing")
 		}
-		return NewAudioContent(data, mimeType), nil
+		audio := NewAudioContent(data, mimeType)
+		audio.Annotated = annotated
+		return audio, nil
 
 	case "resource_link":
 		uri := ExtractString(contentMap, "uri")
@@ -513,7 +595,9 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 		if uri == "" || name == "" {
 			return nil, fmt.Errorf("resource_link uri or name is missing")
 		}
-		return NewResourceLink(uri, name, description, mimeType), nil
+		link := NewResourceLink(uri, name, description, mimeType)
+		link.Annotated = annotated
+		return link, nil
 
 	case "resource":
 		resourceMap := ExtractMap(contentMap, "resource")
@@ -526,7 +610,9 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 			return nil, err
 		}
 
-		return NewEmbeddedResource(resourceContents), nil
+		resource := NewEmbeddedResource(resourceContents)
+		resource.Annotated = annotated
+		return resource, nil
 
 	case "command":
 		command := ExtractString(contentMap, "command")
@@ -544,6 +630,55 @@ func ParseContent(contentMap map[string]any) (Content, error) {
 	return nil, fmt.Errorf("unsupported content type: %s", contentType)
 }
 
+// parseAnnotated parses the optional annotations of a content map.
+func parseAnnotated(contentMap map[string]any) (Annotated, error) {
+	raw, ok := contentMap["annotations"]
+	if !ok || raw == nil {
+		return Annotated{}, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return Annotated{}, fmt.Errorf("failed to marshal annotations: %w", err)
+	}
+	var annotations Annotations
+	if err := json.Unmarshal(data, &annotations); err != nil {
+		return Annotated{}, fmt.Errorf("invalid annotations: %w", err)
+	}
+	return Annotated{Annotations: &annotations}, nil
+}
+
+// ParsePromptMessage parses a prompt message from its JSON object.
+// Image and audio content must carry valid base64 data.
+func ParsePromptMessage(messageMap map[string]any) (PromptMessage, error) {
+	roleStr := ExtractString(messageMap, "role")
+	if roleStr == "" || (roleStr != string(RoleAssistant) && roleStr != string(RoleUser)) {
+		return PromptMessage{}, fmt.Errorf("unsupported role: %s", roleStr)
+	}
+
+	contentMap, ok := messageMap["content"].(map[string]any)
+	if !ok {
+		return PromptMessage{}, fmt.Errorf("content is not an object")
+	}
+
+	content, err := ParseContent(contentMap)
+	if err != nil {
+		return PromptMessage{}, err
+	}
+
+	switch content := content.(type) {
+	case ImageContent:
+		if _, err := base64.StdEncoding.DecodeString(content.Data); err != nil {
+			return PromptMessage{}, fmt.Errorf("image data is not valid base64: %w", err)
+		}
+	case AudioContent:
+		if _, err := base64.StdEncoding.DecodeString(content.Data); err != nil {
+			return PromptMessage{}, fmt.Errorf("audio data is not valid base64: %w", err)
+		}
+	}
+
+	return NewPromptMessage(Role(roleStr), content), nil
+}
+
 func ParseGetPromptResult(rawMessage *json.RawMessage) (*GetPromptResult, error) {
 	if rawMessage == nil {
 		return nil, fmt.Errorf("response is nil")
@@ -583,27 +718,11 @@ func ParseGetPromptResult(rawMessage *json.RawMessage) (*GetPromptResult, error)
 				return nil, fmt.Errorf("message is not an object")
 			}
 
-			// Extract role
-			roleStr := ExtractString(messageMap, "role")
-			if roleStr == "" || (roleStr != string(RoleAssistant) && roleStr != string(RoleUser)) {
-				return nil, fmt.Errorf("unsupported role: %s", roleStr)
-			}
-
-			// Extract content
-			contentMap, ok := messageMap["content"].(map[string]any)
-			if !ok {
-				return nil, fmt.Errorf("content is not an object")
-			}
-
-			// Process content
-			content, err := ParseContent(contentMap)
+			promptMessage, err := ParsePromptMessage(messageMap)
 			if err != nil {
 				return nil, err
 			}
-
-			// Append processed message
-			result.Messages = append(result.Messages, NewPromptMessage(Role(roleStr), content))
-
+			result.Messages = append(result.Messages, promptMessage)
 		}
 	}
 
@@ -636,6 +755,10 @@ func ParseCallToolResult(rawMessage *json.RawMessage) (*CallToolResult, error) {
 		}
 	}
 
+	if structured, ok := jsonContent["structuredContent"]; ok {
+		result.StructuredContent = structured
+	}
+
 	contents, ok := jsonContent["content"]
 	if !ok {
 		return nil, fmt.Errorf("content is missing")
@@ -711,6 +834,12 @@ func ParseReadResourceResult(rawMessage *json.RawMessage) (*ReadResourceResult,
 		}
 	}
 
+	if byteRange, ok := jsonContent["range"].(map[string]any); ok {
+		offset, _ := byteRange["offset"].(float64)
+		length, _ := byteRange["length"].(float64)
+		result.Range = &ByteRange{Offset: int64(offset), Length: int64(length)}
+	}
+
 	contents, ok := jsonContent["contents"]
 	if !ok {
 		return nil, fmt.Errorf("contents is missing")
@@ -0,0 +1,350 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaValidationError is returned when a value does not conform to a JSON Schema.
+type SchemaValidationError struct {
+	// Path is the location of the offending value, e.g. "$.items[2].name".
+	Path string
+	// Message describes why the value is invalid.
+	Message string
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// SchemaValidationErrors lists all the violations of a JSON Schema found in a
+// value, in a deterministic order.
+type SchemaValidationErrors []*SchemaValidationError
+
+func (e SchemaValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the individual violations, for use with errors.As.
+func (e SchemaValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ValidateSchema validates value against the given JSON Schema.
+//
+// Both schema and value may be any JSON-serializable Go value, such as a
+// ToolInputSchema, a map or a json.RawMessage. The commonly used validation
+// keywords are supported: type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, minLength, maxLength,
+// pattern, minimum, maximum, exclusiveMinimum, exclusiveMaximum, allOf, anyOf
+// and oneOf. Unknown keywords are ignored.
+//
+// The first violation found is returned as a *SchemaValidationError. Use
+// ValidateSchemaAll to get all of them.
+func ValidateSchema(schema any, value any) error {
+	errs, err := validate(schema, value)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateSchemaAll is like ValidateSchema, but returns all the violations
+// found as SchemaValidationErrors.
+func ValidateSchemaAll(schema any, value any) error {
+	errs, err := validate(schema, value)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validate(schema any, value any) (SchemaValidationErrors, error) {
+	s, err := toJSONValue(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	v, err := toJSONValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	return validateValue(s, v, "$"), nil
+}
+
+// toJSONValue normalizes a Go value to its generic JSON representation.
+func toJSONValue(value any) (any, error) {
+	var data []byte
+	switch v := value.(type) {
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	default:
+		var err error
+		data, err = json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func validateValue(schema any, value any, path string) []*SchemaValidationError {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return []*SchemaValidationError{{Path: path, Message: "no value is allowed"}}
+		}
+		return nil
+	case map[string]any:
+		return validateObjectSchema(s, value, path)
+	default:
+		// Anything else is not a schema, accept all values
+		return nil
+	}
+}
+
+func validateObjectSchema(schema map[string]any, value any, path string) []*SchemaValidationError {
+	if t, ok := schema["type"]; ok {
+		// The other keywords are meaningless for a value of the wrong type
+		if err := validateType(t, value, path); err != nil {
+			return []*SchemaValidationError{err}
+		}
+	}
+
+	var errs []*SchemaValidationError
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %s is not one of %s", jsonString(value), jsonString(enum))})
+		}
+	}
+
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %s does not equal %s", jsonString(value), jsonString(c))})
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		errs = append(errs, validateObject(schema, v, path)...)
+	case []any:
+		errs = append(errs, validateArray(schema, v, path)...)
+	case string:
+		errs = append(errs, validateString(schema, v, path)...)
+	case float64:
+		errs = append(errs, validateNumber(schema, v, path)...)
+	}
+
+	if allOf, ok := schema["allOf"].([]any); ok {
+		for _, sub := range allOf {
+			errs = append(errs, validateValue(sub, value, path)...)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if len(validateValue(sub, value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			errs = append(errs, &SchemaValidationError{Path: path, Message: "value does not match any of the allowed schemas"})
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, sub := range oneOf {
+			if len(validateValue(sub, value, path)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value must match exactly one schema, matched %d", matches)})
+		}
+	}
+
+	return errs
+}
+
+func validateType(t any, value any, path string) *SchemaValidationError {
+	var types []string
+	switch tt := t.(type) {
+	case string:
+		types = []string{tt}
+	case []any:
+		for _, item := range tt {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return nil
+	}
+
+	actual := jsonType(value)
+	for _, expected := range types {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return &SchemaValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), actual)}
+}
+
+// jsonType returns the JSON Schema type name of a generic JSON value.
+func jsonType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func validateObject(schema map[string]any, value map[string]any, path string) []*SchemaValidationError {
+	var errs []*SchemaValidationError
+	if required, ok := schema["required"].([]any); ok {
+		for _, r := range required {
+			name, ok := r.(string)
+			if !ok {
+				continue
+			}
+			if _, exists := value[name]; !exists {
+				errs = append(errs, &SchemaValidationError{Path: propertyPath(path, name), Message: "required property is missing"})
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+
+	// Validate in a stable order so the reported errors are deterministic
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyValue := value[name]
+		if propertySchema, ok := properties[name]; ok {
+			errs = append(errs, validateValue(propertySchema, propertyValue, propertyPath(path, name))...)
+			continue
+		}
+		if additional, ok := schema["additionalProperties"]; ok {
+			if allowed, isBool := additional.(bool); isBool && !allowed {
+				errs = append(errs, &SchemaValidationError{Path: propertyPath(path, name), Message: "additional property is not allowed"})
+				continue
+			}
+			errs = append(errs, validateValue(additional, propertyValue, propertyPath(path, name))...)
+		}
+	}
+	return errs
+}
+
+func validateArray(schema map[string]any, value []any, path string) []*SchemaValidationError {
+	var errs []*SchemaValidationError
+	if minItems, ok := schema["minItems"].(float64); ok && float64(len(value)) < minItems {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("expected at least %v items, got %d", minItems, len(value))})
+	}
+	if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(value)) > maxItems {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("expected at most %v items, got %d", maxItems, len(value))})
+	}
+	if items, ok := schema["items"]; ok {
+		for i, item := range value {
+			errs = append(errs, validateValue(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return errs
+}
+
+func validateString(schema map[string]any, value string, path string) []*SchemaValidationError {
+	var errs []*SchemaValidationError
+	length := utf8.RuneCountInString(value)
+	if minLength, ok := schema["minLength"].(float64); ok && float64(length) < minLength {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("expected at least %v characters, got %d", minLength, length)})
+	}
+	if maxLength, ok := schema["maxLength"].(float64); ok && float64(length) > maxLength {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("expected at most %v characters, got %d", maxLength, length)})
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("invalid pattern %q: %v", pattern, err)})
+		} else if !re.MatchString(value) {
+			errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %q does not match pattern %q", value, pattern)})
+		}
+	}
+	return errs
+}
+
+func validateNumber(schema map[string]any, value float64, path string) []*SchemaValidationError {
+	var errs []*SchemaValidationError
+	if minimum, ok := schema["minimum"].(float64); ok && value < minimum {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %v is less than minimum %v", value, minimum)})
+	}
+	if maximum, ok := schema["maximum"].(float64); ok && value > maximum {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %v is greater than maximum %v", value, maximum)})
+	}
+	if exclusiveMinimum, ok := schema["exclusiveMinimum"].(float64); ok && value <= exclusiveMinimum {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %v must be greater than %v", value, exclusiveMinimum)})
+	}
+	if exclusiveMaximum, ok := schema["exclusiveMaximum"].(float64); ok && value >= exclusiveMaximum {
+		errs = append(errs, &SchemaValidationError{Path: path, Message: fmt.Sprintf("value %v must be less than %v", value, exclusiveMaximum)})
+	}
+	return errs
+}
+
+func propertyPath(path, name string) string {
+	return path + "." + name
+}
+
+func jsonString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "minLength": 1},
+			"count": map[string]any{"type": "integer", "minimum": 0},
+			"mode":  map[string]any{"type": "string", "enum": []string{"fast", "slow"}},
+			"tags": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string", "pattern": "^[a-z]+$"},
+			},
+			"owner": map[string]any{
+				"type":                 "object",
+				"properties":           map[string]any{"email": map[string]any{"type": "string"}},
+				"required":             []string{"email"},
+				"additionalProperties": false,
+			},
+		},
+		"required": []string{"name", "count"},
+	}
+
+	tests := []struct {
+		name     string
+		value    any
+		wantPath string
+	}{
+		{
+			name:  "valid",
+			value: map[string]any{"name": "x", "count": 3, "mode": "fast", "tags": []string{"a", "b"}, "owner": map[string]any{"email": "a@b.c"}},
+		},
+		{
+			name: "valid struct value",
+			value: struct {
+				Name  string `json:"name"`
+				Count int    `json:"count"`
+			}{Name: "x", Count: 1},
+		},
+		{name: "missing required", value: map[string]any{"name": "x"}, wantPath: "$.count"},
+		{name: "wrong type", value: map[string]any{"name": "x", "count": "3"}, wantPath: "$.count"},
+		{name: "not an integer", value: map[string]any{"name": "x", "count": 1.5}, wantPath: "$.count"},
+		{name: "below minimum", value: map[string]any{"name": "x", "count": -1}, wantPath: "$.count"},
+		{name: "too short", value: map[string]any{"name": "", "count": 1}, wantPath: "$.name"},
+		{name: "not in enum", value: map[string]any{"name": "x", "count": 1, "mode": "medium"}, wantPath: "$.mode"},
+		{name: "array item", value: map[string]any{"name": "x", "count": 1, "tags": []string{"ok", "NOT"}}, wantPath: "$.tags[1]"},
+		{name: "nested required", value: map[string]any{"name": "x", "count": 1, "owner": map[string]any{}}, wantPath: "$.owner.email"},
+		{name: "additional property", value: map[string]any{"name": "x", "count": 1, "owner": map[string]any{"email": "e", "extra": 1}}, wantPath: "$.owner.extra"},
+		{name: "root type", value: []string{"x"}, wantPath: "$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSchema(schema, tt.value)
+			if tt.wantPath == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *SchemaValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantPath, validationErr.Path)
+			assert.Contains(t, err.Error(), tt.wantPath)
+		})
+	}
+}
+
+func TestValidateSchemaAll(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"name":  map[string]any{"type": "string", "minLength": 1},
+			"count": map[string]any{"type": "integer", "minimum": 0, "maximum": 10},
+			"tags":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required": []string{"name", "path"},
+	}
+
+	err := ValidateSchemaAll(schema, map[string]any{"count": -1, "tags": []any{"a", 1, true}})
+	var errs SchemaValidationErrors
+	require.ErrorAs(t, err, &errs)
+	paths := make([]string, len(errs))
+	for i, e := range errs {
+		paths[i] = e.Path
+	}
+	assert.Equal(t, []string{"$.name", "$.path", "$.count", "$.tags[1]", "$.tags[2]"}, paths)
+	assert.Contains(t, err.Error(), "$.count: value -1 is less than minimum 0")
+
+	// The individual violations can be matched too
+	var first *SchemaValidationError
+	require.ErrorAs(t, err, &first)
+	assert.Equal(t, "$.name", first.Path)
+
+	// ValidateSchema reports only the first of them
+	require.ErrorAs(t, ValidateSchema(schema, map[string]any{"count": -1}), &first)
+	assert.Equal(t, "$.name", first.Path)
+
+	assert.NoError(t, ValidateSchemaAll(schema, map[string]any{"name": "x", "path": "p"}))
+}
+
+func TestValidateSchemaCombinators(t *testing.T) {
+	schema := json.RawMessage(`{
+		"anyOf": [{"type": "string"}, {"type": "number"}],
+		"not-a-keyword": true
+	}`)
+	assert.NoError(t, ValidateSchema(schema, "text"))
+	assert.NoError(t, ValidateSchema(schema, 42))
+	assert.Error(t, ValidateSchema(schema, true))
+
+	oneOf := map[string]any{"oneOf": []any{
+		map[string]any{"type": "integer"},
+		map[string]any{"type": "number"},
+	}}
+	assert.NoError(t, ValidateSchema(oneOf, 1.5))
+	assert.Error(t, ValidateSchema(oneOf, 1), "an integer matches both schemas")
+
+	assert.Error(t, ValidateSchema(false, "anything"))
+	assert.NoError(t, ValidateSchema(true, "anything"))
+}
+
+func TestToolOutputSchema(t *testing.T) {
+	tool := NewTool("weather",
+		WithOutputSchema(ToolOutputSchema{
+			Type: "object",
+			Properties: map[string]any{
+				"temperature": map[string]any{"type": "number"},
+			},
+			Required: []string{"temperature"},
+		}),
+	)
+
+	data, err := json.Marshal(tool)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"temperature": map[string]any{"type": "number"}},
+		"required":   []any{"temperature"},
+	}, decoded["outputSchema"])
+
+	var roundTrip Tool
+	require.NoError(t, json.Unmarshal(data, &roundTrip))
+	require.NotNil(t, roundTrip.OutputSchema)
+	assert.Equal(t, []string{"temperature"}, roundTrip.OutputSchema.Required)
+
+	assert.NoError(t, ValidateSchema(tool.OutputSchema, map[string]any{"temperature": 21.5}))
+	assert.Error(t, ValidateSchema(tool.OutputSchema, map[string]any{}))
+
+	// outputSchema is omitted when not set
+	data, err = json.Marshal(NewTool("plain"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "outputSchema")
+}
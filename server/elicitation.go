@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// RequestElicitation asks the user of the current client for additional input,
+// by sending an elicitation/create request with the message to present and the
+// schema of the requested input. It can be called from within a tool handler,
+// and blocks until the client answers or ctx is done.
+//
+// The result reports whether the user accepted, declined or cancelled the
+// request; its Content is only set when the user accepted. The current session
+// must support requests to the client, and the client must have a handler for
+// elicitation requests. Over STDIO, only tool calls run off the read loop, so
+// called from the handler of any other message, RequestElicitation fails
+// with ErrClientResponseBlocked instead of waiting for an unreadable response.
+func (s *MCPServer) RequestElicitation(
+	ctx context.Context,
+	message string,
+	schema mcp.ToolInputSchema,
+) (*mcp.ElicitResult, error) {
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	params := mcp.ElicitParams{
+		Message:         message,
+		RequestedSchema: schema,
+	}
+
+	raw, err := s.sendRequestToClient(ctx, mcp.MethodElicitationCreate, params)
+	if err != nil {
+		return nil, fmt.Errorf("elicitation request failed: %w", err)
+	}
+
+	var result mcp.ElicitResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse elicitation result: %w", err)
+	}
+	if !result.Action.Valid() {
+		return nil, fmt.Errorf("invalid elicitation action: %q", result.Action)
+	}
+	if result.Action != mcp.ElicitationActionAccept {
+		result.Content = nil
+	}
+	return &result, nil
+}
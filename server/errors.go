@@ -7,23 +7,48 @@ import (
 
 var (
 	// Common server errors
-	ErrUnsupported      = errors.New("not supported")
-	ErrResourceNotFound = errors.New("resource not found")
-	ErrPromptNotFound   = errors.New("prompt not found")
-	ErrToolNotFound     = errors.New("tool not found")
+	ErrUnsupported        = errors.New("not supported")
+	ErrResourceNotFound   = errors.New("resource not found")
+	ErrPromptNotFound     = errors.New("prompt not found")
+	ErrToolNotFound       = errors.New("tool not found")
+	ErrServerShuttingDown = errors.New("server is shutting down")
+
+	// Tool-related errors
+	ErrInvalidToolOutput = errors.New("tool output does not match output schema")
 
 	// Session-related errors
-	ErrSessionNotFound              = errors.New("session not found")
-	ErrSessionExists                = errors.New("session already exists")
-	ErrSessionNotInitialized        = errors.New("session not properly initialized")
-	ErrSessionDoesNotSupportTools   = errors.New("session does not support per-session tools")
-	ErrSessionDoesNotSupportLogging = errors.New("session does not support setting logging level")
+	ErrSessionNotFound               = errors.New("session not found")
+	ErrSessionExists                 = errors.New("session already exists")
+	ErrSessionNotInitialized         = errors.New("session not properly initialized")
+	ErrSessionDoesNotSupportTools    = errors.New("session does not support per-session tools")
+	ErrSessionDoesNotSupportLogging  = errors.New("session does not support setting logging level")
+	ErrSessionDoesNotSupportRequests = errors.New("session does not support requests to the client")
+	ErrClientResponseBlocked         = errors.New("response from the client cannot be read on the STDIO read loop: only tool calls can send requests to the client")
+
+	ErrSessionDoesNotSupportResourceSubscriptions = errors.New("session does not support resource subscriptions")
 
 	// Notification-related errors
 	ErrNotificationNotInitialized = errors.New("notification channel not initialized")
 	ErrNotificationChannelBlocked = errors.New("notification channel full or blocked")
 )
 
+// ShutdownError is returned by MCPServer.Shutdown when its context ends before
+// all in-flight requests complete.
+type ShutdownError struct {
+	// Cancelled is the number of in-flight requests that were cancelled.
+	Cancelled int
+	// Err is the error of the context that ended the shutdown.
+	Err error
+}
+
+func (e *ShutdownError) Error() string {
+	return fmt.Sprintf("shutdown: %d in-flight requests cancelled: %v", e.Cancelled, e.Err)
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.Err
+}
+
 // ErrDynamicPathConfig is returned when attempting to use static path methods with dynamic path configuration
 type ErrDynamicPathConfig struct {
 	Method string
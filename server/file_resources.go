@@ -0,0 +1,418 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// ErrPathOutsideRoot is returned when reading a file:// URI that resolves to a
+// path outside the root of a FileResourceProvider.
+var ErrPathOutsideRoot = errors.New("path is outside the root directory")
+
+// FileResourceProvider exposes the files under a directory as resources.
+//
+// Each file is listed as a resource with a file:// URI and its detected MIME
+// type, and can be read in full or by byte range. A resource template covering
+// the whole directory serves files that are not listed yet, such as files
+// created after the last scan. Paths that resolve outside the root, through
+// ".." segments or symbolic links, are never read.
+type FileResourceProvider struct {
+	root     string // absolute, with symbolic links resolved
+	include  []string
+	exclude  []string
+	interval time.Duration
+
+	mu     sync.Mutex
+	files  map[string]fileState // URI -> state at the last scan
+	server *MCPServer
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// fileState is the state of a listed file, used to detect changes.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// FileResourceOption configures a FileResourceProvider.
+type FileResourceOption func(*FileResourceProvider)
+
+// WithFileInclude only exposes the files matching one of the glob patterns, in
+// the syntax of path.Match. Patterns containing a "/" are matched against the
+// slash-separated path relative to the root, others against the file name.
+func WithFileInclude(patterns ...string) FileResourceOption {
+	return func(p *FileResourceProvider) {
+		p.include = append(p.include, patterns...)
+	}
+}
+
+// WithFileExclude hides the files and directories matching one of the glob
+// patterns, matched like the patterns of WithFileInclude. Exclusions take
+// precedence over inclusions.
+func WithFileExclude(patterns ...string) FileResourceOption {
+	return func(p *FileResourceProvider) {
+		p.exclude = append(p.exclude, patterns...)
+	}
+}
+
+// WithFileWatch rescans the directory at the given interval once the provider
+// is registered, updating the listed resources so that the server sends
+// notifications/resources/list_changed when files are added or removed, and
+// notifications/resources/updated to the subscribers of modified files.
+func WithFileWatch(interval time.Duration) FileResourceOption {
+	return func(p *FileResourceProvider) {
+		p.interval = interval
+	}
+}
+
+// NewFileResourceProvider creates a provider for the files under root, which
+// must be a directory. Register it on a server with Register.
+func NewFileResourceProvider(root string, opts ...FileResourceOption) (*FileResourceProvider, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root %q: %w", root, err)
+	}
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root %q: %w", root, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root %q: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("invalid root %q: not a directory", root)
+	}
+
+	p := &FileResourceProvider{
+		root:  abs,
+		files: make(map[string]fileState),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for _, pattern := range append(append([]string{}, p.include...), p.exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return p, nil
+}
+
+// Root returns the absolute path of the root directory.
+func (p *FileResourceProvider) Root() string {
+	return p.root
+}
+
+// Register registers the resource template and the resources of the files
+// under the root on s, and starts watching the directory if WithFileWatch was
+// given. A provider can only be registered once.
+func (p *FileResourceProvider) Register(s *MCPServer) error {
+	p.mu.Lock()
+	if p.server != nil {
+		p.mu.Unlock()
+		return fmt.Errorf("file resource provider is already registered")
+	}
+	p.server = s
+	p.mu.Unlock()
+
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(
+			p.uri(p.root)+"/{+path}",
+			filepath.Base(p.root),
+			mcp.WithTemplateDescription("Files under "+p.root),
+		),
+		p.readTemplate,
+	)
+	if err := p.Rescan(); err != nil {
+		return err
+	}
+
+	if p.interval > 0 {
+		p.mu.Lock()
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go p.watch(p.stop, p.done)
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// Close stops watching the directory. Registered resources are left in place.
+func (p *FileResourceProvider) Close() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop = nil
+	p.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (p *FileResourceProvider) watch(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Errors are transient, e.g. a file removed during the walk, and
+			// are resolved by the next scan
+			_ = p.Rescan()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Rescan walks the directory and updates the resources registered on the
+// server: files that appeared are added, files that disappeared are removed,
+// and subscribers of modified files are notified. It is called periodically
+// with WithFileWatch.
+func (p *FileResourceProvider) Rescan() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.server == nil {
+		return fmt.Errorf("file resource provider is not registered")
+	}
+
+	current := make(map[string]fileState)
+	var added []ServerResource
+	var updated []string
+	err := filepath.WalkDir(p.root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == p.root {
+			return nil
+		}
+		rel := filepath.ToSlash(mustRel(p.root, name))
+		if entry.IsDir() {
+			if p.matches(p.exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !p.allowed(rel) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		uri := p.uri(name)
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		current[uri] = state
+		previous, listed := p.files[uri]
+		if !listed {
+			added = append(added, ServerResource{
+				Resource:     mcp.NewResource(uri, rel, mcp.WithMIMEType(detectMIMEType(name))),
+				RangeHandler: p.readRange,
+			})
+		} else if previous.size != state.size || !previous.modTime.Equal(state.modTime) {
+			updated = append(updated, uri)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", p.root, err)
+	}
+
+	for uri := range p.files {
+		if _, ok := current[uri]; !ok {
+			p.server.RemoveResource(uri)
+		}
+	}
+	if len(added) > 0 {
+		p.server.AddResources(added...)
+	}
+	for _, uri := range updated {
+		p.server.NotifyResourceUpdated(uri)
+	}
+	p.files = current
+	return nil
+}
+
+// allowed reports whether the file with the given relative path passes the
+// include and exclude filters. A file is hidden when it or one of its parent
+// directories is excluded.
+func (p *FileResourceProvider) allowed(rel string) bool {
+	for dir := rel; dir != "."; dir = path.Dir(dir) {
+		if p.matches(p.exclude, dir) {
+			return false
+		}
+	}
+	return len(p.include) == 0 || p.matches(p.include, rel)
+}
+
+// matches reports whether a relative path matches one of the patterns.
+func (p *FileResourceProvider) matches(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		target := rel
+		if !strings.Contains(pattern, "/") {
+			target = path.Base(rel)
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// uri returns the file:// URI of an absolute path.
+func (p *FileResourceProvider) uri(name string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(name)}
+	return u.String()
+}
+
+// resolve returns the path of the file a URI refers to, making sure that it
+// stays within the root and passes the filters.
+func (p *FileResourceProvider) resolve(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("invalid file URI %q", uri)
+	}
+	name := filepath.Clean(filepath.FromSlash(u.Path))
+	// Check the lexical path first, then the path with symbolic links resolved
+	if !withinRoot(p.root, name) {
+		return "", fmt.Errorf("%w: %s", ErrPathOutsideRoot, uri)
+	}
+	resolved, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+		return "", err
+	}
+	if !withinRoot(p.root, resolved) {
+		return "", fmt.Errorf("%w: %s", ErrPathOutsideRoot, uri)
+	}
+	// A symbolic link must not expose a filtered file under another name
+	if !p.allowed(filepath.ToSlash(mustRel(p.root, name))) || !p.allowed(filepath.ToSlash(mustRel(p.root, resolved))) {
+		return "", fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+	}
+	return resolved, nil
+}
+
+func (p *FileResourceProvider) readTemplate(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return p.readRange(ctx, request, mcp.ByteRange{})
+}
+
+func (p *FileResourceProvider) readRange(
+	ctx context.Context,
+	request mcp.ReadResourceRequest,
+	byteRange mcp.ByteRange,
+) ([]mcp.ResourceContents, error) {
+	name, err := p.resolve(request.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, request.Params.URI)
+	}
+
+	if _, err := file.Seek(byteRange.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var reader io.Reader = file
+	if byteRange.Length > 0 {
+		reader = io.LimitReader(file, byteRange.Length)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// A range can cut a multi-byte character, which text contents cannot carry
+	mimeType := detectMIMEType(name)
+	if isTextMIMEType(mimeType) && utf8.Valid(data) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: mimeType,
+			Text:     string(data),
+		}}, nil
+	}
+	return []mcp.ResourceContents{mcp.BlobResourceContents{
+		URI:      request.Params.URI,
+		MIMEType: mimeType,
+		Blob:     base64.StdEncoding.EncodeToString(data),
+	}}, nil
+}
+
+// detectMIMEType returns the MIME type of a file from its extension, or
+// from its first bytes when the extension is unknown.
+func detectMIMEType(name string) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(name)); mimeType != "" {
+		return mimeType
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer file.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	return http.DetectContentType(head[:n])
+}
+
+// isTextMIMEType reports whether contents of the MIME type are returned as text.
+func isTextMIMEType(mimeType string) bool {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-yaml", "application/yaml", "application/toml":
+		return true
+	}
+	return false
+}
+
+// withinRoot reports whether name is root or a path under it.
+func withinRoot(root, name string) bool {
+	rel, err := filepath.Rel(root, name)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// mustRel returns the path of name relative to root, which contains it.
+func mustRel(root, name string) string {
+	rel, err := filepath.Rel(root, name)
+	if err != nil {
+		return name
+	}
+	return rel
+}
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+		require.NoError(t, os.WriteFile(name, []byte(content), 0o644))
+	}
+}
+
+func TestFileResourceProvider(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"README.md":      "# Project",
+		"config.json":    `{"debug":true}`,
+		"logo.png":       "\x89PNG\r\n\x1a\n\x00\x00",
+		"docs/guide.txt": "0123456789",
+		"docs/notes.txt": "héllo",
+		"secret.key":     "secret",
+		".git/config":    "[core]",
+	})
+	outside := t.TempDir()
+	writeTestFiles(t, outside, map[string]string{"passwd": "root"})
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	require.NoError(t, os.Symlink(filepath.Join(root, ".git", "config"), filepath.Join(root, "public.txt")))
+	require.NoError(t, os.Symlink(filepath.Join("..", "secret.key"), filepath.Join(root, "docs", "secret.txt")))
+
+	provider, err := NewFileResourceProvider(root, WithFileExclude(".git", "*.key"))
+	require.NoError(t, err)
+	server := NewMCPServer("test-server", "1.0.0")
+	require.NoError(t, provider.Register(server))
+	uri := func(name string) string {
+		return "file://" + filepath.ToSlash(filepath.Join(provider.Root(), name))
+	}
+
+	read := func(params string) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": `+params+`}`,
+		))
+	}
+	readResult := func(t *testing.T, params string) mcp.ReadResourceResult {
+		t.Helper()
+		message := read(params)
+		response, ok := message.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", message)
+		return response.Result.(mcp.ReadResourceResult)
+	}
+	readURI := func(uri string) string {
+		return fmt.Sprintf(`{"uri": %q}`, uri)
+	}
+
+	t.Run("lists the files passing the filters", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/list"}`))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.ListResourcesResult)
+		mimeTypes := make(map[string]string)
+		for _, resource := range result.Resources {
+			assert.Equal(t, uri(resource.Name), resource.URI)
+			mimeTypes[resource.Name] = resource.MIMEType
+		}
+		assert.Equal(t, map[string]string{
+			"README.md":      "text/markdown; charset=utf-8",
+			"config.json":    "application/json",
+			"logo.png":       "image/png",
+			"docs/guide.txt": "text/plain; charset=utf-8",
+			"docs/notes.txt": "text/plain; charset=utf-8",
+		}, mimeTypes)
+
+		response = server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/templates/list"}`))
+		templates := response.(mcp.JSONRPCResponse).Result.(mcp.ListResourceTemplatesResult)
+		require.Len(t, templates.ResourceTemplates, 1)
+		assert.Equal(t, uri("")+"/{+path}", templates.ResourceTemplates[0].URITemplate.Raw())
+	})
+
+	t.Run("reads text and binary files", func(t *testing.T) {
+		result := readResult(t, readURI(uri("config.json")))
+		text := result.Contents[0].(mcp.TextResourceContents)
+		assert.Equal(t, `{"debug":true}`, text.Text)
+		assert.Equal(t, "application/json", text.MIMEType)
+
+		result = readResult(t, readURI(uri("logo.png")))
+		blob := result.Contents[0].(mcp.BlobResourceContents)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n\x00\x00")), blob.Blob)
+		assert.Equal(t, "image/png", blob.MIMEType)
+	})
+
+	t.Run("reads byte ranges", func(t *testing.T) {
+		result := readResult(t, fmt.Sprintf(`{"uri": %q, "range": {"offset": 2, "length": 3}}`, uri("docs/guide.txt")))
+		assert.Equal(t, &mcp.ByteRange{Offset: 2, Length: 3}, result.Range)
+		assert.Equal(t, "234", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("returns ranges that cut a multi-byte character as blobs", func(t *testing.T) {
+		result := readResult(t, fmt.Sprintf(`{"uri": %q, "range": {"offset": 0, "length": 2}}`, uri("docs/notes.txt")))
+		blob, ok := result.Contents[0].(mcp.BlobResourceContents)
+		require.True(t, ok, "expected blob contents, got %#v", result.Contents[0])
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("h\xc3")), blob.Blob)
+		assert.Equal(t, "text/plain; charset=utf-8", blob.MIMEType)
+
+		result = readResult(t, fmt.Sprintf(`{"uri": %q, "range": {"offset": 0, "length": 3}}`, uri("docs/notes.txt")))
+		assert.Equal(t, "hé", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("reads files created after the scan through the template", func(t *testing.T) {
+		writeTestFiles(t, root, map[string]string{"docs/new.txt": "new"})
+		result := readResult(t, readURI(uri("docs/new.txt")))
+		assert.Equal(t, "new", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("rejects paths outside the root and filtered files", func(t *testing.T) {
+		for _, target := range []string{
+			uri("docs/../../" + filepath.Base(outside) + "/passwd"),
+			uri("docs") + "/%2e%2e/%2e%2e/etc/passwd",
+			uri("escape/passwd"),
+			uri(".git/config"),
+			uri("secret.key"),
+			uri("public.txt"),
+			uri("docs/secret.txt"),
+			uri("missing.txt"),
+		} {
+			response := read(readURI(target))
+			_, ok := response.(mcp.JSONRPCError)
+			assert.True(t, ok, "expected an error for %s, got %#v", target, response)
+		}
+
+		_, err := provider.resolve(uri("escape/passwd"))
+		assert.ErrorIs(t, err, ErrPathOutsideRoot)
+		_, err = provider.resolve(uri("secret.key"))
+		assert.ErrorIs(t, err, ErrResourceNotFound)
+		_, err = provider.resolve(uri("public.txt"))
+		assert.ErrorIs(t, err, ErrResourceNotFound)
+	})
+
+	t.Run("cannot be registered twice", func(t *testing.T) {
+		assert.Error(t, provider.Register(server))
+	})
+}
+
+func TestFileResourceProvider_Watch(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"a.txt": "a"})
+	provider, err := NewFileResourceProvider(root, WithFileInclude("*.txt"), WithFileWatch(10*time.Millisecond))
+	require.NoError(t, err)
+	defer provider.Close()
+
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, true))
+	notifications := make(chan mcp.JSONRPCNotification, 100)
+	session := &sseSession{
+		sessionID:           "session",
+		notificationChannel: notifications,
+	}
+	session.Initialize()
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	require.NoError(t, provider.Register(server))
+	aURI := "file://" + filepath.ToSlash(filepath.Join(provider.Root(), "a.txt"))
+	session.SubscribeResource(aURI)
+
+	listed := func() []string {
+		response := server.HandleMessage(context.Background(), []byte(`{"jsonrpc": "2.0", "id": 1, "method": "resources/list"}`))
+		var names []string
+		for _, resource := range response.(mcp.JSONRPCResponse).Result.(mcp.ListResourcesResult).Resources {
+			names = append(names, resource.Name)
+		}
+		return names
+	}
+	waitFor := func(method string) {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case notification := <-notifications:
+				if notification.Method == method {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("Expected a %s notification", method)
+			}
+		}
+	}
+	drain := func() {
+		for len(notifications) > 0 {
+			<-notifications
+		}
+	}
+	drain()
+
+	writeTestFiles(t, root, map[string]string{"b.txt": "b", "c.md": "c"})
+	waitFor(mcp.MethodNotificationResourcesListChanged)
+	assert.ElementsMatch(t, []string{"a.txt", "b.txt"}, listed())
+
+	drain()
+	require.NoError(t, os.Remove(filepath.Join(root, "b.txt")))
+	waitFor(mcp.MethodNotificationResourcesListChanged)
+	assert.ElementsMatch(t, []string{"a.txt"}, listed())
+
+	drain()
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("changed"), 0o644))
+	require.NoError(t, os.Chtimes(filepath.Join(root, "a.txt"), later, later))
+	waitFor(mcp.MethodNotificationResourceUpdated)
+}
+
+func TestNewFileResourceProvider_Errors(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"file.txt": "x"})
+
+	_, err := NewFileResourceProvider(filepath.Join(root, "missing"))
+	assert.Error(t, err)
+	_, err = NewFileResourceProvider(filepath.Join(root, "file.txt"))
+	assert.Error(t, err)
+	_, err = NewFileResourceProvider(root, WithFileInclude("["))
+	assert.Error(t, err)
+}
@@ -79,12 +79,21 @@ type OnAfterListResourceTemplatesFunc func(ctx context.Context, id any, message
 type OnBeforeReadResourceFunc func(ctx context.Context, id any, message *mcp.ReadResourceRequest)
 type OnAfterReadResourceFunc func(ctx context.Context, id any, message *mcp.ReadResourceRequest, result *mcp.ReadResourceResult)
 
+type OnBeforeSubscribeFunc func(ctx context.Context, id any, message *mcp.SubscribeRequest)
+type OnAfterSubscribeFunc func(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult)
+
+type OnBeforeUnsubscribeFunc func(ctx context.Context, id any, message *mcp.UnsubscribeRequest)
+type OnAfterUnsubscribeFunc func(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult)
+
 type OnBeforeListPromptsFunc func(ctx context.Context, id any, message *mcp.ListPromptsRequest)
 type OnAfterListPromptsFunc func(ctx context.Context, id any, message *mcp.ListPromptsRequest, result *mcp.ListPromptsResult)
 
 type OnBeforeGetPromptFunc func(ctx context.Context, id any, message *mcp.GetPromptRequest)
 type OnAfterGetPromptFunc func(ctx context.Context, id any, message *mcp.GetPromptRequest, result *mcp.GetPromptResult)
 
+type OnBeforeCompleteFunc func(ctx context.Context, id any, message *mcp.CompleteRequest)
+type OnAfterCompleteFunc func(ctx context.Context, id any, message *mcp.CompleteRequest, result *mcp.CompleteResult)
+
 type OnBeforeListToolsFunc func(ctx context.Context, id any, message *mcp.ListToolsRequest)
 type OnAfterListToolsFunc func(ctx context.Context, id any, message *mcp.ListToolsRequest, result *mcp.ListToolsResult)
 
@@ -110,10 +119,16 @@ type Hooks struct {
 	OnAfterListResourceTemplates  []OnAfterListResourceTemplatesFunc
 	OnBeforeReadResource          []OnBeforeReadResourceFunc
 	OnAfterReadResource           []OnAfterReadResourceFunc
+	OnBeforeSubscribe             []OnBeforeSubscribeFunc
+	OnAfterSubscribe              []OnAfterSubscribeFunc
+	OnBeforeUnsubscribe           []OnBeforeUnsubscribeFunc
+	OnAfterUnsubscribe            []OnAfterUnsubscribeFunc
 	OnBeforeListPrompts           []OnBeforeListPromptsFunc
 	OnAfterListPrompts            []OnAfterListPromptsFunc
 	OnBeforeGetPrompt             []OnBeforeGetPromptFunc
 	OnAfterGetPrompt              []OnAfterGetPromptFunc
+	OnBeforeComplete              []OnBeforeCompleteFunc
+	OnAfterComplete               []OnAfterCompleteFunc
 	OnBeforeListTools             []OnBeforeListToolsFunc
 	OnAfterListTools              []OnAfterListToolsFunc
 	OnBeforeCallTool              []OnBeforeCallToolFunc
@@ -422,6 +437,60 @@ func (c *Hooks) afterReadResource(ctx context.Context, id any, message *mcp.Read
 		hook(ctx, id, message, result)
 	}
 }
+func (c *Hooks) AddBeforeSubscribe(hook OnBeforeSubscribeFunc) {
+	c.OnBeforeSubscribe = append(c.OnBeforeSubscribe, hook)
+}
+
+func (c *Hooks) AddAfterSubscribe(hook OnAfterSubscribeFunc) {
+	c.OnAfterSubscribe = append(c.OnAfterSubscribe, hook)
+}
+
+func (c *Hooks) beforeSubscribe(ctx context.Context, id any, message *mcp.SubscribeRequest) {
+	c.beforeAny(ctx, id, mcp.MethodResourcesSubscribe, message)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnBeforeSubscribe {
+		hook(ctx, id, message)
+	}
+}
+
+func (c *Hooks) afterSubscribe(ctx context.Context, id any, message *mcp.SubscribeRequest, result *mcp.EmptyResult) {
+	c.onSuccess(ctx, id, mcp.MethodResourcesSubscribe, message, result)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnAfterSubscribe {
+		hook(ctx, id, message, result)
+	}
+}
+func (c *Hooks) AddBeforeUnsubscribe(hook OnBeforeUnsubscribeFunc) {
+	c.OnBeforeUnsubscribe = append(c.OnBeforeUnsubscribe, hook)
+}
+
+func (c *Hooks) AddAfterUnsubscribe(hook OnAfterUnsubscribeFunc) {
+	c.OnAfterUnsubscribe = append(c.OnAfterUnsubscribe, hook)
+}
+
+func (c *Hooks) beforeUnsubscribe(ctx context.Context, id any, message *mcp.UnsubscribeRequest) {
+	c.beforeAny(ctx, id, mcp.MethodResourcesUnsubscribe, message)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnBeforeUnsubscribe {
+		hook(ctx, id, message)
+	}
+}
+
+func (c *Hooks) afterUnsubscribe(ctx context.Context, id any, message *mcp.UnsubscribeRequest, result *mcp.EmptyResult) {
+	c.onSuccess(ctx, id, mcp.MethodResourcesUnsubscribe, message, result)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnAfterUnsubscribe {
+		hook(ctx, id, message, result)
+	}
+}
 func (c *Hooks) AddBeforeListPrompts(hook OnBeforeListPromptsFunc) {
 	c.OnBeforeListPrompts = append(c.OnBeforeListPrompts, hook)
 }
@@ -476,6 +545,33 @@ func (c *Hooks) afterGetPrompt(ctx context.Context, id any, message *mcp.GetProm
 		hook(ctx, id, message, result)
 	}
 }
+func (c *Hooks) AddBeforeComplete(hook OnBeforeCompleteFunc) {
+	c.OnBeforeComplete = append(c.OnBeforeComplete, hook)
+}
+
+func (c *Hooks) AddAfterComplete(hook OnAfterCompleteFunc) {
+	c.OnAfterComplete = append(c.OnAfterComplete, hook)
+}
+
+func (c *Hooks) beforeComplete(ctx context.Context, id any, message *mcp.CompleteRequest) {
+	c.beforeAny(ctx, id, mcp.MethodCompletionComplete, message)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnBeforeComplete {
+		hook(ctx, id, message)
+	}
+}
+
+func (c *Hooks) afterComplete(ctx context.Context, id any, message *mcp.CompleteRequest, result *mcp.CompleteResult) {
+	c.onSuccess(ctx, id, mcp.MethodCompletionComplete, message, result)
+	if c == nil {
+		return
+	}
+	for _, hook := range c.OnAfterComplete {
+		hook(ctx, id, message, result)
+	}
+}
 func (c *Hooks) AddBeforeListTools(hook OnBeforeListToolsFunc) {
 	c.OnBeforeListTools = append(c.OnBeforeListTools, hook)
 }
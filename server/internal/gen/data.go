@@ -67,6 +67,26 @@ var MCPRequestTypes = []MCPRequestType{
 		HookName:       "ReadResource",
 		UnmarshalError: "invalid read resource request",
 		HandlerFunc:    "handleReadResource",
+	}, {
+		MethodName:     "MethodResourcesSubscribe",
+		ParamType:      "SubscribeRequest",
+		ResultType:     "EmptyResult",
+		Group:          "resources",
+		GroupName:      "Resources",
+		GroupHookName:  "Resource",
+		HookName:       "Subscribe",
+		UnmarshalError: "invalid subscribe request",
+		HandlerFunc:    "handleSubscribe",
+	}, {
+		MethodName:     "MethodResourcesUnsubscribe",
+		ParamType:      "UnsubscribeRequest",
+		ResultType:     "EmptyResult",
+		Group:          "resources",
+		GroupName:      "Resources",
+		GroupHookName:  "Resource",
+		HookName:       "Unsubscribe",
+		UnmarshalError: "invalid unsubscribe request",
+		HandlerFunc:    "handleUnsubscribe",
 	}, {
 		MethodName:     "MethodPromptsList",
 		ParamType:      "ListPromptsRequest",
@@ -87,6 +107,16 @@ var MCPRequestTypes = []MCPRequestType{
 		HookName:       "GetPrompt",
 		UnmarshalError: "invalid get prompt request",
 		HandlerFunc:    "handleGetPrompt",
+	}, {
+		MethodName:     "MethodCompletionComplete",
+		ParamType:      "CompleteRequest",
+		ResultType:     "CompleteResult",
+		Group:          "completions",
+		GroupName:      "Completions",
+		GroupHookName:  "Completion",
+		HookName:       "Complete",
+		UnmarshalError: "invalid complete request",
+		HandlerFunc:    "handleComplete",
 	}, {
 		MethodName:     "MethodToolsList",
 		ParamType:      "ListToolsRequest",
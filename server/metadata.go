@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mathiasXie/mcp-go/internal/metadata"
+)
+
+// requestMetadataKey is the context key for storing the _meta of the request being handled
+type requestMetadataKey struct{}
+
+// MetadataFromContext returns the metadata of the request being handled, i.e.
+// the fields of its params._meta object, such as a trace ID set by the client
+// with client.ContextWithMetadata. It returns nil outside of a request handler or if
+// the request has no metadata.
+//
+// Over networked transports, values are decoded from JSON, so numbers are
+// float64 and objects are map[string]any. In-process clients pass the values
+// unchanged.
+func MetadataFromContext(ctx context.Context) map[string]any {
+	values, _ := ctx.Value(requestMetadataKey{}).(map[string]any)
+	return values
+}
+
+// withRequestMetadata adds the _meta of the request params to the context.
+// Values passed by an in-process client replace the decoded ones.
+func withRequestMetadata(ctx context.Context, params json.RawMessage) context.Context {
+	if len(params) == 0 {
+		return ctx
+	}
+	var request struct {
+		Meta map[string]any `json:"_meta"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil || len(request.Meta) == 0 {
+		return ctx
+	}
+	if passed := metadata.ValuesFromContext(ctx); passed != nil {
+		for key := range request.Meta {
+			if value, ok := passed[key]; ok {
+				request.Meta[key] = value
+			}
+		}
+	}
+	return context.WithValue(ctx, requestMetadataKey{}, request.Meta)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mathiasXie/mcp-go/internal/metadata"
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestMCPServer_MetadataFromContext(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(true))
+	var received map[string]any
+	server.AddTool(mcp.NewTool("traced"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		received = MetadataFromContext(ctx)
+		return mcp.NewToolResultText("ok"), nil
+	})
+	call := func(ctx context.Context, params string) {
+		t.Helper()
+		response := server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": `+params+`}`))
+		_, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+	}
+
+	call(context.Background(), `{"name": "traced", "_meta": {"traceId": "abc", "attempt": 2}}`)
+	assert.Equal(t, map[string]any{"traceId": "abc", "attempt": float64(2)}, received)
+
+	call(context.Background(), `{"name": "traced"}`)
+	assert.Nil(t, received)
+
+	// Passed values replace the decoded ones, only for keys sent in _meta
+	ctx := metadata.ContextWithValues(context.Background(), map[string]any{"attempt": 2, "other": "x"})
+	call(ctx, `{"name": "traced", "_meta": {"traceId": "abc", "attempt": 2}}`)
+	assert.Equal(t, map[string]any{"traceId": "abc", "attempt": 2}, received)
+
+	assert.Nil(t, MetadataFromContext(context.Background()))
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/mathiasXie/mcp-go/mcp"
 )
@@ -20,10 +21,12 @@ func (s *MCPServer) HandleMessage(
 	var err *requestError
 
 	var baseMessage struct {
-		JSONRPC string        `json:"jsonrpc"`
-		Method  mcp.MCPMethod `json:"method"`
-		ID      any           `json:"id,omitempty"`
-		Result  any           `json:"result,omitempty"`
+		JSONRPC string          `json:"jsonrpc"`
+		Method  mcp.MCPMethod   `json:"method"`
+		ID      any             `json:"id,omitempty"`
+		Result  any             `json:"result,omitempty"`
+		Error   any             `json:"error,omitempty"`
+		Params  json.RawMessage `json:"params,omitempty"`
 	}
 
 	if err := json.Unmarshal(message, &baseMessage); err != nil {
@@ -56,19 +59,49 @@ func (s *MCPServer) HandleMessage(
 		return nil // Return nil for notifications
 	}
 
-	if baseMessage.Result != nil {
+	if baseMessage.Result != nil || baseMessage.Error != nil {
 		// this is a response to a request sent by the server (e.g. from a ping
-		// sent due to WithKeepAlive option)
+		// sent due to WithKeepAlive option, or an elicitation request)
+		s.handleClientResponse(ctx, baseMessage.ID, message)
 		return nil
 	}
 
-	handleErr := s.hooks.onRequestInitialization(ctx, baseMessage.ID, message)
-	if handleErr != nil {
-		return createErrorResponse(
-			baseMessage.ID,
-			mcp.INVALID_REQUEST,
-			handleErr.Error(),
-		)
+	ctx = context.WithValue(ctx, requestIDKey{}, mcp.NewRequestId(baseMessage.ID))
+	ctx = withRequestMetadata(ctx, baseMessage.Params)
+	if len(s.requestObservers) > 0 {
+		observeCtx, start := ctx, time.Now()
+		defer func() {
+			s.observeRequest(observeCtx, baseMessage.Method, start, err)
+		}()
+	}
+
+	ctx, done, ok := s.trackRequest(ctx, baseMessage.ID)
+	if !ok {
+		err = &requestError{
+			id:   baseMessage.ID,
+			code: mcp.INTERNAL_ERROR,
+			err:  ErrServerShuttingDown,
+		}
+		return err.ToJSONRPCError()
+	}
+	defer done()
+
+	if handleErr := s.hooks.onRequestInitialization(ctx, baseMessage.ID, message); handleErr != nil {
+		err = &requestError{
+			id:   baseMessage.ID,
+			code: mcp.INVALID_REQUEST,
+			err:  handleErr,
+		}
+		return err.ToJSONRPCError()
+	}
+
+	if handshakeErr := s.checkHandshake(ctx, baseMessage.Method); handshakeErr != nil {
+		err = &requestError{
+			id:   baseMessage.ID,
+			code: mcp.INVALID_REQUEST,
+			err:  handshakeErr,
+		}
+		return err.ToJSONRPCError()
 	}
 
 	switch baseMessage.Method {
@@ -210,6 +243,56 @@ func (s *MCPServer) HandleMessage(
 		}
 		s.hooks.afterReadResource(ctx, baseMessage.ID, &request, result)
 		return createResponse(baseMessage.ID, *result)
+	case mcp.MethodResourcesSubscribe:
+		var request mcp.SubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+			}
+		} else {
+			s.hooks.beforeSubscribe(ctx, baseMessage.ID, &request)
+			result, err = s.handleSubscribe(ctx, baseMessage.ID, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterSubscribe(ctx, baseMessage.ID, &request, result)
+		return createResponse(baseMessage.ID, *result)
+	case mcp.MethodResourcesUnsubscribe:
+		var request mcp.UnsubscribeRequest
+		var result *mcp.EmptyResult
+		if s.capabilities.resources == nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("resources %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+			}
+		} else {
+			s.hooks.beforeUnsubscribe(ctx, baseMessage.ID, &request)
+			result, err = s.handleUnsubscribe(ctx, baseMessage.ID, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterUnsubscribe(ctx, baseMessage.ID, &request, result)
+		return createResponse(baseMessage.ID, *result)
 	case mcp.MethodPromptsList:
 		var request mcp.ListPromptsRequest
 		var result *mcp.ListPromptsResult
@@ -260,6 +343,31 @@ func (s *MCPServer) HandleMessage(
 		}
 		s.hooks.afterGetPrompt(ctx, baseMessage.ID, &request, result)
 		return createResponse(baseMessage.ID, *result)
+	case mcp.MethodCompletionComplete:
+		var request mcp.CompleteRequest
+		var result *mcp.CompleteResult
+		if s.capabilities.completions == nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.METHOD_NOT_FOUND,
+				err:  fmt.Errorf("completions %w", ErrUnsupported),
+			}
+		} else if unmarshalErr := json.Unmarshal(message, &request); unmarshalErr != nil {
+			err = &requestError{
+				id:   baseMessage.ID,
+				code: mcp.INVALID_REQUEST,
+				err:  &UnparsableMessageError{message: message, err: unmarshalErr, method: baseMessage.Method},
+			}
+		} else {
+			s.hooks.beforeComplete(ctx, baseMessage.ID, &request)
+			result, err = s.handleComplete(ctx, baseMessage.ID, request)
+		}
+		if err != nil {
+			s.hooks.onError(ctx, baseMessage.ID, baseMessage.Method, &request, err)
+			return err.ToJSONRPCError()
+		}
+		s.hooks.afterComplete(ctx, baseMessage.ID, &request, result)
+		return createResponse(baseMessage.ID, *result)
 	case mcp.MethodToolsList:
 		var request mcp.ListToolsRequest
 		var result *mcp.ListToolsResult
@@ -311,10 +419,11 @@ func (s *MCPServer) HandleMessage(
 		s.hooks.afterCallTool(ctx, baseMessage.ID, &request, result)
 		return createResponse(baseMessage.ID, *result)
 	default:
-		return createErrorResponse(
-			baseMessage.ID,
-			mcp.METHOD_NOT_FOUND,
-			fmt.Sprintf("Method %s not found", baseMessage.Method),
-		)
+		err = &requestError{
+			id:   baseMessage.ID,
+			code: mcp.METHOD_NOT_FOUND,
+			err:  fmt.Errorf("Method %s not found", baseMessage.Method),
+		}
+		return err.ToJSONRPCError()
 	}
 }
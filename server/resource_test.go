@@ -251,3 +251,133 @@ func TestMCPServer_RemoveResource(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServer_ResourceSubscriptions(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(true, false))
+
+	newSession := func(id string) *sseSession {
+		session := &sseSession{
+			sessionID:           id,
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		}
+		session.Initialize()
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		return session
+	}
+	subscribed := newSession("subscribed")
+	other := newSession("other")
+
+	call := func(session ClientSession, method, uri string) mcp.JSONRPCMessage {
+		ctx := server.WithContext(context.Background(), session)
+		return server.HandleMessage(ctx, []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "`+method+`",
+			"params": {"uri": "`+uri+`"}
+		}`))
+	}
+
+	response := call(subscribed, "resources/subscribe", "test://resource")
+	_, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected response, got %#v", response)
+
+	server.NotifyResourceUpdated("test://resource")
+	server.NotifyResourceUpdated("test://unrelated")
+
+	select {
+	case notification := <-subscribed.notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationResourceUpdated, notification.Method)
+		assert.Equal(t, "test://resource", notification.Params.AdditionalFields["uri"])
+	case <-time.After(time.Second):
+		t.Fatal("Expected resource updated notification")
+	}
+	assert.Len(t, subscribed.notificationChannel, 0, "only the subscribed resource should notify")
+	assert.Len(t, other.notificationChannel, 0, "unsubscribed sessions should not be notified")
+
+	// After unsubscribing, no more updates
+	call(subscribed, "resources/unsubscribe", "test://resource")
+	server.NotifyResourceUpdated("test://resource")
+	assert.Len(t, subscribed.notificationChannel, 0)
+
+	// Disconnected sessions are not notified
+	call(other, "resources/subscribe", "test://resource")
+	server.UnregisterSession(context.Background(), other.SessionID())
+	server.NotifyResourceUpdated("test://resource")
+	assert.Len(t, other.notificationChannel, 0)
+}
+
+func TestMCPServer_ResourceSubscriptionsNotSupported(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithResourceCapabilities(false, false))
+	session := &sseSession{
+		sessionID:           "session",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+	}
+	session.Initialize()
+	ctx := server.WithContext(context.Background(), session)
+
+	response := server.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "resources/subscribe",
+		"params": {"uri": "test://resource"}
+	}`))
+	errorResponse, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok, "expected error response, got %#v", response)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, errorResponse.Error.Code)
+	assert.False(t, session.IsSubscribedToResource("test://resource"))
+}
+
+func TestMCPServer_RangeResource(t *testing.T) {
+	data := []byte("0123456789")
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddRangeResource(
+		mcp.NewResource("blob://data", "data"),
+		func(ctx context.Context, request mcp.ReadResourceRequest, byteRange mcp.ByteRange) ([]mcp.ResourceContents, error) {
+			start := min(byteRange.Offset, int64(len(data)))
+			end := int64(len(data))
+			if byteRange.Length > 0 {
+				end = min(start+byteRange.Length, end)
+			}
+			return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: string(data[start:end])}}, nil
+		},
+	)
+	server.AddResource(mcp.NewResource("text://full", "full"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "full contents"}}, nil
+	})
+
+	read := func(params string) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": `+params+`}`,
+		))
+	}
+	readResult := func(t *testing.T, params string) mcp.ReadResourceResult {
+		t.Helper()
+		response, ok := read(params).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		return response.Result.(mcp.ReadResourceResult)
+	}
+
+	t.Run("serves the requested range", func(t *testing.T) {
+		result := readResult(t, `{"uri": "blob://data", "range": {"offset": 2, "length": 3}}`)
+		assert.Equal(t, &mcp.ByteRange{Offset: 2, Length: 3}, result.Range)
+		assert.Equal(t, "234", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("reads the whole range resource without a range", func(t *testing.T) {
+		result := readResult(t, `{"uri": "blob://data"}`)
+		assert.Nil(t, result.Range)
+		assert.Equal(t, "0123456789", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("returns the full contents of resources without range support", func(t *testing.T) {
+		result := readResult(t, `{"uri": "text://full", "range": {"offset": 2, "length": 3}}`)
+		assert.Nil(t, result.Range)
+		assert.Equal(t, "full contents", result.Contents[0].(mcp.TextResourceContents).Text)
+	})
+
+	t.Run("rejects negative ranges", func(t *testing.T) {
+		response, ok := read(`{"uri": "blob://data", "range": {"offset": -1}}`).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INVALID_PARAMS, response.Error.Code)
+	})
+}
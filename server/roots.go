@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+// rootsRequestTimeout bounds how long the server waits for the client to
+// answer a roots/list request sent to refresh the cached roots.
+const rootsRequestTimeout = 30 * time.Second
+
+// sessionRoots caches the roots listed by the client of a session. The
+// generation is bumped by every refresh so that the answer to an outdated
+// request does not replace the answer to a newer one.
+type sessionRoots struct {
+	mu         sync.Mutex
+	roots      []mcp.Root
+	generation uint64
+	cancel     context.CancelFunc
+	pending    bool // a refresh waits for the session to be registered
+}
+
+// RequestRoots asks the current client for its filesystem roots by sending a
+// roots/list request. It blocks until the client answers or ctx is done. Like
+// RequestElicitation, it fails with ErrClientResponseBlocked over STDIO when
+// called from the handler of a message other than a tool call.
+//
+// Most handlers should use CurrentRoots instead, which returns the roots the
+// server keeps up to date for clients that advertise the roots capability.
+func (s *MCPServer) RequestRoots(ctx context.Context) ([]mcp.Root, error) {
+	raw, err := s.sendRequestToClient(ctx, mcp.MethodRootsList, nil)
+	if err != nil {
+		return nil, fmt.Errorf("roots request failed: %w", err)
+	}
+
+	var result mcp.ListRootsResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse roots result: %w", err)
+	}
+	if result.Roots == nil {
+		result.Roots = []mcp.Root{}
+	}
+	return result.Roots, nil
+}
+
+// CurrentRoots returns the last roots listed by the client of the session, so
+// that tool handlers can, for instance, check that a path is within them.
+//
+// For clients that advertise the roots capability, the server lists the roots
+// once the client sends notifications/initialized, and again whenever it
+// sends notifications/roots/list_changed. The roots/list request is sent on
+// the registered session: over StreamableHTTP, the listing waits until the
+// client opens its GET stream. CurrentRoots never waits for the
+// client: until it answers, or if it never does, the result is empty. It is
+// also empty for unknown sessions and clients without roots support.
+func (s *MCPServer) CurrentRoots(sessionID string) []mcp.Root {
+	value, ok := s.sessionRoots.Load(sessionID)
+	if !ok {
+		return []mcp.Root{}
+	}
+	cache := value.(*sessionRoots)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	roots := slices.Clone(cache.roots)
+	if roots == nil {
+		roots = []mcp.Root{}
+	}
+	return roots
+}
+
+// trackRoots starts caching the roots of the session if the client supports them.
+func (s *MCPServer) trackRoots(session ClientSession, capabilities mcp.ClientCapabilities) {
+	if capabilities.Roots == nil || session.SessionID() == "" {
+		return
+	}
+	s.sessionRoots.LoadOrStore(session.SessionID(), &sessionRoots{})
+}
+
+// refreshRoots lists the roots of the client of the current session in the
+// background, replacing the cached roots once it answers. A refresh in flight
+// is cancelled, since its answer may already be outdated.
+func (s *MCPServer) refreshRoots(ctx context.Context) {
+	if session := ClientSessionFromContext(ctx); session != nil {
+		s.refreshSessionRoots(session.SessionID())
+	}
+}
+
+// resumeRootsRefresh runs the refresh that waited for the session to be registered.
+func (s *MCPServer) resumeRootsRefresh(sessionID string) {
+	value, ok := s.sessionRoots.Load(sessionID)
+	if !ok {
+		return
+	}
+	cache := value.(*sessionRoots)
+	cache.mu.Lock()
+	pending := cache.pending
+	cache.mu.Unlock()
+	if pending {
+		s.refreshSessionRoots(sessionID)
+	}
+}
+
+// refreshSessionRoots lists the roots of the client of the session in the background.
+func (s *MCPServer) refreshSessionRoots(sessionID string) {
+	value, ok := s.sessionRoots.Load(sessionID)
+	if !ok {
+		return
+	}
+	cache := value.(*sessionRoots)
+
+	// The request is sent on the registered session, the one the client listens
+	// on. Over StreamableHTTP, the session of the POST request that triggered
+	// the refresh ends with it; the GET stream registers its session later.
+	registered, ok := s.sessions.Load(sessionID)
+	if !ok {
+		cache.mu.Lock()
+		cache.pending = true
+		cache.mu.Unlock()
+		return
+	}
+	session := registered.(ClientSession)
+
+	// The request must not end with the message that triggered it
+	requestCtx, cancel := context.WithTimeout(s.WithContext(context.Background(), session), rootsRequestTimeout)
+	cache.mu.Lock()
+	if cache.cancel != nil {
+		cache.cancel()
+	}
+	cache.generation++
+	generation := cache.generation
+	cache.cancel = cancel
+	cache.pending = false
+	cache.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		roots, err := s.RequestRoots(requestCtx)
+
+		cache.mu.Lock()
+		current := cache.generation == generation
+		if current {
+			cache.cancel = nil
+			if err == nil {
+				cache.roots = roots
+			}
+		}
+		cache.mu.Unlock()
+
+		// On failure, the last known roots are kept
+		if current && err != nil {
+			s.hooks.onError(requestCtx, nil, mcp.MethodRootsList, map[string]any{
+				"sessionID": session.SessionID(),
+			}, err)
+		}
+	}()
+}
+
+// forgetRoots cancels any refresh in flight and drops the cached roots of the session.
+func (s *MCPServer) forgetRoots(sessionID string) {
+	value, ok := s.sessionRoots.LoadAndDelete(sessionID)
+	if !ok {
+		return
+	}
+	cache := value.(*sessionRoots)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.generation++
+	if cache.cancel != nil {
+		cache.cancel()
+		cache.cancel = nil
+	}
+}
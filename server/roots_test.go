@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mathiasXie/mcp-go/mcp"
+)
+
+func TestMCPServer_CurrentRoots(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &sessionTestClientWithRequests{
+		sessionTestClient: sessionTestClient{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		},
+		requestChannel: make(chan mcp.JSONRPCRequest, 10),
+	}
+	ctx := server.WithContext(context.Background(), session)
+	require.NoError(t, server.RegisterSession(ctx, session))
+
+	// nextRequest returns the next roots/list request sent to the client
+	nextRequest := func(t *testing.T) mcp.JSONRPCRequest {
+		t.Helper()
+		select {
+		case request := <-session.requestChannel:
+			assert.Equal(t, string(mcp.MethodRootsList), request.Method)
+			return request
+		case <-time.After(time.Second):
+			t.Fatal("Expected a roots/list request")
+			return mcp.JSONRPCRequest{}
+		}
+	}
+	answer := func(t *testing.T, request mcp.JSONRPCRequest, roots ...mcp.Root) {
+		t.Helper()
+		id, err := json.Marshal(request.ID)
+		require.NoError(t, err)
+		result, err := json.Marshal(mcp.ListRootsResult{Roots: roots})
+		require.NoError(t, err)
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"result":%s}`, id, result)
+		assert.Nil(t, server.HandleMessage(ctx, []byte(message)))
+	}
+	notify := func(method string) {
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","method":%q}`, method)
+		assert.Nil(t, server.HandleMessage(ctx, []byte(message)))
+	}
+	eventuallyRoots := func(t *testing.T, want ...mcp.Root) {
+		t.Helper()
+		assert.Eventually(t, func() bool {
+			return assert.ObjectsAreEqual(want, server.CurrentRoots("session-1"))
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{"roots":{"listChanged":true}}}}`
+	require.IsType(t, mcp.JSONRPCResponse{}, server.HandleMessage(ctx, []byte(initialize)))
+	assert.Equal(t, []mcp.Root{}, server.CurrentRoots("session-1"))
+
+	// The roots are listed once the client is initialized, without blocking
+	notify(mcp.MethodNotificationInitialized)
+	request := nextRequest(t)
+	assert.Equal(t, []mcp.Root{}, server.CurrentRoots("session-1"))
+	project := mcp.Root{URI: "file:///project", Name: "project"}
+	answer(t, request, project)
+	eventuallyRoots(t, project)
+
+	// A change triggers a new listing
+	notify(mcp.MethodNotificationRootsListChanged)
+	other := mcp.Root{URI: "file:///other"}
+	answer(t, nextRequest(t), project, other)
+	eventuallyRoots(t, project, other)
+
+	// The answer to an outdated listing is ignored
+	notify(mcp.MethodNotificationRootsListChanged)
+	outdated := nextRequest(t)
+	notify(mcp.MethodNotificationRootsListChanged)
+	latest := nextRequest(t)
+	answer(t, outdated, mcp.Root{URI: "file:///outdated"})
+	answer(t, latest, other)
+	eventuallyRoots(t, other)
+
+	server.UnregisterSession(context.Background(), "session-1")
+	assert.Equal(t, []mcp.Root{}, server.CurrentRoots("session-1"))
+}
+
+func TestMCPServer_CurrentRootsWithoutRootsSupport(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	session := &sessionTestClientWithRequests{
+		sessionTestClient: sessionTestClient{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		},
+		requestChannel: make(chan mcp.JSONRPCRequest, 10),
+	}
+	ctx := server.WithContext(context.Background(), session)
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`
+	require.IsType(t, mcp.JSONRPCResponse{}, server.HandleMessage(ctx, []byte(initialize)))
+	assert.Nil(t, server.HandleMessage(ctx, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+
+	select {
+	case request := <-session.requestChannel:
+		t.Fatalf("Unexpected request to a client without roots support: %s", request.Method)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, []mcp.Root{}, server.CurrentRoots("session-1"))
+	assert.Equal(t, []mcp.Root{}, server.CurrentRoots("unknown"))
+}
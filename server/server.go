@@ -5,18 +5,24 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"regexp"
 	"slices"
 	"sort"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/mathiasXie/mcp-go/mcp"
 )
 
 // resourceEntry holds both a resource and its handler
 type resourceEntry struct {
-	resource mcp.Resource
-	handler  ResourceHandlerFunc
+	resource     mcp.Resource
+	handler      ResourceHandlerFunc
+	rangeHandler ResourceRangeHandlerFunc
 }
 
 // resourceTemplateEntry holds both a template and its handler
@@ -31,6 +37,12 @@ type ServerOption func(*MCPServer)
 // ResourceHandlerFunc is a function that returns resource contents.
 type ResourceHandlerFunc func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
 
+// ResourceRangeHandlerFunc is a function that returns a slice of a resource's
+// contents. The contents hold at most byteRange.Length bytes of the resource
+// starting at byteRange.Offset, fewer if the resource ends first; a Length of
+// zero reaches the end of the resource.
+type ResourceRangeHandlerFunc func(ctx context.Context, request mcp.ReadResourceRequest, byteRange mcp.ByteRange) ([]mcp.ResourceContents, error)
+
 // ResourceTemplateHandlerFunc is a function that returns a resource template.
 type ResourceTemplateHandlerFunc func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
 
@@ -40,6 +52,15 @@ type PromptHandlerFunc func(ctx context.Context, request mcp.GetPromptRequest) (
 // ToolHandlerFunc handles tool calls with given arguments.
 type ToolHandlerFunc func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
 
+// ToolOutputEmitter sends content produced by a streaming tool to the client
+// before the tool call returns.
+type ToolOutputEmitter func(content ...mcp.Content) error
+
+// StreamingToolHandlerFunc handles tool calls that emit incremental output.
+// Content passed to emit is delivered to the client as it is produced; the
+// returned result completes the call.
+type StreamingToolHandlerFunc func(ctx context.Context, request mcp.CallToolRequest, emit ToolOutputEmitter) (*mcp.CallToolResult, error)
+
 // ToolHandlerMiddleware is a middleware function that wraps a ToolHandlerFunc.
 type ToolHandlerMiddleware func(ToolHandlerFunc) ToolHandlerFunc
 
@@ -62,6 +83,9 @@ type ServerPrompt struct {
 type ServerResource struct {
 	Resource mcp.Resource
 	Handler  ResourceHandlerFunc
+	// RangeHandler serves reads that request a byte range. When Handler is
+	// nil, full reads are served by RangeHandler with an empty range.
+	RangeHandler ResourceRangeHandlerFunc
 }
 
 // serverKey is the context key for storing the server instance
@@ -75,6 +99,25 @@ func ServerFromContext(ctx context.Context) *MCPServer {
 	return nil
 }
 
+// requestIDKey is the context key for storing the ID of the request being handled
+type requestIDKey struct{}
+
+// RequestIDFromContext retrieves the JSON-RPC ID of the request being handled
+// from a context. It reports false outside of a request handler.
+func RequestIDFromContext(ctx context.Context) (mcp.RequestId, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(mcp.RequestId)
+	return id, ok
+}
+
+// RequestObserverFunc observes a request handled by the server. It is called
+// once the request completes, with the context of the request, its method,
+// the time handling started and the error answered to the client, if any.
+//
+// The context gives access to the session (ClientSessionFromContext) and the
+// request ID (RequestIDFromContext), which is enough to build spans and
+// metrics without a dependency on a specific instrumentation library.
+type RequestObserverFunc func(ctx context.Context, method string, start time.Time, err error)
+
 // UnparsableMessageError is attached to the RequestError when json.Unmarshal
 // fails on the request.
 type UnparsableMessageError struct {
@@ -160,6 +203,35 @@ type MCPServer struct {
 	paginationLimit        *int
 	sessions               sync.Map
 	hooks                  *Hooks
+	requestObservers       []RequestObserverFunc
+	strictOutputValidation bool
+	lenientContentEncoding bool
+	strictHandshake        bool
+	toolSlots              chan struct{}
+
+	onSessionConnect    func(sessionID string, request mcp.InitializeRequest)
+	onSessionDisconnect func(sessionID string)
+	connectedSessions   sync.Map // sessionID -> struct{}
+	initializedSessions sync.Map // sessionID -> struct{}, sessions that sent notifications/initialized
+	sessionRoots        sync.Map // sessionID -> *sessionRoots, for clients that support roots
+
+	// Coalescing of list changed notifications
+	listChangedDebounce time.Duration
+	listChangedMu       sync.Mutex
+	listChangedPending  map[string]*time.Timer // method -> pending notification
+
+	// In-flight request tracking for Shutdown
+	requestsMu     sync.Mutex
+	activeRequests map[int64]context.CancelFunc
+	requestKeys    map[requestKey]int64 // JSON-RPC request ID -> active request, for cancellation
+	nextRequestKey int64
+	requestsWG     sync.WaitGroup
+	shuttingDown   bool
+
+	// Requests sent to clients, awaiting their responses
+	clientRequestsMu  sync.Mutex
+	clientRequests    map[requestKey]chan clientResponse
+	nextClientRequest int64
 }
 
 // WithPaginationLimit sets the pagination limit for the server.
@@ -171,10 +243,11 @@ func WithPaginationLimit(limit int) ServerOption {
 
 // serverCapabilities defines the supported features of the MCP server
 type serverCapabilities struct {
-	tools     *toolCapabilities
-	resources *resourceCapabilities
-	prompts   *promptCapabilities
-	logging   *bool
+	tools       *toolCapabilities
+	resources   *resourceCapabilities
+	prompts     *promptCapabilities
+	logging     *bool
+	completions *bool
 }
 
 // resourceCapabilities defines the supported resource-related features
@@ -254,6 +327,26 @@ func WithHooks(hooks *Hooks) ServerOption {
 	}
 }
 
+// WithRequestObserver registers an observer called after every request the
+// server handles, including requests answered with an error. The option may
+// be given several times; observers are called in order.
+func WithRequestObserver(observer RequestObserverFunc) ServerOption {
+	return func(s *MCPServer) {
+		s.requestObservers = append(s.requestObservers, observer)
+	}
+}
+
+// observeRequest calls the request observers for a completed request.
+func (s *MCPServer) observeRequest(ctx context.Context, method mcp.MCPMethod, start time.Time, reqErr *requestError) {
+	var err error
+	if reqErr != nil {
+		err = reqErr
+	}
+	for _, observer := range s.requestObservers {
+		observer(ctx, string(method), start, err)
+	}
+}
+
 // WithPromptCapabilities configures prompt-related server capabilities
 func WithPromptCapabilities(listChanged bool) ServerOption {
 	return func(s *MCPServer) {
@@ -281,6 +374,87 @@ func WithLogging() ServerOption {
 	}
 }
 
+// WithCompletions enables the completions capability, which answers completion/complete
+// requests using the argument completers of prompts and resource templates.
+// It is enabled implicitly when a prompt or resource template with completers is added.
+func WithCompletions() ServerOption {
+	return func(s *MCPServer) {
+		s.capabilities.completions = mcp.ToBoolPtr(true)
+	}
+}
+
+// WithSessionHooks registers functions called when a client session connects and
+// disconnects. onConnect runs once the session has completed initialize, with the
+// client's initialize request. onDisconnect runs exactly once for every connected
+// session, when its transport closes, it is terminated, or the server shuts down.
+// Either function may be nil.
+func WithSessionHooks(
+	onConnect func(sessionID string, request mcp.InitializeRequest),
+	onDisconnect func(sessionID string),
+) ServerOption {
+	return func(s *MCPServer) {
+		s.onSessionConnect = onConnect
+		s.onSessionDisconnect = onDisconnect
+	}
+}
+
+// WithListChangedDebounce coalesces the list changed notifications sent when
+// tools, prompts or resources are added or removed at runtime. The first change
+// schedules a notification that is sent after window; further changes of the
+// same list within the window are covered by it, so a bulk registration results
+// in a single notification per list. By default, a notification is sent for
+// every change.
+func WithListChangedDebounce(window time.Duration) ServerOption {
+	return func(s *MCPServer) {
+		s.listChangedDebounce = window
+	}
+}
+
+// WithMaxConcurrentTools limits the number of tool handlers that run at the same time
+// to n. Transports dispatch tool calls concurrently, so a slow tool does not block
+// other requests of the same session; calls beyond the limit wait for a free slot
+// instead of being rejected. By default, or with n <= 0, there is no limit.
+func WithMaxConcurrentTools(n int) ServerOption {
+	return func(s *MCPServer) {
+		if n <= 0 {
+			s.toolSlots = nil
+			return
+		}
+		s.toolSlots = make(chan struct{}, n)
+	}
+}
+
+// WithStrictOutputValidation enables validation of the structured content returned
+// by tools against their output schema. When enabled, a tool result that does not
+// conform to the tool's output schema is reported to the client as an internal error.
+func WithStrictOutputValidation(strict bool) ServerOption {
+	return func(s *MCPServer) {
+		s.strictOutputValidation = strict
+	}
+}
+
+// WithLenientContentEncoding makes tool results tolerate content items that
+// cannot be encoded, such as annotations with a NaN priority, or text that is
+// not valid UTF-8. Each failed item is replaced by a text item describing the
+// failure, the other items are sent as returned, and the encoding error is
+// logged. By default, results are sent as returned: an item that cannot be
+// encoded fails the whole response, and invalid UTF-8 is silently replaced.
+func WithLenientContentEncoding(lenient bool) ServerOption {
+	return func(s *MCPServer) {
+		s.lenientContentEncoding = lenient
+	}
+}
+
+// WithStrictHandshake rejects requests other than initialize and ping that a session
+// sends before completing the initialization handshake with notifications/initialized,
+// as the specification requires. Sessions without an ID, such as those of a stateless
+// streamable HTTP server, cannot be tracked and are not checked.
+func WithStrictHandshake() ServerOption {
+	return func(s *MCPServer) {
+		s.strictHandshake = true
+	}
+}
+
 // WithInstructions sets the server instructions for the client returned in the initialize response
 func WithInstructions(instructions string) ServerOption {
 	return func(s *MCPServer) {
@@ -302,6 +476,10 @@ func NewMCPServer(
 		name:                 name,
 		version:              version,
 		notificationHandlers: make(map[string]NotificationHandlerFunc),
+		activeRequests:       make(map[int64]context.CancelFunc),
+		requestKeys:          make(map[requestKey]int64),
+		clientRequests:       make(map[requestKey]chan clientResponse),
+		listChangedPending:   make(map[string]*time.Timer),
 		capabilities: serverCapabilities{
 			tools:     nil,
 			resources: nil,
@@ -323,9 +501,17 @@ func (s *MCPServer) AddResources(resources ...ServerResource) {
 
 	s.resourcesMu.Lock()
 	for _, entry := range resources {
+		handler := entry.Handler
+		if handler == nil && entry.RangeHandler != nil {
+			rangeHandler := entry.RangeHandler
+			handler = func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+				return rangeHandler(ctx, request, mcp.ByteRange{})
+			}
+		}
 		s.resources[entry.Resource.URI] = resourceEntry{
-			resource: entry.Resource,
-			handler:  entry.Handler,
+			resource:     entry.Resource,
+			handler:      handler,
+			rangeHandler: entry.RangeHandler,
 		}
 	}
 	s.resourcesMu.Unlock()
@@ -333,7 +519,7 @@ func (s *MCPServer) AddResources(resources ...ServerResource) {
 	// When the list of available resources changes, servers that declared the listChanged capability SHOULD send a notification
 	if s.capabilities.resources.listChanged {
 		// Send notification to all initialized sessions
-		s.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationResourcesListChanged)
 	}
 }
 
@@ -345,6 +531,17 @@ func (s *MCPServer) AddResource(
 	s.AddResources(ServerResource{Resource: resource, Handler: handler})
 }
 
+// AddRangeResource registers a resource whose handler can serve byte ranges of
+// its contents, so that clients can read large resources in slices with
+// Client.ReadResourceRange. Reads without a range call handler with an empty
+// range, which covers the whole resource.
+func (s *MCPServer) AddRangeResource(
+	resource mcp.Resource,
+	handler ResourceRangeHandlerFunc,
+) {
+	s.AddResources(ServerResource{Resource: resource, RangeHandler: handler})
+}
+
 // RemoveResource removes a resource from the server
 func (s *MCPServer) RemoveResource(uri string) {
 	s.resourcesMu.Lock()
@@ -356,7 +553,7 @@ func (s *MCPServer) RemoveResource(uri string) {
 
 	// Send notification to all initialized sessions if listChanged capability is enabled and we actually remove a resource
 	if exists && s.capabilities.resources != nil && s.capabilities.resources.listChanged {
-		s.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationResourcesListChanged)
 	}
 }
 
@@ -366,6 +563,9 @@ func (s *MCPServer) AddResourceTemplate(
 	handler ResourceTemplateHandlerFunc,
 ) {
 	s.implicitlyRegisterResourceCapabilities()
+	if len(template.ArgumentCompleters) > 0 {
+		s.implicitlyRegisterCompletionCapabilities()
+	}
 
 	s.resourcesMu.Lock()
 	s.resourceTemplates[template.URITemplate.Raw()] = resourceTemplateEntry{
@@ -377,7 +577,7 @@ func (s *MCPServer) AddResourceTemplate(
 	// When the list of available resources changes, servers that declared the listChanged capability SHOULD send a notification
 	if s.capabilities.resources.listChanged {
 		// Send notification to all initialized sessions
-		s.SendNotificationToAllClients(mcp.MethodNotificationResourcesListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationResourcesListChanged)
 	}
 }
 
@@ -389,13 +589,16 @@ func (s *MCPServer) AddPrompts(prompts ...ServerPrompt) {
 	for _, entry := range prompts {
 		s.prompts[entry.Prompt.Name] = entry.Prompt
 		s.promptHandlers[entry.Prompt.Name] = entry.Handler
+		if len(entry.Prompt.ArgumentCompleters) > 0 {
+			s.implicitlyRegisterCompletionCapabilities()
+		}
 	}
 	s.promptsMu.Unlock()
 
 	// When the list of available prompts changes, servers that declared the listChanged capability SHOULD send a notification.
 	if s.capabilities.prompts.listChanged {
 		// Send notification to all initialized sessions
-		s.SendNotificationToAllClients(mcp.MethodNotificationPromptsListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationPromptsListChanged)
 	}
 }
 
@@ -420,7 +623,7 @@ func (s *MCPServer) DeletePrompts(names ...string) {
 	// Send notification to all initialized sessions if listChanged capability is enabled, and we actually remove a prompt
 	if exists && s.capabilities.prompts != nil && s.capabilities.prompts.listChanged {
 		// Send notification to all initialized sessions
-		s.SendNotificationToAllClients(mcp.MethodNotificationPromptsListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationPromptsListChanged)
 	}
 }
 
@@ -429,6 +632,59 @@ func (s *MCPServer) AddTool(tool mcp.Tool, handler ToolHandlerFunc) {
 	s.AddTools(ServerTool{Tool: tool, Handler: handler})
 }
 
+// AddStreamingTool registers a tool whose handler emits incremental output.
+//
+// When the request carries a progress token, emitted content is sent to the
+// client as notifications/tools/output notifications for that token, which
+// Client.CallToolStream turns into chunks. Otherwise, or when the request has
+// no session to notify, the emitted content is prepended to the content of
+// the result. Once the request context is done, emit returns its error.
+func (s *MCPServer) AddStreamingTool(tool mcp.Tool, handler StreamingToolHandlerFunc) {
+	s.AddTool(tool, streamingToolHandler(handler))
+}
+
+// streamingToolHandler adapts a StreamingToolHandlerFunc to a ToolHandlerFunc.
+func streamingToolHandler(handler StreamingToolHandlerFunc) ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var token mcp.ProgressToken
+		if request.Params.Meta != nil {
+			token = request.Params.Meta.ProgressToken
+		}
+		server := ServerFromContext(ctx)
+		stream := token != nil && server != nil && ClientSessionFromContext(ctx) != nil
+
+		var mu sync.Mutex
+		var buffered []mcp.Content
+		emit := func(content ...mcp.Content) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if len(content) == 0 {
+				return nil
+			}
+			if !stream {
+				mu.Lock()
+				buffered = append(buffered, content...)
+				mu.Unlock()
+				return nil
+			}
+			return server.SendNotificationToClient(ctx, mcp.MethodNotificationToolOutput, map[string]any{
+				"progressToken": token,
+				"content":       content,
+			})
+		}
+
+		result, err := handler(ctx, request, emit)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil || result == nil || len(buffered) == 0 {
+			return result, err
+		}
+		result.Content = append(buffered, result.Content...)
+		return result, nil
+	}
+}
+
 // Register tool capabilities due to a tool being added.  Default to
 // listChanged: true, but don't change the value if we've already explicitly
 // registered tools.listChanged false.
@@ -453,6 +709,13 @@ func (s *MCPServer) implicitlyRegisterPromptCapabilities() {
 	)
 }
 
+func (s *MCPServer) implicitlyRegisterCompletionCapabilities() {
+	s.implicitlyRegisterCapabilities(
+		func() bool { return s.capabilities.completions != nil },
+		func() { s.capabilities.completions = mcp.ToBoolPtr(true) },
+	)
+}
+
 func (s *MCPServer) implicitlyRegisterCapabilities(check func() bool, register func()) {
 	s.capabilitiesMu.RLock()
 	if check() {
@@ -481,7 +744,7 @@ func (s *MCPServer) AddTools(tools ...ServerTool) {
 	// When the list of available tools changes, servers that declared the listChanged capability SHOULD send a notification.
 	if s.capabilities.tools.listChanged {
 		// Send notification to all initialized sessions
-		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationToolsListChanged)
 	}
 }
 
@@ -508,8 +771,29 @@ func (s *MCPServer) DeleteTools(names ...string) {
 	// When the list of available tools changes, servers that declared the listChanged capability SHOULD send a notification.
 	if exists && s.capabilities.tools != nil && s.capabilities.tools.listChanged {
 		// Send notification to all initialized sessions
-		s.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+		s.notifyListChanged(mcp.MethodNotificationToolsListChanged)
+	}
+}
+
+// notifyListChanged sends a list changed notification to all initialized sessions,
+// coalescing changes within the WithListChangedDebounce window.
+func (s *MCPServer) notifyListChanged(method string) {
+	if s.listChangedDebounce <= 0 {
+		s.SendNotificationToAllClients(method, nil)
+		return
+	}
+
+	s.listChangedMu.Lock()
+	defer s.listChangedMu.Unlock()
+	if _, pending := s.listChangedPending[method]; pending {
+		return
 	}
+	s.listChangedPending[method] = time.AfterFunc(s.listChangedDebounce, func() {
+		s.listChangedMu.Lock()
+		delete(s.listChangedPending, method)
+		s.listChangedMu.Unlock()
+		s.SendNotificationToAllClients(method, nil)
+	})
 }
 
 // AddNotificationHandler registers a new handler for incoming notifications
@@ -522,6 +806,103 @@ func (s *MCPServer) AddNotificationHandler(
 	s.notificationHandlers[method] = handler
 }
 
+// Shutdown gracefully stops the server. New requests are rejected with
+// ErrServerShuttingDown while the requests already being handled are allowed to
+// complete. If ctx ends first, the remaining requests are cancelled through their
+// context and a *ShutdownError reporting how many were cancelled is returned.
+// Finally, all sessions are unregistered.
+func (s *MCPServer) Shutdown(ctx context.Context) error {
+	s.requestsMu.Lock()
+	s.shuttingDown = true
+	s.requestsMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.requestsWG.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		s.requestsMu.Lock()
+		cancelled := len(s.activeRequests)
+		for _, cancel := range s.activeRequests {
+			cancel()
+		}
+		s.requestsMu.Unlock()
+		err = &ShutdownError{Cancelled: cancelled, Err: ctx.Err()}
+	}
+
+	s.sessions.Range(func(key, value any) bool {
+		s.UnregisterSession(ctx, key.(string))
+		return true
+	})
+	// Sessions that are not registered, such as streamable HTTP sessions
+	// without a listening connection, still disconnect
+	s.connectedSessions.Range(func(key, value any) bool {
+		s.sessionDisconnected(key.(string))
+		return true
+	})
+
+	return err
+}
+
+// requestKey identifies a request by its session and JSON-RPC request ID.
+type requestKey struct {
+	sessionID string
+	id        string
+}
+
+func newRequestKey(ctx context.Context, id any) requestKey {
+	key := requestKey{id: mcp.NewRequestId(id).String()}
+	if session := ClientSessionFromContext(ctx); session != nil {
+		key.sessionID = session.SessionID()
+	}
+	return key
+}
+
+// trackRequest registers a request as in flight. It returns a cancellable
+// context for the request and a function that must be called once the request
+// is done, or false if the server is shutting down.
+func (s *MCPServer) trackRequest(ctx context.Context, id any) (context.Context, func(), bool) {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if s.shuttingDown {
+		return ctx, nil, false
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.nextRequestKey++
+	key := s.nextRequestKey
+	s.activeRequests[key] = cancel
+	reqKey := newRequestKey(ctx, id)
+	s.requestKeys[reqKey] = key
+	s.requestsWG.Add(1)
+
+	return ctx, func() {
+		s.requestsMu.Lock()
+		delete(s.activeRequests, key)
+		if s.requestKeys[reqKey] == key {
+			delete(s.requestKeys, reqKey)
+		}
+		s.requestsMu.Unlock()
+		cancel()
+		s.requestsWG.Done()
+	}, true
+}
+
+// cancelRequest cancels the context of the in-flight request of the session
+// with the given JSON-RPC request ID, as asked by a notifications/cancelled.
+func (s *MCPServer) cancelRequest(ctx context.Context, id any) {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if key, ok := s.requestKeys[newRequestKey(ctx, id)]; ok {
+		s.activeRequests[key]()
+	}
+}
+
 func (s *MCPServer) handleInitialize(
 	ctx context.Context,
 	_ any,
@@ -562,6 +943,10 @@ func (s *MCPServer) handleInitialize(
 		capabilities.Logging = &struct{}{}
 	}
 
+	if s.capabilities.completions != nil && *s.capabilities.completions {
+		capabilities.Completions = &struct{}{}
+	}
+
 	result := mcp.InitializeResult{
 		ProtocolVersion: s.protocolVersion(request.Params.ProtocolVersion),
 		ServerInfo: mcp.Implementation{
@@ -574,11 +959,14 @@ func (s *MCPServer) handleInitialize(
 
 	if session := ClientSessionFromContext(ctx); session != nil {
 		session.Initialize()
+		s.trackRoots(session, request.Params.Capabilities)
 
 		// Store client info if the session supports it
 		if sessionWithClientInfo, ok := session.(SessionWithClientInfo); ok {
 			sessionWithClientInfo.SetClientInfo(request.Params.ClientInfo)
 		}
+
+		s.sessionConnected(session.SessionID(), request)
 	}
 	return &result, nil
 }
@@ -666,9 +1054,9 @@ func listByPagination[T mcp.Named](
 		}
 	}
 	elementsToReturn := allElements[startPos:endPos]
-	// set the next cursor
+	// set the next cursor, only if there are more elements after this page
 	nextCursor := func() mcp.Cursor {
-		if s.paginationLimit != nil && len(elementsToReturn) >= *s.paginationLimit {
+		if s.paginationLimit != nil && len(elementsToReturn) > 0 && endPos < len(allElements) {
 			nc := elementsToReturn[len(elementsToReturn)-1].GetName()
 			toString := base64.StdEncoding.EncodeToString([]byte(nc))
 			return mcp.Cursor(toString)
@@ -757,12 +1145,29 @@ func (s *MCPServer) handleReadResource(
 	id any,
 	request mcp.ReadResourceRequest,
 ) (*mcp.ReadResourceResult, *requestError) {
+	if byteRange := request.Params.Range; byteRange != nil && (byteRange.Offset < 0 || byteRange.Length < 0) {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("invalid range: offset %d, length %d", byteRange.Offset, byteRange.Length),
+		}
+	}
+
 	s.resourcesMu.RLock()
 	// First try direct resource handlers
 	if entry, ok := s.resources[request.Params.URI]; ok {
-		handler := entry.handler
 		s.resourcesMu.RUnlock()
-		contents, err := handler(ctx, request)
+		// Resources without a range handler are read in full, and the result
+		// carries no range so that the client can tell
+		var contents []mcp.ResourceContents
+		var err error
+		served := request.Params.Range
+		if served != nil && entry.rangeHandler != nil {
+			contents, err = entry.rangeHandler(ctx, request, *served)
+		} else {
+			served = nil
+			contents, err = entry.handler(ctx, request)
+		}
 		if err != nil {
 			return nil, &requestError{
 				id:   id,
@@ -770,28 +1175,35 @@ func (s *MCPServer) handleReadResource(
 				err:  err,
 			}
 		}
-		return &mcp.ReadResourceResult{Contents: contents}, nil
+		return &mcp.ReadResourceResult{Contents: contents, Range: served}, nil
 	}
 
-	// If no direct handler found, try matching against templates
-	var matchedHandler ResourceTemplateHandlerFunc
+	// If no direct handler found, try matching against templates.
+	// When several templates match, the most specific one wins.
+	var matchedEntry resourceTemplateEntry
 	var matched bool
 	for _, entry := range s.resourceTemplates {
-		template := entry.template
-		if matchesTemplate(request.Params.URI, template.URITemplate) {
-			matchedHandler = entry.handler
+		if !matchesTemplate(request.Params.URI, entry.template.URITemplate) {
+			continue
+		}
+		if !matched || moreSpecificTemplate(entry.template.URITemplate, matchedEntry.template.URITemplate) {
+			matchedEntry = entry
 			matched = true
-			matchedVars := template.URITemplate.Match(request.Params.URI)
-			// Convert matched variables to a map
-			request.Params.Arguments = make(map[string]any, len(matchedVars))
-			for name, value := range matchedVars {
-				request.Params.Arguments[name] = value.V
-			}
-			break
 		}
 	}
 	s.resourcesMu.RUnlock()
 
+	var matchedHandler ResourceTemplateHandlerFunc
+	if matched {
+		matchedHandler = matchedEntry.handler
+		matchedVars := matchedEntry.template.URITemplate.Match(request.Params.URI)
+		// Convert matched variables to a map
+		request.Params.Arguments = make(map[string]any, len(matchedVars))
+		for name, value := range matchedVars {
+			request.Params.Arguments[name] = value.V
+		}
+	}
+
 	if matched {
 		contents, err := matchedHandler(ctx, request)
 		if err != nil {
@@ -815,11 +1227,84 @@ func (s *MCPServer) handleReadResource(
 	}
 }
 
+func (s *MCPServer) handleSubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.SubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	session, reqErr := s.subscriptionSession(ctx, id)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	session.SubscribeResource(request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+func (s *MCPServer) handleUnsubscribe(
+	ctx context.Context,
+	id any,
+	request mcp.UnsubscribeRequest,
+) (*mcp.EmptyResult, *requestError) {
+	session, reqErr := s.subscriptionSession(ctx, id)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	session.UnsubscribeResource(request.Params.URI)
+	return &mcp.EmptyResult{}, nil
+}
+
+// subscriptionSession returns the current session if the server and session support resource subscriptions.
+func (s *MCPServer) subscriptionSession(
+	ctx context.Context,
+	id any,
+) (SessionWithResourceSubscriptions, *requestError) {
+	if !s.capabilities.resources.subscribe {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.METHOD_NOT_FOUND,
+			err:  fmt.Errorf("resource subscriptions %w", ErrUnsupported),
+		}
+	}
+
+	clientSession := ClientSessionFromContext(ctx)
+	if clientSession == nil || !clientSession.Initialized() {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  ErrSessionNotInitialized,
+		}
+	}
+
+	session, ok := clientSession.(SessionWithResourceSubscriptions)
+	if !ok {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INTERNAL_ERROR,
+			err:  ErrSessionDoesNotSupportResourceSubscriptions,
+		}
+	}
+	return session, nil
+}
+
 // matchesTemplate checks if a URI matches a URI template pattern
 func matchesTemplate(uri string, template *mcp.URITemplate) bool {
 	return template.Regexp().MatchString(uri)
 }
 
+// templateExpression matches the expressions of a URI template, e.g. "{id}" or "{+path}".
+var templateExpression = regexp.MustCompile(`\{[^}]*\}`)
+
+// moreSpecificTemplate reports whether template a is more specific than b, i.e. has
+// more literal characters. Ties are broken by the raw template so matching is deterministic.
+func moreSpecificTemplate(a, b *mcp.URITemplate) bool {
+	literalA := len(templateExpression.ReplaceAllString(a.Raw(), ""))
+	literalB := len(templateExpression.ReplaceAllString(b.Raw(), ""))
+	if literalA != literalB {
+		return literalA > literalB
+	}
+	return a.Raw() < b.Raw()
+}
+
 func (s *MCPServer) handleListPrompts(
 	ctx context.Context,
 	id any,
@@ -887,6 +1372,74 @@ func (s *MCPServer) handleGetPrompt(
 	return result, nil
 }
 
+func (s *MCPServer) handleComplete(
+	ctx context.Context,
+	id any,
+	request mcp.CompleteRequest,
+) (*mcp.CompleteResult, *requestError) {
+	ref, ok := request.Params.Ref.(map[string]any)
+	if !ok {
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("invalid completion reference"),
+		}
+	}
+
+	var completers map[string]mcp.ArgumentCompleterFunc
+	switch refType, _ := ref["type"].(string); refType {
+	case mcp.RefTypePrompt:
+		name, _ := ref["name"].(string)
+		s.promptsMu.RLock()
+		prompt, ok := s.prompts[name]
+		s.promptsMu.RUnlock()
+		if !ok {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_PARAMS,
+				err:  fmt.Errorf("prompt '%s' not found: %w", name, ErrPromptNotFound),
+			}
+		}
+		completers = prompt.ArgumentCompleters
+	case mcp.RefTypeResource:
+		uri, _ := ref["uri"].(string)
+		s.resourcesMu.RLock()
+		entry, ok := s.resourceTemplates[uri]
+		s.resourcesMu.RUnlock()
+		if !ok {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INVALID_PARAMS,
+				err:  fmt.Errorf("resource template '%s' not found: %w", uri, ErrResourceNotFound),
+			}
+		}
+		completers = entry.template.ArgumentCompleters
+	default:
+		return nil, &requestError{
+			id:   id,
+			code: mcp.INVALID_PARAMS,
+			err:  fmt.Errorf("unknown completion reference type '%s'", refType),
+		}
+	}
+
+	// Arguments without a completer have no suggestions
+	values := []string{}
+	if completer, ok := completers[request.Params.Argument.Name]; ok {
+		if suggestions := completer(ctx, request.Params.Argument.Value); suggestions != nil {
+			values = suggestions
+		}
+	}
+
+	result := mcp.CompleteResult{}
+	result.Completion.Total = len(values)
+	if len(values) > mcp.MaxCompletionValues {
+		values = values[:mcp.MaxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	result.Completion.Values = values
+	return &result, nil
+}
+
 func (s *MCPServer) handleListTools(
 	ctx context.Context,
 	id any,
@@ -977,6 +1530,19 @@ func (s *MCPServer) handleListTools(
 	return &result, nil
 }
 
+// toolInputSchema returns the input schema of the tool, decoding RawInputSchema if set.
+// A raw schema that cannot be decoded yields nil, which disables the required arguments check.
+func toolInputSchema(tool mcp.Tool) *mcp.ToolInputSchema {
+	if tool.RawInputSchema == nil {
+		return &tool.InputSchema
+	}
+	var schema mcp.ToolInputSchema
+	if err := json.Unmarshal(tool.RawInputSchema, &schema); err != nil {
+		return nil
+	}
+	return &schema
+}
+
 func (s *MCPServer) handleToolCall(
 	ctx context.Context,
 	id any,
@@ -1014,6 +1580,8 @@ func (s *MCPServer) handleToolCall(
 		}
 	}
 
+	request.InputSchema = toolInputSchema(tool.Tool)
+
 	finalHandler := tool.Handler
 
 	s.middlewareMu.RLock()
@@ -1025,6 +1593,20 @@ func (s *MCPServer) handleToolCall(
 		finalHandler = mw[i](finalHandler)
 	}
 
+	// Wait for a free slot when the number of concurrent tool calls is limited
+	if s.toolSlots != nil {
+		select {
+		case s.toolSlots <- struct{}{}:
+			defer func() { <-s.toolSlots }()
+		case <-ctx.Done():
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  ctx.Err(),
+			}
+		}
+	}
+
 	result, err := finalHandler(ctx, request)
 	if err != nil {
 		return nil, &requestError{
@@ -1034,13 +1616,101 @@ func (s *MCPServer) handleToolCall(
 		}
 	}
 
+	if s.strictOutputValidation {
+		if err := validateToolOutput(tool.Tool, result); err != nil {
+			return nil, &requestError{
+				id:   id,
+				code: mcp.INTERNAL_ERROR,
+				err:  fmt.Errorf("tool '%s' returned invalid output: %w", request.Params.Name, err),
+			}
+		}
+	}
+
+	if s.lenientContentEncoding {
+		result = replaceUnencodableContent(request.Params.Name, result)
+	}
+
 	return result, nil
 }
 
+// replaceUnencodableContent returns the result with the content items that
+// cannot be encoded replaced by text items describing the failure. The result
+// returned by the handler is left unchanged.
+func replaceUnencodableContent(toolName string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	if result == nil {
+		return nil
+	}
+	var content []mcp.Content
+	for i, item := range result.Content {
+		err := encodeContent(item)
+		if err == nil {
+			continue
+		}
+		log.Printf("tool '%s': failed to encode content item %d: %v", toolName, i, err)
+		if content == nil {
+			content = slices.Clone(result.Content)
+		}
+		content[i] = mcp.NewTextContent(fmt.Sprintf("content item %d could not be encoded: %v", i, err))
+	}
+	if content == nil {
+		return result
+	}
+	replaced := *result
+	replaced.Content = content
+	return &replaced
+}
+
+// encodeContent reports whether a content item can be encoded to JSON without
+// losing data.
+func encodeContent(item mcp.Content) error {
+	// Invalid UTF-8 would be silently replaced when encoding
+	switch text := item.(type) {
+	case mcp.TextContent:
+		if !utf8.ValidString(text.Text) {
+			return errors.New("text is not valid UTF-8")
+		}
+	case *mcp.TextContent:
+		if text != nil && !utf8.ValidString(text.Text) {
+			return errors.New("text is not valid UTF-8")
+		}
+	}
+	_, err := json.Marshal(item)
+	return err
+}
+
+// validateToolOutput checks the structured content of a successful tool result against the tool's output schema.
+func validateToolOutput(tool mcp.Tool, result *mcp.CallToolResult) error {
+	if tool.OutputSchema == nil || result == nil || result.IsError {
+		return nil
+	}
+	if result.StructuredContent == nil {
+		return fmt.Errorf("structured content is required by the output schema: %w", ErrInvalidToolOutput)
+	}
+	if err := mcp.ValidateSchema(tool.OutputSchema, result.StructuredContent); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToolOutput, err)
+	}
+	return nil
+}
+
 func (s *MCPServer) handleNotification(
 	ctx context.Context,
 	notification mcp.JSONRPCNotification,
 ) mcp.JSONRPCMessage {
+	if notification.Method == mcp.MethodNotificationInitialized {
+		if session := ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+			s.initializedSessions.Store(session.SessionID(), struct{}{})
+		}
+		s.refreshRoots(ctx)
+	}
+	if notification.Method == mcp.MethodNotificationRootsListChanged {
+		s.refreshRoots(ctx)
+	}
+	if notification.Method == mcp.MethodNotificationCancelled {
+		if id, ok := notification.Params.AdditionalFields["requestId"]; ok && id != nil {
+			s.cancelRequest(ctx, id)
+		}
+	}
+
 	s.notificationHandlersMu.RLock()
 	handler, ok := s.notificationHandlers[notification.Method]
 	s.notificationHandlersMu.RUnlock()
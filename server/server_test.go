@@ -6,8 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -377,6 +381,63 @@ func TestMCPServer_Tools(t *testing.T) {
 	}
 }
 
+func TestMCPServer_ListChangedDebounce(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolCapabilities(true),
+		WithPromptCapabilities(true),
+		WithListChangedDebounce(50*time.Millisecond),
+	)
+	notificationChannel := make(chan mcp.JSONRPCNotification, 100)
+	err := server.RegisterSession(context.Background(), &fakeSession{
+		sessionID:           "test",
+		notificationChannel: notificationChannel,
+		initialized:         true,
+	})
+	require.NoError(t, err)
+
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+	for i := 0; i < 5; i++ {
+		server.AddTool(mcp.NewTool(fmt.Sprintf("tool-%d", i)), handler)
+	}
+	server.DeleteTools("tool-0")
+	server.AddPrompt(mcp.NewPrompt("prompt"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{}, nil
+	})
+
+	// Nothing is sent before the window ends
+	select {
+	case notification := <-notificationChannel:
+		t.Fatalf("unexpected notification before the debounce window ended: %s", notification.Method)
+	default:
+	}
+
+	methods := make(map[string]int)
+	timeout := time.After(500 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case notification := <-notificationChannel:
+			methods[notification.Method]++
+		case <-timeout:
+			done = true
+		}
+	}
+	assert.Equal(t, map[string]int{
+		mcp.MethodNotificationToolsListChanged:   1,
+		mcp.MethodNotificationPromptsListChanged: 1,
+	}, methods)
+
+	// A change after the window schedules a new notification
+	server.DeleteTools("tool-1")
+	select {
+	case notification := <-notificationChannel:
+		assert.Equal(t, mcp.MethodNotificationToolsListChanged, notification.Method)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for notification")
+	}
+}
+
 func TestMCPServer_HandleValidMessages(t *testing.T) {
 	server := NewMCPServer("test-server", "1.0.0",
 		WithResourceCapabilities(true, true),
@@ -503,6 +564,74 @@ func TestMCPServer_HandlePagination(t *testing.T) {
 	}
 }
 
+func TestMCPServer_PaginateTools(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPaginationLimit(3))
+	for i := 0; i < 7; i++ {
+		server.AddTool(mcp.NewTool(fmt.Sprintf("tool-%d", i)), nil)
+	}
+
+	var names []string
+	var cursor mcp.Cursor
+	pages := 0
+	for {
+		message := `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`
+		if cursor != "" {
+			message = fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "tools/list", "params": {"cursor": %q}}`, cursor)
+		}
+		response := server.HandleMessage(context.Background(), []byte(message))
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result, ok := resp.Result.(mcp.ListToolsResult)
+		require.True(t, ok)
+
+		pages++
+		assert.LessOrEqual(t, len(result.Tools), 3)
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"tool-0", "tool-1", "tool-2", "tool-3", "tool-4", "tool-5", "tool-6"}, names)
+}
+
+func TestMCPServer_PaginationLastFullPageHasNoCursor(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPaginationLimit(2), WithPromptCapabilities(true))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		server.AddPrompt(mcp.NewPrompt(name), nil)
+	}
+
+	cursor := base64.StdEncoding.EncodeToString([]byte("b"))
+	response := server.HandleMessage(context.Background(), []byte(fmt.Sprintf(
+		`{"jsonrpc": "2.0", "id": 1, "method": "prompts/list", "params": {"cursor": %q}}`, cursor,
+	)))
+	resp, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok)
+	result, ok := resp.Result.(mcp.ListPromptsResult)
+	require.True(t, ok)
+
+	require.Len(t, result.Prompts, 2)
+	assert.Equal(t, "c", result.Prompts[0].Name)
+	assert.Equal(t, "d", result.Prompts[1].Name)
+	assert.Equal(t, mcp.Cursor(""), result.NextCursor)
+}
+
+func TestMCPServer_PaginationInvalidCursor(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithPaginationLimit(2))
+	server.AddTool(mcp.NewTool("tool"), nil)
+
+	response := server.HandleMessage(context.Background(), []byte(
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/list", "params": {"cursor": "not base64!"}}`,
+	))
+	errResp, ok := response.(mcp.JSONRPCError)
+	require.True(t, ok)
+	assert.Equal(t, mcp.INVALID_PARAMS, errResp.Error.Code)
+}
+
 func TestMCPServer_HandleNotifications(t *testing.T) {
 	server := createTestServer()
 	notificationReceived := false
@@ -1271,6 +1400,21 @@ func TestMCPServer_HandleMethodsWithoutCapabilities(t *testing.T) {
 			expectedErr: mcp.METHOD_NOT_FOUND,
 			errString:   "resources",
 		},
+		{
+			name: "Completions without capabilities",
+			message: `{
+                    "jsonrpc": "2.0",
+                    "id": 1,
+                    "method": "completion/complete",
+                    "params": {
+                        "ref": {"type": "ref/prompt", "name": "test-prompt"},
+                        "argument": {"name": "language", "value": "py"}
+                    }
+                }`,
+			options:     []ServerOption{hooksOption}, // No capabilities at all
+			expectedErr: mcp.METHOD_NOT_FOUND,
+			errString:   "completions",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1524,6 +1668,50 @@ func (f fakeSession) Initialized() bool {
 
 var _ ClientSession = fakeSession{}
 
+func TestMCPServer_ResourceTemplateMatching(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	respond := func(name string) ResourceTemplateHandlerFunc {
+		return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			params, _ := json.Marshal(request.TemplateParams())
+			return []mcp.ResourceContents{
+				mcp.TextResourceContents{URI: request.Params.URI, Text: name + " " + string(params)},
+			}, nil
+		}
+	}
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///{+path}", "Files"), respond("files"))
+	server.AddResourceTemplate(mcp.NewResourceTemplate("file:///docs/{name}", "Docs"), respond("docs"))
+	server.AddResourceTemplate(mcp.NewResourceTemplate("db://users/{id}", "Users"), respond("users"))
+	server.AddResource(mcp.NewResource("db://users/me", "Me"), func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{URI: request.Params.URI, Text: "me"}}, nil
+	})
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "db://users/42", want: `users {"id":"42"}`},
+		{uri: "db://users/me", want: "me"},
+		{uri: "file:///docs/readme", want: `docs {"name":"readme"}`},
+		{uri: "file:///src/main.go", want: `files {"path":"src/main.go"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			// Repeat to catch nondeterministic template selection
+			for i := 0; i < 20; i++ {
+				response := server.HandleMessage(context.Background(), []byte(fmt.Sprintf(
+					`{"jsonrpc": "2.0", "id": 1, "method": "resources/read", "params": {"uri": %q}}`, tt.uri,
+				)))
+				resp, ok := response.(mcp.JSONRPCResponse)
+				require.True(t, ok)
+				result, ok := resp.Result.(mcp.ReadResourceResult)
+				require.True(t, ok)
+				require.Len(t, result.Contents, 1)
+				assert.Equal(t, tt.want, result.Contents[0].(mcp.TextResourceContents).Text)
+			}
+		})
+	}
+}
+
 func TestMCPServer_WithHooks(t *testing.T) {
 	// Create hook counters to verify calls
 	var (
@@ -1829,6 +2017,231 @@ func TestMCPServer_WithRecover(t *testing.T) {
 	assert.Nil(t, errorResponse.Error.Data)
 }
 
+func TestMCPServer_ToolHandlerMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) ToolHandlerMiddleware {
+		return func(next ToolHandlerFunc) ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, name+":"+request.Params.Name)
+				return next(ctx, request)
+			}
+		}
+	}
+	deny := func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.GetString("token", "") != "secret" {
+				return mcp.NewToolResultError("unauthorized"), nil
+			}
+			return next(ctx, request)
+		}
+	}
+
+	server := NewMCPServer(
+		"test-server",
+		"1.0.0",
+		WithToolHandlerMiddleware(record("first")),
+		WithToolHandlerMiddleware(record("second")),
+		WithToolHandlerMiddleware(deny),
+	)
+	server.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "handler")
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	t.Run("applied in registration order", func(t *testing.T) {
+		calls = nil
+		response := server.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "tools/call",
+			"params": {"name": "echo", "arguments": {"token": "secret"}}
+		}`))
+
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		assert.False(t, result.IsError)
+		assert.Equal(t, []string{"first:echo", "second:echo", "handler"}, calls)
+	})
+
+	t.Run("short-circuits without calling the handler", func(t *testing.T) {
+		calls = nil
+		response := server.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 2,
+			"method": "tools/call",
+			"params": {"name": "echo"}
+		}`))
+
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		result, ok := resp.Result.(mcp.CallToolResult)
+		require.True(t, ok)
+		assert.True(t, result.IsError)
+		assert.Equal(t, []string{"first:echo", "second:echo"}, calls)
+	})
+}
+
+func TestMCPServer_ToolCallBindsInputSchema(t *testing.T) {
+	type args struct {
+		Path string `json:"path"`
+	}
+	server := NewMCPServer("test-server", "1.0.0")
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var a args
+		if err := request.BindArguments(&a); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(a.Path), nil
+	}
+	server.AddTool(mcp.NewTool("dsl", mcp.WithString("path", mcp.Required())), handler)
+	server.AddTool(mcp.NewToolWithRawSchema("raw", "", json.RawMessage(
+		`{"type": "object", "properties": {"path": {"type": "string"}}, "required": ["path"]}`,
+	)), handler)
+
+	for _, name := range []string{"dsl", "raw"} {
+		t.Run(name, func(t *testing.T) {
+			response := server.HandleMessage(context.Background(), []byte(fmt.Sprintf(
+				`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": %q, "arguments": {}}}`, name,
+			)))
+			resp, ok := response.(mcp.JSONRPCResponse)
+			require.True(t, ok)
+			result, ok := resp.Result.(mcp.CallToolResult)
+			require.True(t, ok)
+			assert.True(t, result.IsError)
+			assert.Equal(t, `required argument "path" not found`, result.Content[0].(mcp.TextContent).Text)
+
+			response = server.HandleMessage(context.Background(), []byte(fmt.Sprintf(
+				`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": %q, "arguments": {"path": "/tmp"}}}`, name,
+			)))
+			result = response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+			assert.False(t, result.IsError)
+			assert.Equal(t, "/tmp", result.Content[0].(mcp.TextContent).Text)
+		})
+	}
+}
+
+func TestMCPServer_StrictOutputValidation(t *testing.T) {
+	outputSchema := mcp.WithOutputSchema(mcp.ToolOutputSchema{
+		Type: "object",
+		Properties: map[string]any{
+			"items": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"id": map[string]any{"type": "integer"}},
+					"required":   []string{"id"},
+				},
+			},
+		},
+		Required: []string{"items"},
+	})
+	newServer := func(strict bool) *MCPServer {
+		server := NewMCPServer("test-server", "1.0.0", WithStrictOutputValidation(strict))
+		server.AddTool(mcp.NewTool("valid", outputSchema), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultStructured(map[string]any{"items": []any{map[string]any{"id": 1}}}, "1 item"), nil
+		})
+		server.AddTool(mcp.NewTool("invalid", outputSchema), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultStructured(map[string]any{"items": []any{map[string]any{"id": "one"}}}, "1 item"), nil
+		})
+		server.AddTool(mcp.NewTool("missing", outputSchema), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("no structured content"), nil
+		})
+		server.AddTool(mcp.NewTool("failed", outputSchema), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultError("something went wrong"), nil
+		})
+		return server
+	}
+	callTool := func(server *MCPServer, name string) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(fmt.Sprintf(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": %q}}`, name,
+		)))
+	}
+
+	strict := newServer(true)
+
+	t.Run("conforming output is returned", func(t *testing.T) {
+		_, ok := callTool(strict, "valid").(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+
+	t.Run("non-conforming output is an internal error with the field path", func(t *testing.T) {
+		errResp, ok := callTool(strict, "invalid").(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INTERNAL_ERROR, errResp.Error.Code)
+		assert.Contains(t, errResp.Error.Message, "$.items[0].id")
+	})
+
+	t.Run("missing structured content is an internal error", func(t *testing.T) {
+		errResp, ok := callTool(strict, "missing").(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INTERNAL_ERROR, errResp.Error.Code)
+	})
+
+	t.Run("error results are not validated", func(t *testing.T) {
+		_, ok := callTool(strict, "failed").(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+
+	t.Run("validation is disabled by default", func(t *testing.T) {
+		_, ok := callTool(newServer(false), "invalid").(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+}
+
+func TestMCPServer_LenientContentEncoding(t *testing.T) {
+	nan := mcp.TextContent{
+		Annotated: mcp.Annotated{Annotations: &mcp.Annotations{Priority: math.NaN()}},
+		Type:      "text",
+		Text:      "important",
+	}
+	content := []mcp.Content{
+		mcp.NewTextContent("first"),
+		mcp.NewTextContent("binary \xff\xfe"),
+		nan,
+		mcp.NewImageContent("aW1hZ2U=", "image/png"),
+	}
+	newServer := func(opts ...ServerOption) *MCPServer {
+		server := NewMCPServer("test-server", "1.0.0", opts...)
+		server.AddTool(mcp.NewTool("mixed"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: content}, nil
+		})
+		return server
+	}
+	callTool := func(server *MCPServer) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "mixed"}}`,
+		))
+	}
+
+	t.Run("failed items are replaced", func(t *testing.T) {
+		response, ok := callTool(newServer(WithLenientContentEncoding(true))).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		_, err := json.Marshal(response)
+		require.NoError(t, err)
+
+		result := response.Result.(mcp.CallToolResult)
+		require.Len(t, result.Content, 4)
+		assert.Equal(t, mcp.NewTextContent("first"), result.Content[0])
+		assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "content item 1 could not be encoded: text is not valid UTF-8")
+		assert.Contains(t, result.Content[2].(mcp.TextContent).Text, "content item 2 could not be encoded")
+		assert.Contains(t, result.Content[2].(mcp.TextContent).Text, "NaN")
+		assert.Equal(t, content[3], result.Content[3])
+		assert.False(t, result.IsError)
+
+		// The result returned by the handler is left unchanged
+		assert.Equal(t, nan, content[2])
+	})
+
+	t.Run("strict encoding is the default", func(t *testing.T) {
+		response, ok := callTool(newServer()).(mcp.JSONRPCResponse)
+		require.True(t, ok)
+		_, err := json.Marshal(response)
+		assert.Error(t, err)
+	})
+}
+
 func getTools(length int) []mcp.Tool {
 	list := make([]mcp.Tool, 0, 10000)
 	for i := range length {
@@ -1866,7 +2279,7 @@ func listByPaginationForReflect[T any](
 	elementsToReturn := allElements[startPos:endPos]
 	// set the next cursor
 	nextCursor := func() mcp.Cursor {
-		if s.paginationLimit != nil && len(elementsToReturn) >= *s.paginationLimit {
+		if s.paginationLimit != nil && len(elementsToReturn) > 0 && endPos < len(allElements) {
 			nc := reflect.ValueOf(elementsToReturn[len(elementsToReturn)-1]).
 				FieldByName("Name").
 				String()
@@ -2022,3 +2435,356 @@ func TestMCPServer_ProtocolNegotiation(t *testing.T) {
 		})
 	}
 }
+
+func TestMCPServer_Completion(t *testing.T) {
+	languages := []string{"go", "javascript", "python", "rust"}
+	completeLanguage := func(ctx context.Context, partial string) []string {
+		var values []string
+		for _, language := range languages {
+			if strings.HasPrefix(language, partial) {
+				values = append(values, language)
+			}
+		}
+		return values
+	}
+
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddPrompt(
+		mcp.NewPrompt("code_review",
+			mcp.WithArgument("language"),
+			mcp.WithArgument("style"),
+			mcp.WithArgumentCompleter("language", completeLanguage),
+		),
+		func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		},
+	)
+	server.AddResourceTemplate(
+		mcp.NewResourceTemplate("test://numbers/{n}", "numbers",
+			mcp.WithTemplateArgumentCompleter("n", func(ctx context.Context, partial string) []string {
+				values := make([]string, 150)
+				for i := range values {
+					values[i] = partial + strconv.Itoa(i)
+				}
+				return values
+			}),
+		),
+		func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+			return nil, nil
+		},
+	)
+
+	// Adding completers enables the completions capability
+	initResponse := server.HandleMessage(context.Background(), []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize"
+	}`))
+	initResult, ok := initResponse.(mcp.JSONRPCResponse).Result.(mcp.InitializeResult)
+	require.True(t, ok)
+	assert.NotNil(t, initResult.Capabilities.Completions)
+
+	complete := func(ref, name, value string) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(`{
+			"jsonrpc": "2.0",
+			"id": 1,
+			"method": "completion/complete",
+			"params": {
+				"ref": `+ref+`,
+				"argument": {"name": "`+name+`", "value": "`+value+`"}
+			}
+		}`))
+	}
+	completion := func(t *testing.T, response mcp.JSONRPCMessage) mcp.CompleteResult {
+		t.Helper()
+		resp, ok := response.(mcp.JSONRPCResponse)
+		require.True(t, ok, "expected response, got %#v", response)
+		result, ok := resp.Result.(mcp.CompleteResult)
+		require.True(t, ok)
+		return result
+	}
+
+	t.Run("prompt argument", func(t *testing.T) {
+		result := completion(t, complete(`{"type": "ref/prompt", "name": "code_review"}`, "language", "py"))
+		assert.Equal(t, []string{"python"}, result.Completion.Values)
+		assert.Equal(t, 1, result.Completion.Total)
+		assert.False(t, result.Completion.HasMore)
+	})
+
+	t.Run("argument without completer", func(t *testing.T) {
+		result := completion(t, complete(`{"type": "ref/prompt", "name": "code_review"}`, "style", "s"))
+		assert.Equal(t, []string{}, result.Completion.Values)
+	})
+
+	t.Run("resource template values are capped", func(t *testing.T) {
+		result := completion(t, complete(`{"type": "ref/resource", "uri": "test://numbers/{n}"}`, "n", "1"))
+		assert.Len(t, result.Completion.Values, mcp.MaxCompletionValues)
+		assert.Equal(t, "10", result.Completion.Values[0])
+		assert.Equal(t, 150, result.Completion.Total)
+		assert.True(t, result.Completion.HasMore)
+	})
+
+	t.Run("unknown references", func(t *testing.T) {
+		for _, ref := range []string{
+			`{"type": "ref/prompt", "name": "missing"}`,
+			`{"type": "ref/resource", "uri": "test://missing"}`,
+			`{"type": "ref/tool", "name": "code_review"}`,
+		} {
+			errorResponse, ok := complete(ref, "language", "").(mcp.JSONRPCError)
+			require.True(t, ok, ref)
+			assert.Equal(t, mcp.INVALID_PARAMS, errorResponse.Error.Code)
+		}
+	})
+}
+
+func TestMCPServer_Shutdown(t *testing.T) {
+	callTool := func(server *MCPServer, id int) mcp.JSONRPCMessage {
+		return server.HandleMessage(context.Background(), []byte(fmt.Sprintf(`{
+			"jsonrpc": "2.0",
+			"id": %d,
+			"method": "tools/call",
+			"params": {"name": "work"}
+		}`, id)))
+	}
+
+	newServer := func(handler ToolHandlerFunc) (*MCPServer, chan struct{}) {
+		server := NewMCPServer("test-server", "1.0.0")
+		started := make(chan struct{}, 1)
+		server.AddTool(mcp.NewTool("work"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			started <- struct{}{}
+			return handler(ctx, request)
+		})
+		session := &sseSession{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		return server, started
+	}
+
+	t.Run("drains in-flight requests", func(t *testing.T) {
+		release := make(chan struct{})
+		server, started := newServer(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-release
+			return mcp.NewToolResultText("done"), nil
+		})
+
+		responses := make(chan mcp.JSONRPCMessage, 1)
+		go func() { responses <- callTool(server, 1) }()
+		<-started
+
+		shutdownErr := make(chan error, 1)
+		go func() { shutdownErr <- server.Shutdown(context.Background()) }()
+
+		// New requests are rejected once shutdown has started
+		assert.Eventually(t, func() bool {
+			errorResponse, ok := callTool(server, 2).(mcp.JSONRPCError)
+			return ok && errorResponse.Error.Message == ErrServerShuttingDown.Error()
+		}, time.Second, 10*time.Millisecond)
+
+		close(release)
+		require.NoError(t, <-shutdownErr)
+		_, ok := (<-responses).(mcp.JSONRPCResponse)
+		assert.True(t, ok, "in-flight request should complete")
+
+		_, ok = server.sessions.Load("session-1")
+		assert.False(t, ok, "sessions should be unregistered")
+	})
+
+	t.Run("cancels requests after deadline", func(t *testing.T) {
+		server, started := newServer(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		responses := make(chan mcp.JSONRPCMessage, 1)
+		go func() { responses <- callTool(server, 1) }()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := server.Shutdown(ctx)
+
+		var shutdownErr *ShutdownError
+		require.ErrorAs(t, err, &shutdownErr)
+		assert.Equal(t, 1, shutdownErr.Cancelled)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		_, ok := (<-responses).(mcp.JSONRPCError)
+		assert.True(t, ok, "cancelled request should fail")
+	})
+}
+
+func TestMCPServer_MaxConcurrentTools(t *testing.T) {
+	const limit = 2
+	server := NewMCPServer("test-server", "1.0.0", WithMaxConcurrentTools(limit))
+
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+	server.AddTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	const calls = 5
+	responses := make(chan mcp.JSONRPCMessage, calls)
+	for i := 0; i < calls; i++ {
+		go func(id int) {
+			responses <- server.HandleMessage(context.Background(), []byte(fmt.Sprintf(`{
+				"jsonrpc": "2.0",
+				"id": %d,
+				"method": "tools/call",
+				"params": {"name": "slow"}
+			}`, id)))
+		}(i)
+	}
+
+	// Calls beyond the limit wait instead of failing
+	assert.Eventually(t, func() bool { return running.Load() == limit }, time.Second, 5*time.Millisecond)
+	assert.Len(t, responses, 0)
+
+	close(release)
+	for i := 0; i < calls; i++ {
+		_, ok := (<-responses).(mcp.JSONRPCResponse)
+		assert.True(t, ok, "queued tool calls should succeed")
+	}
+	assert.Equal(t, int32(limit), maxRunning.Load())
+}
+
+func TestMCPServer_AddStreamingTool(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	server.AddStreamingTool(mcp.NewTool("tail"), func(ctx context.Context, request mcp.CallToolRequest, emit ToolOutputEmitter) (*mcp.CallToolResult, error) {
+		require.NoError(t, emit(mcp.NewTextContent("line 1")))
+		require.NoError(t, emit(mcp.NewTextContent("line 2")))
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	texts := func(content []mcp.Content) []string {
+		var texts []string
+		for _, c := range content {
+			texts = append(texts, c.(mcp.TextContent).Text)
+		}
+		return texts
+	}
+
+	t.Run("streams output with a progress token", func(t *testing.T) {
+		notifications := make(chan mcp.JSONRPCNotification, 10)
+		session := &fakeSession{sessionID: "stream", notificationChannel: notifications, initialized: true}
+		ctx := server.WithContext(context.Background(), session)
+
+		response := server.HandleMessage(ctx, []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "tail", "_meta": {"progressToken": "t1"}}}`,
+		))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		assert.Equal(t, []string{"done"}, texts(result.Content))
+
+		require.Len(t, notifications, 2)
+		for _, expected := range []string{"line 1", "line 2"} {
+			notification := <-notifications
+			assert.Equal(t, mcp.MethodNotificationToolOutput, notification.Method)
+			assert.Equal(t, "t1", notification.Params.AdditionalFields["progressToken"])
+			assert.Equal(t, []string{expected}, texts(notification.Params.AdditionalFields["content"].([]mcp.Content)))
+		}
+	})
+
+	t.Run("prepends output to the result without a progress token", func(t *testing.T) {
+		response := server.HandleMessage(context.Background(), []byte(
+			`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "tail"}}`,
+		))
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		assert.Equal(t, []string{"line 1", "line 2", "done"}, texts(result.Content))
+	})
+}
+
+func TestMCPServer_CancelledNotification(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+	started := make(chan struct{})
+	server.AddTool(mcp.NewTool("wait"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	session := &fakeSession{sessionID: "cancel", notificationChannel: make(chan mcp.JSONRPCNotification, 10), initialized: true}
+	ctx := server.WithContext(context.Background(), session)
+
+	done := make(chan mcp.JSONRPCMessage, 1)
+	go func() {
+		done <- server.HandleMessage(ctx, []byte(
+			`{"jsonrpc": "2.0", "id": 7, "method": "tools/call", "params": {"name": "wait"}}`,
+		))
+	}()
+	<-started
+
+	// A cancellation from another session does not affect the request
+	other := server.WithContext(context.Background(), &fakeSession{sessionID: "other", initialized: true})
+	server.HandleMessage(other, []byte(
+		`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 7}}`,
+	))
+	select {
+	case <-done:
+		t.Fatal("Expected the request to keep running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	server.HandleMessage(ctx, []byte(
+		`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 7, "reason": "user abort"}}`,
+	))
+	select {
+	case response := <-done:
+		_, ok := response.(mcp.JSONRPCError)
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the request to be cancelled")
+	}
+}
+
+func TestMCPServer_RequestObserver(t *testing.T) {
+	type observation struct {
+		method    string
+		id        string
+		sessionID string
+		err       error
+	}
+	var observed []observation
+	server := NewMCPServer("test-server", "1.0.0",
+		WithToolCapabilities(false),
+		WithRequestObserver(func(ctx context.Context, method string, start time.Time, err error) {
+			assert.False(t, start.IsZero())
+			assert.False(t, start.After(time.Now()))
+			id, ok := RequestIDFromContext(ctx)
+			require.True(t, ok)
+			var sessionID string
+			if session := ClientSessionFromContext(ctx); session != nil {
+				sessionID = session.SessionID()
+			}
+			observed = append(observed, observation{method, id.String(), sessionID, err})
+		}),
+	)
+	ctx := server.WithContext(context.Background(), &fakeSession{sessionID: "observed", initialized: true})
+
+	server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+	server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": "two", "method": "tools/call", "params": {"name": "missing"}}`))
+	server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "id": 3, "method": "unknown/method"}`))
+	// Notifications are not requests
+	server.HandleMessage(ctx, []byte(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`))
+
+	require.Len(t, observed, 3)
+	assert.Equal(t, observation{"ping", "int64:1", "observed", nil}, observed[0])
+
+	assert.Equal(t, "tools/call", observed[1].method)
+	assert.Equal(t, "string:two", observed[1].id)
+	assert.ErrorIs(t, observed[1].err, ErrToolNotFound)
+
+	assert.Equal(t, "unknown/method", observed[2].method)
+	var reqErr *requestError
+	require.ErrorAs(t, observed[2].err, &reqErr)
+	assert.Equal(t, mcp.METHOD_NOT_FOUND, reqErr.code)
+}
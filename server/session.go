@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/mathiasXie/mcp-go/mcp"
@@ -39,6 +40,19 @@ type SessionWithTools interface {
 	SetSessionTools(tools map[string]ServerTool)
 }
 
+// SessionWithResourceSubscriptions is an extension of ClientSession that tracks resource subscriptions
+type SessionWithResourceSubscriptions interface {
+	ClientSession
+	// SubscribeResource records a subscription to updates of the resource with the given URI
+	// This method must be thread-safe for concurrent access
+	SubscribeResource(uri string)
+	// UnsubscribeResource removes the subscription to the resource with the given URI
+	// This method must be thread-safe for concurrent access
+	UnsubscribeResource(uri string)
+	// IsSubscribedToResource reports whether the session is subscribed to the resource with the given URI
+	IsSubscribedToResource(uri string) bool
+}
+
 // SessionWithClientInfo is an extension of ClientSession that can store client info
 type SessionWithClientInfo interface {
 	ClientSession
@@ -48,6 +62,15 @@ type SessionWithClientInfo interface {
 	SetClientInfo(clientInfo mcp.Implementation)
 }
 
+// SessionWithRequests is an extension of ClientSession that can send requests to the client,
+// such as elicitation requests
+type SessionWithRequests interface {
+	ClientSession
+	// RequestChannel provides a channel suitable for sending requests to client.
+	// Responses of the client are passed to MCPServer.HandleMessage.
+	RequestChannel() chan<- mcp.JSONRPCRequest
+}
+
 // SessionWithStreamableHTTPConfig extends ClientSession to support streamable HTTP transport configurations
 type SessionWithStreamableHTTPConfig interface {
 	ClientSession
@@ -93,6 +116,7 @@ func (s *MCPServer) RegisterSession(
 		return ErrSessionExists
 	}
 	s.hooks.RegisterSession(ctx, session)
+	s.resumeRootsRefresh(sessionID)
 	return nil
 }
 
@@ -100,6 +124,16 @@ func (s *MCPServer) RegisterSession(
 func (s *MCPServer) UnregisterSession(
 	ctx context.Context,
 	sessionID string,
+) {
+	s.unregisterSession(ctx, sessionID)
+	s.sessionDisconnected(sessionID)
+}
+
+// unregisterSession removes the session from storage without ending it, for
+// transports where a session outlives its connection.
+func (s *MCPServer) unregisterSession(
+	ctx context.Context,
+	sessionID string,
 ) {
 	sessionValue, ok := s.sessions.LoadAndDelete(sessionID)
 	if !ok {
@@ -110,6 +144,51 @@ func (s *MCPServer) UnregisterSession(
 	}
 }
 
+// sessionConnected runs the connect hook the first time a session completes initialize.
+func (s *MCPServer) sessionConnected(sessionID string, request mcp.InitializeRequest) {
+	if sessionID == "" {
+		return
+	}
+	if _, loaded := s.connectedSessions.LoadOrStore(sessionID, struct{}{}); loaded {
+		return
+	}
+	if s.onSessionConnect != nil {
+		s.onSessionConnect(sessionID, request)
+	}
+}
+
+// SessionInitialized reports whether the client of the session completed the
+// initialization handshake by sending notifications/initialized.
+func (s *MCPServer) SessionInitialized(sessionID string) bool {
+	_, ok := s.initializedSessions.Load(sessionID)
+	return ok
+}
+
+// checkHandshake returns ErrSessionNotInitialized for requests that a session sends
+// before notifications/initialized when WithStrictHandshake is enabled.
+func (s *MCPServer) checkHandshake(ctx context.Context, method mcp.MCPMethod) error {
+	if !s.strictHandshake || method == mcp.MethodInitialize || method == mcp.MethodPing {
+		return nil
+	}
+	session := ClientSessionFromContext(ctx)
+	if session == nil || session.SessionID() == "" || s.SessionInitialized(session.SessionID()) {
+		return nil
+	}
+	return fmt.Errorf("%s: %w: notifications/initialized not received", method, ErrSessionNotInitialized)
+}
+
+// sessionDisconnected runs the disconnect hook once for a connected session.
+func (s *MCPServer) sessionDisconnected(sessionID string) {
+	s.initializedSessions.Delete(sessionID)
+	s.forgetRoots(sessionID)
+	if _, ok := s.connectedSessions.LoadAndDelete(sessionID); !ok {
+		return
+	}
+	if s.onSessionDisconnect != nil {
+		s.onSessionDisconnect(sessionID)
+	}
+}
+
 // SendNotificationToAllClients sends a notification to all the currently active clients.
 func (s *MCPServer) SendNotificationToAllClients(
 	method string,
@@ -251,6 +330,60 @@ func (s *MCPServer) SendNotificationToSpecificClient(
 	}
 }
 
+// SendLogMessage sends a notifications/message log entry to the current client.
+// Messages below the minimum level the client set through logging/setLevel are
+// dropped; the default minimum level is info. The logger name is optional.
+func (s *MCPServer) SendLogMessage(
+	ctx context.Context,
+	level mcp.LoggingLevel,
+	logger string,
+	data any,
+) error {
+	if s.capabilities.logging == nil || !*s.capabilities.logging {
+		return fmt.Errorf("logging %w", ErrUnsupported)
+	}
+
+	session := ClientSessionFromContext(ctx)
+	if session == nil || !session.Initialized() {
+		return ErrNotificationNotInitialized
+	}
+
+	minLevel := mcp.LoggingLevelInfo
+	if sessionLogging, ok := session.(SessionWithLogging); ok {
+		minLevel = sessionLogging.GetLogLevel()
+	}
+	if !level.ShouldSendTo(minLevel) {
+		return nil
+	}
+
+	params := map[string]any{
+		"level": level,
+		"data":  data,
+	}
+	if logger != "" {
+		params["logger"] = logger
+	}
+	return s.SendNotificationToClient(ctx, mcp.MethodNotificationMessage, params)
+}
+
+// NotifyResourceUpdated sends a notifications/resources/updated notification for
+// the resource with the given URI to every session subscribed to it.
+func (s *MCPServer) NotifyResourceUpdated(uri string) {
+	s.sessions.Range(func(k, v any) bool {
+		session, ok := v.(SessionWithResourceSubscriptions)
+		if !ok || !session.Initialized() || !session.IsSubscribedToResource(uri) {
+			return true
+		}
+		// Blocked channels are reported through the error hooks
+		_ = s.SendNotificationToSpecificClient(
+			session.SessionID(),
+			mcp.MethodNotificationResourceUpdated,
+			map[string]any{"uri": uri},
+		)
+		return true
+	})
+}
+
 // AddSessionTool adds a tool for a specific session
 func (s *MCPServer) AddSessionTool(sessionID string, tool mcp.Tool, handler ToolHandlerFunc) error {
 	return s.AddSessionTools(sessionID, ServerTool{Tool: tool, Handler: handler})
@@ -378,3 +511,101 @@ func (s *MCPServer) DeleteSessionTools(sessionID string, names ...string) error
 
 	return nil
 }
+
+// clientResponse is the response of the client to a request sent by the server.
+type clientResponse struct {
+	result json.RawMessage
+	err    error
+}
+
+// sendRequestToClient sends a request to the current client and waits for its response.
+// It returns the raw result of the response, or an error if the client answered with an
+// error or ctx ended first.
+func (s *MCPServer) sendRequestToClient(
+	ctx context.Context,
+	method mcp.MCPMethod,
+	params any,
+) (json.RawMessage, error) {
+	session := ClientSessionFromContext(ctx)
+	if session == nil || !session.Initialized() {
+		return nil, ErrSessionNotInitialized
+	}
+	sessionWithRequests, ok := session.(SessionWithRequests)
+	if !ok {
+		return nil, ErrSessionDoesNotSupportRequests
+	}
+	if blocksReadLoop(ctx) {
+		return nil, ErrClientResponseBlocked
+	}
+
+	// the request is sent on the SSE stream of the current HTTP request
+	if sessionWithStreamableHTTPConfig, ok := session.(SessionWithStreamableHTTPConfig); ok {
+		sessionWithStreamableHTTPConfig.UpgradeToSSEWhenReceiveNotification()
+	}
+
+	// String IDs never collide with the numeric IDs of keep-alive pings
+	s.clientRequestsMu.Lock()
+	s.nextClientRequest++
+	id := mcp.NewRequestId(fmt.Sprintf("server-%d", s.nextClientRequest))
+	key := requestKey{sessionID: session.SessionID(), id: id.String()}
+	responses := make(chan clientResponse, 1)
+	s.clientRequests[key] = responses
+	s.clientRequestsMu.Unlock()
+
+	defer func() {
+		s.clientRequestsMu.Lock()
+		delete(s.clientRequests, key)
+		s.clientRequestsMu.Unlock()
+	}()
+
+	request := mcp.JSONRPCRequest{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		ID:      id,
+		Params:  params,
+		Request: mcp.Request{
+			Method: string(method),
+		},
+	}
+	select {
+	case sessionWithRequests.RequestChannel() <- request:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case response := <-responses:
+		return response.result, response.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// handleClientResponse passes the response of the client to the request sent by
+// sendRequestToClient with the given ID. Responses to unknown requests are ignored.
+func (s *MCPServer) handleClientResponse(ctx context.Context, id any, message json.RawMessage) {
+	key := newRequestKey(ctx, id)
+	s.clientRequestsMu.Lock()
+	responses, ok := s.clientRequests[key]
+	delete(s.clientRequests, key)
+	s.clientRequestsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	var result clientResponse
+	if err := json.Unmarshal(message, &response); err != nil {
+		result.err = fmt.Errorf("failed to parse client response: %w", err)
+	} else if response.Error != nil {
+		result.err = fmt.Errorf("client returned error %d: %s", response.Error.Code, response.Error.Message)
+	} else {
+		result.result = response.Result
+	}
+	responses <- result
+}
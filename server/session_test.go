@@ -1,9 +1,13 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -172,12 +176,23 @@ func (f *sessionTestClientWithLogging) GetLogLevel() mcp.LoggingLevel {
 	return level.(mcp.LoggingLevel)
 }
 
+// sessionTestClientWithRequests implements the SessionWithRequests interface for testing
+type sessionTestClientWithRequests struct {
+	sessionTestClient
+	requestChannel chan mcp.JSONRPCRequest
+}
+
+func (f *sessionTestClientWithRequests) RequestChannel() chan<- mcp.JSONRPCRequest {
+	return f.requestChannel
+}
+
 // Verify that all implementations satisfy their respective interfaces
 var (
 	_ ClientSession         = (*sessionTestClient)(nil)
 	_ SessionWithTools      = (*sessionTestClientWithTools)(nil)
 	_ SessionWithLogging    = (*sessionTestClientWithLogging)(nil)
 	_ SessionWithClientInfo = (*sessionTestClientWithClientInfo)(nil)
+	_ SessionWithRequests   = (*sessionTestClientWithRequests)(nil)
 )
 
 func TestSessionWithTools_Integration(t *testing.T) {
@@ -1126,3 +1141,327 @@ func TestSessionWithClientInfo_Integration(t *testing.T) {
 	assert.Equal(t, clientInfo.Name, storedClientInfo.Name, "Client name should match")
 	assert.Equal(t, clientInfo.Version, storedClientInfo.Version, "Client version should match")
 }
+
+func TestMCPServer_SendLogMessage(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithLogging())
+
+	session := &sseSession{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+	}
+	session.Initialize()
+	require.NoError(t, server.RegisterSession(context.Background(), session))
+	ctx := server.WithContext(context.Background(), session)
+
+	// The default minimum level is info
+	assert.Equal(t, mcp.LoggingLevelInfo, session.GetLogLevel())
+	require.NoError(t, server.SendLogMessage(ctx, mcp.LoggingLevelDebug, "db", "dropped"))
+	require.NoError(t, server.SendLogMessage(ctx, mcp.LoggingLevelInfo, "db", "connected"))
+	require.Len(t, session.notificationChannel, 1)
+
+	notification := <-session.notificationChannel
+	assert.Equal(t, mcp.MethodNotificationMessage, notification.Method)
+	assert.Equal(t, mcp.LoggingLevelInfo, notification.Params.AdditionalFields["level"])
+	assert.Equal(t, "db", notification.Params.AdditionalFields["logger"])
+	assert.Equal(t, "connected", notification.Params.AdditionalFields["data"])
+
+	// Raise the minimum level through logging/setLevel
+	response := server.HandleMessage(ctx, []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "logging/setLevel",
+		"params": {"level": "error"}
+	}`))
+	_, ok := response.(mcp.JSONRPCResponse)
+	require.True(t, ok, "expected response, got %#v", response)
+
+	require.NoError(t, server.SendLogMessage(ctx, mcp.LoggingLevelWarning, "", "dropped"))
+	require.NoError(t, server.SendLogMessage(ctx, mcp.LoggingLevelCritical, "", map[string]any{"code": 42}))
+	require.Len(t, session.notificationChannel, 1)
+
+	notification = <-session.notificationChannel
+	assert.Equal(t, mcp.LoggingLevelCritical, notification.Params.AdditionalFields["level"])
+	assert.NotContains(t, notification.Params.AdditionalFields, "logger")
+	assert.Equal(t, map[string]any{"code": 42}, notification.Params.AdditionalFields["data"])
+}
+
+func TestMCPServer_SendLogMessageNotEnabled(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0")
+
+	session := &sseSession{
+		sessionID:           "session-1",
+		notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+	}
+	session.Initialize()
+	ctx := server.WithContext(context.Background(), session)
+
+	err := server.SendLogMessage(ctx, mcp.LoggingLevelError, "", "message")
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assert.Len(t, session.notificationChannel, 0)
+}
+
+func TestMCPServer_SessionHooks_ConnectDisconnect(t *testing.T) {
+	type events struct {
+		mu           sync.Mutex
+		connected    []string
+		clientNames  []string
+		disconnected []string
+	}
+	newServer := func(e *events) *MCPServer {
+		return NewMCPServer("test-server", "1.0.0", WithSessionHooks(
+			func(sessionID string, request mcp.InitializeRequest) {
+				e.mu.Lock()
+				defer e.mu.Unlock()
+				e.connected = append(e.connected, sessionID)
+				e.clientNames = append(e.clientNames, request.Params.ClientInfo.Name)
+			},
+			func(sessionID string) {
+				e.mu.Lock()
+				defer e.mu.Unlock()
+				e.disconnected = append(e.disconnected, sessionID)
+			},
+		))
+	}
+	initialize := []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {"protocolVersion": "2025-03-26", "clientInfo": {"name": "test-client", "version": "1.0.0"}}
+	}`)
+
+	t.Run("fires once per session", func(t *testing.T) {
+		e := &events{}
+		server := newServer(e)
+		session := &sseSession{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		assert.Empty(t, e.connected, "connect should wait for initialize")
+
+		ctx := server.WithContext(context.Background(), session)
+		server.HandleMessage(ctx, initialize)
+		server.HandleMessage(ctx, initialize)
+		assert.Equal(t, []string{"session-1"}, e.connected)
+		assert.Equal(t, []string{"test-client"}, e.clientNames)
+
+		server.UnregisterSession(context.Background(), "session-1")
+		server.UnregisterSession(context.Background(), "session-1")
+		assert.Equal(t, []string{"session-1"}, e.disconnected)
+	})
+
+	t.Run("uninitialized sessions do not disconnect", func(t *testing.T) {
+		e := &events{}
+		server := newServer(e)
+		session := &sseSession{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		server.UnregisterSession(context.Background(), "session-1")
+		assert.Empty(t, e.disconnected)
+	})
+
+	t.Run("abrupt SSE disconnect", func(t *testing.T) {
+		e := &events{}
+		server := newServer(e)
+		testServer := NewTestServer(server)
+		defer testServer.Close()
+
+		sseResp, err := http.Get(testServer.URL + "/sse")
+		require.NoError(t, err)
+		endpointEvent, err := readSSEEvent(sseResp)
+		require.NoError(t, err)
+		messageURL := strings.TrimSpace(strings.Split(strings.Split(endpointEvent, "data: ")[1], "\n")[0])
+
+		resp, err := http.Post(messageURL, "application/json", bytes.NewReader(initialize))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Eventually(t, func() bool {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			return len(e.connected) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		// Drop the connection without any shutdown handshake
+		sseResp.Body.Close()
+		assert.Eventually(t, func() bool {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			return len(e.disconnected) == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, e.connected, e.disconnected)
+	})
+
+	t.Run("shutdown disconnects unregistered sessions", func(t *testing.T) {
+		e := &events{}
+		server := newServer(e)
+		session := newStreamableHttpSession("session-1", newSessionToolsStore(), newSessionSubscriptionsStore(), newSessionLogLevelsStore())
+		server.HandleMessage(server.WithContext(context.Background(), session), initialize)
+		require.Equal(t, []string{"session-1"}, e.connected)
+
+		require.NoError(t, server.Shutdown(context.Background()))
+		assert.Equal(t, []string{"session-1"}, e.disconnected)
+	})
+}
+
+func TestMCPServer_StrictHandshake(t *testing.T) {
+	initialize := []byte(`{
+		"jsonrpc": "2.0",
+		"id": 1,
+		"method": "initialize",
+		"params": {"protocolVersion": "2025-03-26", "clientInfo": {"name": "test-client", "version": "1.0.0"}}
+	}`)
+	listTools := []byte(`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`)
+	ping := []byte(`{"jsonrpc": "2.0", "id": 3, "method": "ping"}`)
+	initialized := []byte(`{"jsonrpc": "2.0", "method": "notifications/initialized"}`)
+
+	newSession := func(server *MCPServer, id string) context.Context {
+		session := &sseSession{
+			sessionID:           id,
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+		}
+		require.NoError(t, server.RegisterSession(context.Background(), session))
+		return server.WithContext(context.Background(), session)
+	}
+
+	t.Run("rejects requests before notifications/initialized", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(false), WithStrictHandshake())
+		ctx := newSession(server, "session-1")
+
+		_, ok := server.HandleMessage(ctx, initialize).(mcp.JSONRPCResponse)
+		require.True(t, ok, "initialize must be allowed")
+		_, ok = server.HandleMessage(ctx, ping).(mcp.JSONRPCResponse)
+		require.True(t, ok, "ping must be allowed")
+
+		errResp, ok := server.HandleMessage(ctx, listTools).(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Equal(t, mcp.INVALID_REQUEST, errResp.Error.Code)
+		assert.Contains(t, errResp.Error.Message, ErrSessionNotInitialized.Error())
+		assert.False(t, server.SessionInitialized("session-1"))
+
+		assert.Nil(t, server.HandleMessage(ctx, initialized))
+		assert.True(t, server.SessionInitialized("session-1"))
+		_, ok = server.HandleMessage(ctx, listTools).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+
+		server.UnregisterSession(context.Background(), "session-1")
+		assert.False(t, server.SessionInitialized("session-1"))
+	})
+
+	t.Run("tracks the handshake without rejecting by default", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(false))
+		ctx := newSession(server, "session-2")
+
+		server.HandleMessage(ctx, initialize)
+		_, ok := server.HandleMessage(ctx, listTools).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+		assert.False(t, server.SessionInitialized("session-2"))
+
+		server.HandleMessage(ctx, initialized)
+		assert.True(t, server.SessionInitialized("session-2"))
+	})
+
+	t.Run("requests without a session are not checked", func(t *testing.T) {
+		server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(false), WithStrictHandshake())
+		_, ok := server.HandleMessage(context.Background(), listTools).(mcp.JSONRPCResponse)
+		assert.True(t, ok)
+	})
+}
+
+func TestMCPServer_RequestElicitation(t *testing.T) {
+	server := NewMCPServer("test-server", "1.0.0", WithToolCapabilities(true))
+	server.AddTool(mcp.NewTool("confirm"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := server.RequestElicitation(ctx, "Proceed?", mcp.ToolInputSchema{
+			Properties: map[string]any{"confirm": map[string]any{"type": "boolean"}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		content, _ := json.Marshal(result.Content)
+		return mcp.NewToolResultText(string(result.Action) + " " + string(content)), nil
+	})
+
+	session := &sessionTestClientWithRequests{
+		sessionTestClient: sessionTestClient{
+			sessionID:           "session-1",
+			notificationChannel: make(chan mcp.JSONRPCNotification, 10),
+			initialized:         true,
+		},
+		requestChannel: make(chan mcp.JSONRPCRequest, 10),
+	}
+	ctx := server.WithContext(context.Background(), session)
+	callTool := []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"confirm"}}`)
+
+	// answer calls the tool and answers its elicitation request with the given response fields
+	answer := func(t *testing.T, response string) mcp.JSONRPCMessage {
+		t.Helper()
+		responses := make(chan mcp.JSONRPCMessage, 1)
+		go func() {
+			responses <- server.HandleMessage(ctx, callTool)
+		}()
+
+		var request mcp.JSONRPCRequest
+		select {
+		case request = <-session.requestChannel:
+		case <-time.After(time.Second):
+			t.Fatal("Expected an elicitation request")
+		}
+		assert.Equal(t, string(mcp.MethodElicitationCreate), request.Method)
+		params, ok := request.Params.(mcp.ElicitParams)
+		require.True(t, ok)
+		assert.Equal(t, "Proceed?", params.Message)
+		assert.Equal(t, "object", params.RequestedSchema.Type)
+
+		id, err := json.Marshal(request.ID)
+		require.NoError(t, err)
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,%s}`, id, response)
+		assert.Nil(t, server.HandleMessage(ctx, []byte(message)))
+
+		select {
+		case response := <-responses:
+			return response
+		case <-time.After(time.Second):
+			t.Fatal("Expected the tool call to complete")
+			return nil
+		}
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		response := answer(t, `"result":{"action":"accept","content":{"confirm":true}}`)
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		assert.Equal(t, `accept {"confirm":true}`, result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("decline drops content", func(t *testing.T) {
+		response := answer(t, `"result":{"action":"decline","content":{"confirm":true}}`)
+		result := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		assert.Equal(t, "decline null", result.Content[0].(mcp.TextContent).Text)
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		response := answer(t, `"error":{"code":-32601,"message":"method not found"}`)
+		errResp, ok := response.(mcp.JSONRPCError)
+		require.True(t, ok)
+		assert.Contains(t, errResp.Error.Message, "method not found")
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		response := answer(t, `"result":{"action":"maybe"}`)
+		_, ok := response.(mcp.JSONRPCError)
+		assert.True(t, ok)
+	})
+
+	t.Run("session without requests", func(t *testing.T) {
+		ctx := server.WithContext(context.Background(), &sessionTestClient{sessionID: "session-2", initialized: true})
+		_, err := server.RequestElicitation(ctx, "Proceed?", mcp.ToolInputSchema{})
+		assert.ErrorIs(t, err, ErrSessionDoesNotSupportRequests)
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := server.RequestElicitation(ctx, "Proceed?", mcp.ToolInputSchema{})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
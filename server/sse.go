@@ -22,14 +22,44 @@ import (
 // sseSession represents an active SSE connection.
 type sseSession struct {
 	done                chan struct{}
-	eventQueue          chan string // Channel for queuing events
+	closeOnce           sync.Once
+	eventQueue          chan string        // Channel for queuing events
+	flushes             chan chan struct{} // flush requests served by the notification handler
 	sessionID           string
 	requestID           atomic.Int64
 	notificationChannel chan mcp.JSONRPCNotification
+	requestChannel      chan mcp.JSONRPCRequest
 	initialized         atomic.Bool
 	loggingLevel        atomic.Value
 	tools               sync.Map     // stores session-specific tools
 	clientInfo          atomic.Value // stores session-specific client info
+	subscriptions       sync.Map     // stores subscribed resource URIs
+	pendingPings        sync.Map     // IDs of keepalive pings awaiting a response
+}
+
+// flushNotifications waits until the notifications and requests sent to the
+// session so far are queued, so that they precede the next queued response.
+func (s *sseSession) flushNotifications() {
+	if s.flushes == nil {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case s.flushes <- done:
+	case <-s.done:
+		return
+	}
+	select {
+	case <-done:
+	case <-s.done:
+	}
+}
+
+// close ends the session's event stream; it is safe to call more than once.
+func (s *sseSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
 }
 
 // SSEContextFunc is a function that takes an existing context and the current
@@ -52,9 +82,13 @@ func (s *sseSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
 	return s.notificationChannel
 }
 
+func (s *sseSession) RequestChannel() chan<- mcp.JSONRPCRequest {
+	return s.requestChannel
+}
+
 func (s *sseSession) Initialize() {
 	// set default logging level
-	s.loggingLevel.Store(mcp.LoggingLevelError)
+	s.loggingLevel.Store(mcp.LoggingLevelInfo)
 	s.initialized.Store(true)
 }
 
@@ -69,7 +103,7 @@ func (s *sseSession) SetLogLevel(level mcp.LoggingLevel) {
 func (s *sseSession) GetLogLevel() mcp.LoggingLevel {
 	level := s.loggingLevel.Load()
 	if level == nil {
-		return mcp.LoggingLevelError
+		return mcp.LoggingLevelInfo
 	}
 	return level.(mcp.LoggingLevel)
 }
@@ -108,11 +142,26 @@ func (s *sseSession) SetClientInfo(clientInfo mcp.Implementation) {
 	s.clientInfo.Store(clientInfo)
 }
 
+func (s *sseSession) SubscribeResource(uri string) {
+	s.subscriptions.Store(uri, struct{}{})
+}
+
+func (s *sseSession) UnsubscribeResource(uri string) {
+	s.subscriptions.Delete(uri)
+}
+
+func (s *sseSession) IsSubscribedToResource(uri string) bool {
+	_, ok := s.subscriptions.Load(uri)
+	return ok
+}
+
 var (
-	_ ClientSession         = (*sseSession)(nil)
-	_ SessionWithTools      = (*sseSession)(nil)
-	_ SessionWithLogging    = (*sseSession)(nil)
-	_ SessionWithClientInfo = (*sseSession)(nil)
+	_ ClientSession                    = (*sseSession)(nil)
+	_ SessionWithTools                 = (*sseSession)(nil)
+	_ SessionWithLogging               = (*sseSession)(nil)
+	_ SessionWithClientInfo            = (*sseSession)(nil)
+	_ SessionWithResourceSubscriptions = (*sseSession)(nil)
+	_ SessionWithRequests              = (*sseSession)(nil)
 )
 
 // SSEServer implements a Server-Sent Events (SSE) based MCP server.
@@ -132,6 +181,7 @@ type SSEServer struct {
 
 	keepAlive         bool
 	keepAliveInterval time.Duration
+	keepAliveTimeout  time.Duration
 
 	mu sync.RWMutex
 }
@@ -249,6 +299,17 @@ func WithKeepAlive(keepAlive bool) SSEOption {
 	}
 }
 
+// WithKeepAliveTimeout enables keepalive pings and closes a session whose client
+// does not answer a ping within timeout. This reaps connections that are dead but
+// still look open to TCP. The ping interval is set independently with
+// WithKeepAliveInterval. A timeout of 0, the default, never closes sessions.
+func WithKeepAliveTimeout(timeout time.Duration) SSEOption {
+	return func(s *SSEServer) {
+		s.keepAlive = true
+		s.keepAliveTimeout = timeout
+	}
+}
+
 // WithSSEContextFunc sets a function that will be called to customise the context
 // to the server using the incoming request.
 func WithSSEContextFunc(fn SSEContextFunc) SSEOption {
@@ -317,7 +378,7 @@ func (s *SSEServer) Shutdown(ctx context.Context) error {
 	if srv != nil {
 		s.sessions.Range(func(key, value any) bool {
 			if session, ok := value.(*sseSession); ok {
-				close(session.done)
+				session.close()
 			}
 			s.sessions.Delete(key)
 			return true
@@ -351,8 +412,10 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	session := &sseSession{
 		done:                make(chan struct{}),
 		eventQueue:          make(chan string, 100), // Buffer for events
+		flushes:             make(chan chan struct{}),
 		sessionID:           sessionID,
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		requestChannel:      make(chan mcp.JSONRPCRequest, 100),
 	}
 
 	s.sessions.Store(sessionID, session)
@@ -370,18 +433,46 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 
 	// Start notification handler for this session
 	go func() {
+		queue := func(message any) bool {
+			eventData, err := json.Marshal(message)
+			if err != nil {
+				return true
+			}
+			select {
+			case session.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", eventData):
+				// Event queued successfully
+				return true
+			case <-session.done:
+				return false
+			}
+		}
 		for {
 			select {
 			case notification := <-session.notificationChannel:
-				eventData, err := json.Marshal(notification)
-				if err == nil {
+				if !queue(notification) {
+					return
+				}
+			case request := <-session.requestChannel:
+				if !queue(request) {
+					return
+				}
+			case done := <-session.flushes:
+				// Queue what was sent so far before the response that asked for it
+				for drained := false; !drained; {
 					select {
-					case session.eventQueue <- fmt.Sprintf("event: message\ndata: %s\n\n", eventData):
-						// Event queued successfully
-					case <-session.done:
-						return
+					case notification := <-session.notificationChannel:
+						if !queue(notification) {
+							return
+						}
+					case request := <-session.requestChannel:
+						if !queue(request) {
+							return
+						}
+					default:
+						drained = true
 					}
 				}
+				close(done)
 			case <-session.done:
 				return
 			case <-r.Context().Done():
@@ -398,13 +489,17 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 			for {
 				select {
 				case <-ticker.C:
+					id := session.requestID.Add(1)
 					message := mcp.JSONRPCRequest{
 						JSONRPC: "2.0",
-						ID:      mcp.NewRequestId(session.requestID.Add(1)),
+						ID:      mcp.NewRequestId(id),
 						Request: mcp.Request{
 							Method: "ping",
 						},
 					}
+					if s.keepAliveTimeout > 0 {
+						s.expectPingResponse(session, id)
+					}
 					messageBytes, _ := json.Marshal(message)
 					pingMsg := fmt.Sprintf("event: message\ndata:%s\n\n", messageBytes)
 					select {
@@ -438,7 +533,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprint(w, event)
 			flusher.Flush()
 		case <-r.Context().Done():
-			close(session.done)
+			session.close()
 			return
 		case <-session.done:
 			return
@@ -446,6 +541,39 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// expectPingResponse closes the session unless the ping with the given ID is
+// answered within the keepalive timeout.
+func (s *SSEServer) expectPingResponse(session *sseSession, id int64) {
+	session.pendingPings.Store(id, struct{}{})
+	time.AfterFunc(s.keepAliveTimeout, func() {
+		if _, pending := session.pendingPings.LoadAndDelete(id); pending {
+			log.Printf("Session %s did not answer ping within %v, closing", session.sessionID, s.keepAliveTimeout)
+			session.close()
+		}
+	})
+}
+
+// responseID returns the numeric ID of a JSON-RPC response sent by the client.
+func responseID(message json.RawMessage) (int64, bool) {
+	var response struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(message, &response); err != nil {
+		return 0, false
+	}
+	if response.Method != "" || (response.Result == nil && response.Error == nil) {
+		return 0, false
+	}
+	var id float64
+	if err := json.Unmarshal(response.ID, &id); err != nil {
+		return 0, false
+	}
+	return int64(id), true
+}
+
 // GetMessageEndpointForClient returns the appropriate message endpoint URL with session ID
 // for the given request. This is the canonical way to compute the message endpoint for a client.
 // It handles both dynamic and static path modes, and honors the WithUseFullURLForMessageEndpoint flag.
@@ -496,6 +624,11 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Any response to a keepalive ping shows that the client is alive
+	if id, ok := responseID(rawMessage); ok {
+		session.pendingPings.Delete(id)
+	}
+
 	// Create a context that preserves all values from parent ctx but won't be canceled when the parent is canceled.
 	// this is required because the http ctx will be canceled when the client disconnects
 	detachedCtx := context.WithoutCancel(ctx)
@@ -513,6 +646,7 @@ func (s *SSEServer) handleMessage(w http.ResponseWriter, r *http.Request) {
 		response := s.server.HandleMessage(ctx, rawMessage)
 		// Only send response if there is one (not for notifications)
 		if response != nil {
+			session.flushNotifications()
 			var message string
 			if eventData, err := json.Marshal(response); err != nil {
 				// If there is an error marshalling the response, send a generic error response
@@ -881,6 +881,90 @@ func TestSSEServer(t *testing.T) {
 		}
 	})
 
+	t.Run("Closes sessions that do not answer pings", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0.0")
+		testServer := NewTestServer(mcpServer,
+			WithKeepAliveInterval(20*time.Millisecond),
+			WithKeepAliveTimeout(50*time.Millisecond),
+		)
+		defer testServer.Close()
+
+		sseResp, err := http.Get(fmt.Sprintf("%s/sse", testServer.URL))
+		if err != nil {
+			t.Fatalf("Failed to connect to SSE endpoint: %v", err)
+		}
+		defer sseResp.Body.Close()
+
+		// Ignore the pings: the server must end the stream
+		done := make(chan error, 1)
+		go func() {
+			_, err := io.Copy(io.Discard, sseResp.Body)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Expected the stream to end cleanly, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Session was not closed after unanswered ping")
+		}
+	})
+
+	t.Run("Keeps sessions that answer pings", func(t *testing.T) {
+		mcpServer := NewMCPServer("test", "1.0.0")
+		testServer := NewTestServer(mcpServer,
+			WithKeepAliveInterval(20*time.Millisecond),
+			WithKeepAliveTimeout(100*time.Millisecond),
+		)
+		defer testServer.Close()
+
+		sseResp, err := http.Get(fmt.Sprintf("%s/sse", testServer.URL))
+		if err != nil {
+			t.Fatalf("Failed to connect to SSE endpoint: %v", err)
+		}
+		defer sseResp.Body.Close()
+
+		ended := make(chan struct{})
+		go func() {
+			defer close(ended)
+			reader := bufio.NewReader(sseResp.Body)
+			var messageURL string
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				data, ok := strings.CutPrefix(line, "data:")
+				if !ok {
+					continue
+				}
+				data = strings.TrimSpace(data)
+				if messageURL == "" {
+					messageURL = data
+					continue
+				}
+				var ping map[string]any
+				if err := json.Unmarshal([]byte(data), &ping); err != nil || ping["method"] != "ping" {
+					continue
+				}
+				body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": ping["id"], "result": map[string]any{}})
+				resp, err := http.Post(messageURL, "application/json", bytes.NewReader(body))
+				if err != nil {
+					return
+				}
+				resp.Body.Close()
+			}
+		}()
+
+		select {
+		case <-ended:
+			t.Fatal("Session was closed although pings were answered")
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
+
 	t.Run("TestSSEHandlerWithDynamicMounting", func(t *testing.T) {
 		mcpServer := NewMCPServer("test", "1.0.0")
 		// MessageEndpointFunc that extracts tenant from the path using Go 1.22+ PathValue
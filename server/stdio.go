@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"sync/atomic"
 	"syscall"
 
@@ -28,6 +29,10 @@ type StdioServer struct {
 	server      *MCPServer
 	errLogger   *log.Logger
 	contextFunc StdioContextFunc
+
+	writeMu   sync.Mutex         // serializes writes of responses and notifications
+	toolCalls sync.WaitGroup     // tool calls dispatched concurrently
+	flushes   chan chan struct{} // flush requests served by the notification handler
 }
 
 // StdioOption defines a function type for configuring StdioServer
@@ -52,9 +57,11 @@ func WithStdioContextFunc(fn StdioContextFunc) StdioOption {
 // stdioSession is a static client session, since stdio has only one client.
 type stdioSession struct {
 	notifications chan mcp.JSONRPCNotification
+	requests      chan mcp.JSONRPCRequest
 	initialized   atomic.Bool
 	loggingLevel  atomic.Value
 	clientInfo    atomic.Value // stores session-specific client info
+	subscriptions sync.Map     // stores subscribed resource URIs
 }
 
 func (s *stdioSession) SessionID() string {
@@ -65,9 +72,13 @@ func (s *stdioSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
 	return s.notifications
 }
 
+func (s *stdioSession) RequestChannel() chan<- mcp.JSONRPCRequest {
+	return s.requests
+}
+
 func (s *stdioSession) Initialize() {
 	// set default logging level
-	s.loggingLevel.Store(mcp.LoggingLevelError)
+	s.loggingLevel.Store(mcp.LoggingLevelInfo)
 	s.initialized.Store(true)
 }
 
@@ -95,19 +106,35 @@ func (s *stdioSession) SetLogLevel(level mcp.LoggingLevel) {
 func (s *stdioSession) GetLogLevel() mcp.LoggingLevel {
 	level := s.loggingLevel.Load()
 	if level == nil {
-		return mcp.LoggingLevelError
+		return mcp.LoggingLevelInfo
 	}
 	return level.(mcp.LoggingLevel)
 }
 
+func (s *stdioSession) SubscribeResource(uri string) {
+	s.subscriptions.Store(uri, struct{}{})
+}
+
+func (s *stdioSession) UnsubscribeResource(uri string) {
+	s.subscriptions.Delete(uri)
+}
+
+func (s *stdioSession) IsSubscribedToResource(uri string) bool {
+	_, ok := s.subscriptions.Load(uri)
+	return ok
+}
+
 var (
-	_ ClientSession         = (*stdioSession)(nil)
-	_ SessionWithLogging    = (*stdioSession)(nil)
-	_ SessionWithClientInfo = (*stdioSession)(nil)
+	_ ClientSession                    = (*stdioSession)(nil)
+	_ SessionWithLogging               = (*stdioSession)(nil)
+	_ SessionWithClientInfo            = (*stdioSession)(nil)
+	_ SessionWithResourceSubscriptions = (*stdioSession)(nil)
+	_ SessionWithRequests              = (*stdioSession)(nil)
 )
 
 var stdioSessionInstance = stdioSession{
 	notifications: make(chan mcp.JSONRPCNotification, 100),
+	requests:      make(chan mcp.JSONRPCRequest, 100),
 }
 
 // NewStdioServer creates a new stdio server wrapper around an MCPServer.
@@ -136,8 +163,8 @@ func (s *StdioServer) SetContextFunc(fn StdioContextFunc) {
 	s.contextFunc = fn
 }
 
-// handleNotifications continuously processes notifications from the session's notification channel
-// and writes them to the provided output. It runs until the context is cancelled.
+// handleNotifications continuously processes notifications and requests from the session's
+// channels and writes them to the provided output. It runs until the context is cancelled.
 // Any errors encountered while writing notifications are logged but do not stop the handler.
 func (s *StdioServer) handleNotifications(ctx context.Context, stdout io.Writer) {
 	for {
@@ -146,12 +173,55 @@ func (s *StdioServer) handleNotifications(ctx context.Context, stdout io.Writer)
 			if err := s.writeResponse(notification, stdout); err != nil {
 				s.errLogger.Printf("Error writing notification: %v", err)
 			}
+		case request := <-stdioSessionInstance.requests:
+			if err := s.writeResponse(request, stdout); err != nil {
+				s.errLogger.Printf("Error writing request: %v", err)
+			}
+		case done := <-s.flushes:
+			s.drainNotifications(stdout)
+			close(done)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// drainNotifications writes the notifications and requests queued on the session.
+func (s *StdioServer) drainNotifications(stdout io.Writer) {
+	for {
+		select {
+		case notification := <-stdioSessionInstance.notifications:
+			if err := s.writeResponse(notification, stdout); err != nil {
+				s.errLogger.Printf("Error writing notification: %v", err)
+			}
+		case request := <-stdioSessionInstance.requests:
+			if err := s.writeResponse(request, stdout); err != nil {
+				s.errLogger.Printf("Error writing request: %v", err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// flushNotifications waits until the notification handler wrote the notifications
+// queued so far, so that those sent while handling a request precede its response.
+func (s *StdioServer) flushNotifications(ctx context.Context) {
+	if s.flushes == nil {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case s.flushes <- done:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
 // processInputStream continuously reads and processes messages from the input stream.
 // It handles EOF gracefully as a normal termination condition.
 // The function returns when either:
@@ -232,8 +302,13 @@ func (s *StdioServer) Listen(
 	reader := bufio.NewReader(stdin)
 
 	// Start notification handler
+	s.flushes = make(chan chan struct{})
 	go s.handleNotifications(ctx, stdout)
-	return s.processInputStream(ctx, reader, stdout)
+	err := s.processInputStream(ctx, reader, stdout)
+
+	// Let concurrently dispatched tool calls write their responses
+	s.toolCalls.Wait()
+	return err
 }
 
 // processMessage handles a single JSON-RPC message and writes the response.
@@ -256,11 +331,33 @@ func (s *StdioServer) processMessage(
 		return s.writeResponse(response, writer)
 	}
 
-	// Handle the message using the wrapped server
-	response := s.server.HandleMessage(ctx, rawMessage)
+	// Tool calls run in the background so they don't block the other messages
+	// of the session, and can wait for responses to their requests to the client
+	if isToolCall(rawMessage) {
+		s.toolCalls.Add(1)
+		go func() {
+			defer s.toolCalls.Done()
+			if response := s.server.HandleMessage(ctx, rawMessage); response != nil {
+				s.flushNotifications(ctx)
+				if err := s.writeResponse(response, writer); err != nil {
+					s.errLogger.Printf("Error writing response: %v", err)
+				}
+			}
+		}()
+		return nil
+	}
+
+	// Handle the message using the wrapped server. Requests sent to the client
+	// meanwhile would never see their response, which is read by this loop, so
+	// they fail with ErrClientResponseBlocked.
+	handling := &atomic.Bool{}
+	handling.Store(true)
+	response := s.server.HandleMessage(context.WithValue(ctx, readLoopKey{}, handling), rawMessage)
+	handling.Store(false)
 
 	// Only write response if there is one (not for notifications)
 	if response != nil {
+		s.flushNotifications(ctx)
 		if err := s.writeResponse(response, writer); err != nil {
 			return fmt.Errorf("failed to write response: %w", err)
 		}
@@ -269,6 +366,17 @@ func (s *StdioServer) processMessage(
 	return nil
 }
 
+// readLoopKey is the context key of the messages handled on the read loop of
+// a StdioServer. Its value reports whether the message is still being handled.
+type readLoopKey struct{}
+
+// blocksReadLoop reports whether ctx belongs to a message still being handled
+// on the read loop of a StdioServer, which cannot read responses until then.
+func blocksReadLoop(ctx context.Context) bool {
+	handling, ok := ctx.Value(readLoopKey{}).(*atomic.Bool)
+	return ok && handling.Load()
+}
+
 // writeResponse marshals and writes a JSON-RPC response message followed by a newline.
 // Returns an error if marshaling or writing fails.
 func (s *StdioServer) writeResponse(
@@ -280,6 +388,9 @@ func (s *StdioServer) writeResponse(
 		return err
 	}
 
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	// Write response followed by newline
 	if _, err := fmt.Fprintf(writer, "%s\n", responseBytes); err != nil {
 		return err
@@ -288,6 +399,18 @@ func (s *StdioServer) writeResponse(
 	return nil
 }
 
+// isToolCall reports whether the message is a tools/call request.
+func isToolCall(message json.RawMessage) bool {
+	var baseMessage struct {
+		Method mcp.MCPMethod `json:"method"`
+		ID     any           `json:"id"`
+	}
+	if err := json.Unmarshal(message, &baseMessage); err != nil {
+		return false
+	}
+	return baseMessage.ID != nil && baseMessage.Method == mcp.MethodToolsCall
+}
+
 // ServeStdio is a convenience function that creates and starts a StdioServer with os.Stdin and os.Stdout.
 // It sets up signal handling for graceful shutdown on SIGTERM and SIGINT.
 // Returns an error if the server encounters any issues during operation.
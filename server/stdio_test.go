@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mathiasXie/mcp-go/mcp"
 )
@@ -268,3 +271,158 @@ func TestStdioServer(t *testing.T) {
 		}
 	})
 }
+
+func TestStdioServer_ConcurrentToolCalls(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	release := make(chan struct{})
+	mcpServer := NewMCPServer("test", "1.0.0", WithMaxConcurrentTools(2))
+	mcpServer.AddTool(mcp.NewTool("slow"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-release
+		return mcp.NewToolResultText("done"), nil
+	})
+	stdioServer := NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverErrCh := make(chan error, 1)
+	go func() {
+		serverErrCh <- stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+
+	messages := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}
+	for _, message := range messages {
+		if _, err := stdinWriter.Write([]byte(message + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scanner := bufio.NewScanner(stdoutReader)
+	readID := func() float64 {
+		if !scanner.Scan() {
+			t.Fatal("failed to read response")
+		}
+		var response map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return response["id"].(float64)
+	}
+
+	// The ping is answered while the tool call is still running
+	if id := readID(); id != 2 {
+		t.Errorf("expected ping response first, got response for id %v", id)
+	}
+	close(release)
+	if id := readID(); id != 1 {
+		t.Errorf("expected tool call response, got response for id %v", id)
+	}
+
+	stdinWriter.Close()
+	if err := <-serverErrCh; err != nil {
+		t.Errorf("unexpected server error: %v", err)
+	}
+}
+
+func TestStdioServer_RequestsToClient(t *testing.T) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+
+	mcpServer := NewMCPServer("test", "1.0.0", WithPromptCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("confirm"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := mcpServer.RequestElicitation(ctx, "Proceed?", mcp.ToolInputSchema{})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(string(result.Action)), nil
+	})
+	mcpServer.AddPrompt(mcp.NewPrompt("roots"), func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if _, err := mcpServer.RequestRoots(ctx); err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{}, nil
+	})
+	stdioServer := NewStdioServer(mcpServer)
+	stdioServer.SetErrorLogger(log.New(io.Discard, "", 0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	serverErrCh := make(chan error, 1)
+	go func() {
+		serverErrCh <- stdioServer.Listen(ctx, stdinReader, stdoutWriter)
+		stdoutWriter.Close()
+	}()
+
+	// Messages are written in order, without waiting for the server to read them
+	outgoing := make(chan string, 10)
+	defer close(outgoing)
+	go func() {
+		for message := range outgoing {
+			if _, err := stdinWriter.Write([]byte(message + "\n")); err != nil {
+				return
+			}
+		}
+	}()
+	send := func(message string) {
+		outgoing <- message
+	}
+	scanner := bufio.NewScanner(stdoutReader)
+	read := func() map[string]any {
+		t.Helper()
+		if !scanner.Scan() {
+			t.Fatal("failed to read message")
+		}
+		var message map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatalf("failed to unmarshal message: %v", err)
+		}
+		return message
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"}}}`)
+	read()
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	// Tool calls run off the read loop, which reads the answer to the elicitation
+	send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"confirm"}}`)
+	request := read()
+	if request["method"] != string(mcp.MethodElicitationCreate) {
+		t.Fatalf("expected an elicitation request, got %v", request)
+	}
+	answer, _ := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      request["id"],
+		"result":  map[string]any{"action": "accept", "content": map[string]any{}},
+	})
+	send(string(answer))
+	response := read()
+	if response["id"] != float64(2) {
+		t.Fatalf("expected the tool call response, got %v", response)
+	}
+	content := response["result"].(map[string]any)["content"].([]any)[0].(map[string]any)
+	if content["text"] != "accept" {
+		t.Errorf("expected the elicitation to be accepted, got %v", response["result"])
+	}
+
+	// Other messages are handled on the read loop, so they fail instead of
+	// waiting for a response the loop cannot read
+	send(`{"jsonrpc":"2.0","id":3,"method":"prompts/get","params":{"name":"roots"}}`)
+	response = read()
+	errorObject, ok := response["error"].(map[string]any)
+	if !ok || !strings.Contains(fmt.Sprint(errorObject["message"]), ErrClientResponseBlocked.Error()) {
+		t.Errorf("expected the prompt to fail with ErrClientResponseBlocked, got %v", response)
+	}
+
+	stdinWriter.Close()
+	if err := <-serverErrCh; err != nil {
+		t.Errorf("unexpected server error: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -85,6 +86,23 @@ func WithStreamableHTTPServer(srv *http.Server) StreamableHTTPOption {
 	}
 }
 
+// defaultSessionIdleTimeout bounds how long the state of abandoned sessions is kept.
+const defaultSessionIdleTimeout = 30 * time.Minute
+
+// WithSessionIdleTimeout expires sessions that have not received a request for
+// the given duration and have no request in progress or open GET stream. Expired sessions are
+// terminated through the SessionIdManager and their state (session tools,
+// resource subscriptions, log level) is freed; later requests carrying their
+// session ID get a 404, which tells the client to initialize a new session.
+// Expired sessions are swept while the server handles requests, so no
+// goroutine outlives it. The default is 30 minutes; a timeout of zero or less
+// keeps sessions until they are deleted by the client.
+func WithSessionIdleTimeout(timeout time.Duration) StreamableHTTPOption {
+	return func(s *StreamableHTTPServer) {
+		s.sessionIdleTimeout = timeout
+	}
+}
+
 // WithLogger sets the logger for the server
 func WithLogger(logger util.Logger) StreamableHTTPOption {
 	return func(s *StreamableHTTPServer) {
@@ -117,9 +135,12 @@ func WithLogger(logger util.Logger) StreamableHTTPOption {
 //   - Batching of requests/notifications/responses in arrays.
 //   - Stream Resumability
 type StreamableHTTPServer struct {
-	server            *MCPServer
-	sessionTools      *sessionToolsStore
-	sessionRequestIDs sync.Map // sessionId --> last requestID(*atomic.Int64)
+	server               *MCPServer
+	sessionTools         *sessionToolsStore
+	sessionSubscriptions *sessionSubscriptionsStore
+	sessionLogLevels     *sessionLogLevelsStore
+	sessionRequestIDs    sync.Map // sessionId --> last requestID(*atomic.Int64)
+	sessionStates        sync.Map // sessionId --> *sessionState, for the sessions issued and not deleted or expired
 
 	httpServer *http.Server
 	mu         sync.RWMutex
@@ -128,17 +149,23 @@ type StreamableHTTPServer struct {
 	contextFunc             HTTPContextFunc
 	sessionIdManager        SessionIdManager
 	listenHeartbeatInterval time.Duration
+	sessionIdleTimeout      time.Duration
 	logger                  util.Logger
+
+	lastExpiry atomic.Int64 // unix nanoseconds of the last sweep of idle sessions
 }
 
 // NewStreamableHTTPServer creates a new streamable-http server instance
 func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *StreamableHTTPServer {
 	s := &StreamableHTTPServer{
-		server:           server,
-		sessionTools:     newSessionToolsStore(),
-		endpointPath:     "/mcp",
-		sessionIdManager: &InsecureStatefulSessionIdManager{},
-		logger:           util.DefaultLogger(),
+		server:               server,
+		sessionTools:         newSessionToolsStore(),
+		sessionSubscriptions: newSessionSubscriptionsStore(),
+		sessionLogLevels:     newSessionLogLevelsStore(),
+		endpointPath:         "/mcp",
+		sessionIdManager:     &InsecureStatefulSessionIdManager{},
+		sessionIdleTimeout:   defaultSessionIdleTimeout,
+		logger:               util.DefaultLogger(),
 	}
 
 	// Apply all options
@@ -150,6 +177,7 @@ func NewStreamableHTTPServer(server *MCPServer, opts ...StreamableHTTPOption) *S
 
 // ServeHTTP implements the http.Handler interface.
 func (s *StreamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.expireIdleSessions()
 	switch r.Method {
 	case http.MethodPost:
 		s.handlePost(w, r)
@@ -191,7 +219,6 @@ func (s *StreamableHTTPServer) Start(addr string) error {
 // Shutdown gracefully stops the server, closing all active sessions
 // and shutting down the HTTP server.
 func (s *StreamableHTTPServer) Shutdown(ctx context.Context) error {
-
 	// shutdown the server if needed (may use as a http.Handler)
 	s.mu.RLock()
 	srv := s.httpServer
@@ -219,7 +246,22 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	}
 
 	// Check the request body is valid json, meanwhile, get the request Method
-	rawData, err := io.ReadAll(r.Body)
+	body := io.Reader(r.Body)
+	switch encoding := r.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, fmt.Sprintf("read request body error: %v", err))
+			return
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		http.Error(w, fmt.Sprintf("Unsupported content encoding: %s", encoding), http.StatusUnsupportedMediaType)
+		return
+	}
+	rawData, err := io.ReadAll(body)
 	if err != nil {
 		s.writeJSONRPCError(w, nil, mcp.PARSE_ERROR, fmt.Sprintf("read request body error: %v", err))
 		return
@@ -237,25 +279,36 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	// The session is ephemeral. Its life is the same as the request. It's only created
 	// for interaction with the mcp server.
 	var sessionID string
+	var state *sessionState
 	if isInitializeRequest {
 		// generate a new one for initialize request
 		sessionID = s.sessionIdManager.Generate()
+		if sessionID != "" {
+			state = &sessionState{}
+			state.touch()
+			s.sessionStates.Store(sessionID, state)
+		}
 	} else {
 		// Get session ID from header.
 		// Stateful servers need the client to carry the session ID.
 		sessionID = r.Header.Get(headerKeySessionID)
-		isTerminated, err := s.sessionIdManager.Validate(sessionID)
-		if err != nil {
-			http.Error(w, "Invalid session ID", http.StatusBadRequest)
-			return
-		}
-		if isTerminated {
-			http.Error(w, "Session terminated", http.StatusNotFound)
+		var ok bool
+		if state, ok = s.validateSession(w, sessionID); !ok {
 			return
 		}
 	}
 
-	session := newStreamableHttpSession(sessionID, s.sessionTools)
+	if state != nil {
+		// A session with a request in progress is not idle
+		state.active.Add(1)
+		defer func() {
+			state.touch()
+			state.active.Add(-1)
+		}()
+	}
+
+	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionSubscriptions, s.sessionLogLevels)
+	session.state = state
 
 	// Set the client context before handling the message
 	ctx := s.server.WithContext(r.Context(), session)
@@ -264,46 +317,49 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 	}
 
 	// handle potential notifications
-	mu := sync.Mutex{}
 	upgradedHeader := false
-	done := make(chan struct{})
+	upgrade := func() {
+		if !upgradedHeader {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Connection", "keep-alive")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusAccepted)
+			upgradedHeader = true
+		}
+	}
+	writeNotification := func(message any) {
+		// if there's notifications or requests, upgradedHeader to SSE response
+		upgrade()
+		if err := writeSSEEvent(w, message); err != nil {
+			s.logger.Errorf("Failed to write SSE event: %v", err)
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+	handled := make(chan struct{})
+	notified := make(chan struct{})
 
 	go func() {
+		defer close(notified)
 		for {
 			select {
 			case nt := <-session.notificationChannel:
-				func() {
-					mu.Lock()
-					defer mu.Unlock()
-					// if the done chan is closed, as the request is terminated, just return
+				writeNotification(nt)
+			case req := <-session.requestChannel:
+				writeNotification(req)
+			case <-handled:
+				// notifications sent while handling the request precede the response
+				for {
 					select {
-					case <-done:
-						return
+					case nt := <-session.notificationChannel:
+						writeNotification(nt)
+					case req := <-session.requestChannel:
+						writeNotification(req)
 					default:
-					}
-					defer func() {
-						flusher, ok := w.(http.Flusher)
-						if ok {
-							flusher.Flush()
-						}
-					}()
-
-					// if there's notifications, upgradedHeader to SSE response
-					if !upgradedHeader {
-						w.Header().Set("Content-Type", "text/event-stream")
-						w.Header().Set("Connection", "keep-alive")
-						w.Header().Set("Cache-Control", "no-cache")
-						w.WriteHeader(http.StatusAccepted)
-						upgradedHeader = true
-					}
-					err := writeSSEEvent(w, nt)
-					if err != nil {
-						s.logger.Errorf("Failed to write SSE event: %v", err)
 						return
 					}
-				}()
-			case <-done:
-				return
+				}
 			case <-ctx.Done():
 				return
 			}
@@ -312,29 +368,23 @@ func (s *StreamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request
 
 	// Process message through MCPServer
 	response := s.server.HandleMessage(ctx, rawData)
+	close(handled)
+	<-notified
 	if response == nil {
 		// For notifications, just send 202 Accepted with no body
-		w.WriteHeader(http.StatusAccepted)
+		if !upgradedHeader {
+			w.WriteHeader(http.StatusAccepted)
+		}
 		return
 	}
 
 	// Write response
-	mu.Lock()
-	defer mu.Unlock()
-	// close the done chan before unlock
-	defer close(done)
 	if ctx.Err() != nil {
 		return
 	}
 	// If client-server communication already upgraded to SSE stream
 	if session.upgradeToSSE.Load() {
-		if !upgradedHeader {
-			w.Header().Set("Content-Type", "text/event-stream")
-			w.Header().Set("Connection", "keep-alive")
-			w.Header().Set("Cache-Control", "no-cache")
-			w.WriteHeader(http.StatusAccepted)
-			upgradedHeader = true
-		}
+		upgrade()
 		if err := writeSSEEvent(w, response); err != nil {
 			s.logger.Errorf("Failed to write final SSE response event: %v", err)
 		}
@@ -357,20 +407,34 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 	// https://modelcontextprotocol.io/specification/2025-03-26/basic/transports#listening-for-messages-from-the-server
 
 	sessionID := r.Header.Get(headerKeySessionID)
-	// the specification didn't say we should validate the session id
-
+	var state *sessionState
 	if sessionID == "" {
 		// It's a stateless server,
 		// but the MCP server requires a unique ID for registering, so we use a random one
 		sessionID = uuid.New().String()
+	} else {
+		var ok bool
+		if state, ok = s.validateSession(w, sessionID); !ok {
+			return
+		}
+	}
+	if state != nil {
+		// A session with an open stream is not idle
+		state.active.Add(1)
+		defer func() {
+			state.touch()
+			state.active.Add(-1)
+		}()
 	}
 
-	session := newStreamableHttpSession(sessionID, s.sessionTools)
+	session := newStreamableHttpSession(sessionID, s.sessionTools, s.sessionSubscriptions, s.sessionLogLevels)
+	session.state = state
 	if err := s.server.RegisterSession(r.Context(), session); err != nil {
 		http.Error(w, fmt.Sprintf("Session registration failed: %v", err), http.StatusBadRequest)
 		return
 	}
-	defer s.server.UnregisterSession(r.Context(), sessionID)
+	// The session outlives the listening connection, it ends with a DELETE request
+	defer s.server.unregisterSession(r.Context(), sessionID)
 
 	// Set the client context before handling the message
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -399,6 +463,12 @@ func (s *StreamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request)
 				case <-done:
 					return
 				}
+			case req := <-session.requestChannel:
+				select {
+				case writeChan <- req:
+				case <-done:
+					return
+				}
 			case <-done:
 				return
 			}
@@ -466,13 +536,102 @@ func (s *StreamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	s.deleteSession(sessionID)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// validateSession checks the session ID of a request, writing a 400 response
+// for invalid IDs and a 404 response for sessions that are terminated,
+// expired or were not issued by this server, which tells the client to
+// initialize a new session. It returns the state of the session, which is nil
+// for stateless requests.
+func (s *StreamableHTTPServer) validateSession(w http.ResponseWriter, sessionID string) (*sessionState, bool) {
+	isTerminated, err := s.sessionIdManager.Validate(sessionID)
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return nil, false
+	}
+	// Stateless servers ignore session IDs, there is no session to look up
+	if _, stateless := s.sessionIdManager.(*StatelessSessionIdManager); stateless || (sessionID == "" && !isTerminated) {
+		return nil, true
+	}
+	var state *sessionState
+	if value, ok := s.sessionStates.Load(sessionID); ok {
+		state = value.(*sessionState)
+		if s.isExpired(state) {
+			s.expireSession(sessionID)
+			state = nil
+		}
+	}
+	if isTerminated || state == nil {
+		s.writeSessionNotFound(w)
+		return nil, false
+	}
+	state.touch()
+	return state, true
+}
+
+// writeSessionNotFound writes the 404 response that tells the client to
+// initialize a new session.
+func (s *StreamableHTTPServer) writeSessionNotFound(w http.ResponseWriter) {
+	response := createErrorResponse(nil, mcp.INVALID_REQUEST, "Session not found or expired, send a new initialize request")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Errorf("Failed to write session not found response: %v", err)
+	}
+}
+
+// deleteSession frees the state of a session.
+func (s *StreamableHTTPServer) deleteSession(sessionID string) {
+	s.sessionStates.Delete(sessionID)
+
 	// remove the session relateddata from the sessionToolsStore
 	s.sessionTools.delete(sessionID)
 
+	// remove the session's resource subscriptions and log level
+	s.sessionSubscriptions.delete(sessionID)
+	s.sessionLogLevels.delete(sessionID)
+
 	// remove current session's requstID information
 	s.sessionRequestIDs.Delete(sessionID)
 
-	w.WriteHeader(http.StatusOK)
+	s.server.sessionDisconnected(sessionID)
+}
+
+// isExpired reports whether a session has been idle for longer than the idle timeout.
+func (s *StreamableHTTPServer) isExpired(state *sessionState) bool {
+	return s.sessionIdleTimeout > 0 &&
+		state.active.Load() == 0 &&
+		time.Since(time.Unix(0, state.lastActive.Load())) > s.sessionIdleTimeout
+}
+
+// expireSession terminates an idle session and frees its state.
+func (s *StreamableHTTPServer) expireSession(sessionID string) {
+	if _, err := s.sessionIdManager.Terminate(sessionID); err != nil {
+		s.logger.Errorf("Failed to terminate expired session %s: %v", sessionID, err)
+	}
+	s.deleteSession(sessionID)
+}
+
+// expireIdleSessions expires the sessions that exceeded the idle timeout. It
+// runs at the start of requests, sweeping at most once per half timeout.
+func (s *StreamableHTTPServer) expireIdleSessions() {
+	if s.sessionIdleTimeout <= 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := s.lastExpiry.Load()
+	if now-last < int64(s.sessionIdleTimeout/2) || !s.lastExpiry.CompareAndSwap(last, now) {
+		return
+	}
+	s.sessionStates.Range(func(key, value any) bool {
+		if s.isExpired(value.(*sessionState)) {
+			s.expireSession(key.(string))
+		}
+		return true
+	})
 }
 
 func writeSSEEvent(w io.Writer, data any) error {
@@ -541,21 +700,107 @@ func (s *sessionToolsStore) delete(sessionID string) {
 	delete(s.tools, sessionID)
 }
 
+type sessionSubscriptionsStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[string]struct{} // sessionID -> resource URI set
+}
+
+func newSessionSubscriptionsStore() *sessionSubscriptionsStore {
+	return &sessionSubscriptionsStore{
+		subscriptions: make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *sessionSubscriptionsStore) subscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscriptions[sessionID] == nil {
+		s.subscriptions[sessionID] = make(map[string]struct{})
+	}
+	s.subscriptions[sessionID][uri] = struct{}{}
+}
+
+func (s *sessionSubscriptionsStore) unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions[sessionID], uri)
+}
+
+func (s *sessionSubscriptionsStore) isSubscribed(sessionID, uri string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.subscriptions[sessionID][uri]
+	return ok
+}
+
+func (s *sessionSubscriptionsStore) delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, sessionID)
+}
+
+type sessionLogLevelsStore struct {
+	levels sync.Map // sessionID -> mcp.LoggingLevel
+}
+
+func newSessionLogLevelsStore() *sessionLogLevelsStore {
+	return &sessionLogLevelsStore{}
+}
+
+func (s *sessionLogLevelsStore) get(sessionID string) mcp.LoggingLevel {
+	if level, ok := s.levels.Load(sessionID); ok {
+		return level.(mcp.LoggingLevel)
+	}
+	return mcp.LoggingLevelInfo
+}
+
+func (s *sessionLogLevelsStore) set(sessionID string, level mcp.LoggingLevel) {
+	s.levels.Store(sessionID, level)
+}
+
+func (s *sessionLogLevelsStore) delete(sessionID string) {
+	s.levels.Delete(sessionID)
+}
+
+// sessionState is the state of a session created by an initialize request,
+// kept until the session is deleted or expires.
+type sessionState struct {
+	initialized atomic.Bool
+	lastActive  atomic.Int64 // unix nanoseconds of the last request
+	active      atomic.Int32 // requests in progress and open GET streams
+}
+
+func (s *sessionState) touch() {
+	s.lastActive.Store(time.Now().UnixNano())
+}
+
 // streamableHttpSession is a session for streamable-http transport
 // When in POST handlers(request/notification), it's ephemeral, and only exists in the life of the request handler.
 // When in GET handlers(listening), it's a real session, and will be registered in the MCP server.
 type streamableHttpSession struct {
 	sessionID           string
 	notificationChannel chan mcp.JSONRPCNotification // server -> client notifications
+	requestChannel      chan mcp.JSONRPCRequest      // server -> client requests
 	tools               *sessionToolsStore
+	subscriptions       *sessionSubscriptionsStore
+	logLevels           *sessionLogLevelsStore
+	state               *sessionState // nil for stateless sessions
 	upgradeToSSE        atomic.Bool
 }
 
-func newStreamableHttpSession(sessionID string, toolStore *sessionToolsStore) *streamableHttpSession {
+func newStreamableHttpSession(
+	sessionID string,
+	toolStore *sessionToolsStore,
+	subscriptionStore *sessionSubscriptionsStore,
+	logLevelStore *sessionLogLevelsStore,
+) *streamableHttpSession {
 	return &streamableHttpSession{
 		sessionID:           sessionID,
 		notificationChannel: make(chan mcp.JSONRPCNotification, 100),
+		requestChannel:      make(chan mcp.JSONRPCRequest, 100),
 		tools:               toolStore,
+		subscriptions:       subscriptionStore,
+		logLevels:           logLevelStore,
 	}
 }
 
@@ -567,14 +812,22 @@ func (s *streamableHttpSession) NotificationChannel() chan<- mcp.JSONRPCNotifica
 	return s.notificationChannel
 }
 
+func (s *streamableHttpSession) RequestChannel() chan<- mcp.JSONRPCRequest {
+	return s.requestChannel
+}
+
+var _ SessionWithRequests = (*streamableHttpSession)(nil)
+
 func (s *streamableHttpSession) Initialize() {
-	// do nothing
-	// the session is ephemeral, no real initialized action needed
+	// the session is ephemeral, the initialized flag is kept with the session state
+	if s.state != nil {
+		s.state.initialized.Store(true)
+	}
 }
 
 func (s *streamableHttpSession) Initialized() bool {
-	// the session is ephemeral, no real initialized action needed
-	return true
+	// sessions without state, e.g. stateless ones, are always considered initialized
+	return s.state == nil || s.state.initialized.Load()
 }
 
 var _ ClientSession = (*streamableHttpSession)(nil)
@@ -589,6 +842,30 @@ func (s *streamableHttpSession) SetSessionTools(tools map[string]ServerTool) {
 
 var _ SessionWithTools = (*streamableHttpSession)(nil)
 
+func (s *streamableHttpSession) SubscribeResource(uri string) {
+	s.subscriptions.subscribe(s.sessionID, uri)
+}
+
+func (s *streamableHttpSession) UnsubscribeResource(uri string) {
+	s.subscriptions.unsubscribe(s.sessionID, uri)
+}
+
+func (s *streamableHttpSession) IsSubscribedToResource(uri string) bool {
+	return s.subscriptions.isSubscribed(s.sessionID, uri)
+}
+
+var _ SessionWithResourceSubscriptions = (*streamableHttpSession)(nil)
+
+func (s *streamableHttpSession) SetLogLevel(level mcp.LoggingLevel) {
+	s.logLevels.set(s.sessionID, level)
+}
+
+func (s *streamableHttpSession) GetLogLevel() mcp.LoggingLevel {
+	return s.logLevels.get(s.sessionID)
+}
+
+var _ SessionWithLogging = (*streamableHttpSession)(nil)
+
 func (s *streamableHttpSession) UpgradeToSSEWhenReceiveNotification() {
 	s.upgradeToSSE.Store(true)
 }
@@ -3,6 +3,7 @@ package server
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -123,6 +124,50 @@ func TestStreamableHTTP_POST_InvalidContent(t *testing.T) {
 	})
 }
 
+func TestStreamableHTTP_POST_CompressedBody(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	server := NewTestStreamableHTTPServer(mcpServer)
+	defer server.Close()
+
+	t.Run("gzip body is decompressed", func(t *testing.T) {
+		var body bytes.Buffer
+		zw := gzip.NewWriter(&body)
+		_ = json.NewEncoder(zw).Encode(initRequest)
+		_ = zw.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL, &body)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get(headerKeySessionID) == "" {
+			t.Errorf("Expected session id in header")
+		}
+	})
+
+	t.Run("unsupported encoding is rejected", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "br")
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send message: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status 415, got %d", resp.StatusCode)
+		}
+	})
+}
+
 func TestStreamableHTTP_POST_SendAndReceive(t *testing.T) {
 	mcpServer := NewMCPServer("test-mcp-server", "1.0")
 	addSSETool(mcpServer)
@@ -781,3 +826,459 @@ func postJSON(url string, bodyObject any) (*http.Response, error) {
 	req.Header.Set("Content-Type", "application/json")
 	return http.DefaultClient.Do(req)
 }
+
+func TestStreamableHTTP_ResourceSubscriptions(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0", WithResourceCapabilities(true, false))
+	streamableServer := NewStreamableHTTPServer(mcpServer)
+	server := httptest.NewServer(streamableServer)
+	defer server.Close()
+
+	// initialize to get a session id
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to send initialize request: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+	if sessionID == "" {
+		t.Fatal("Expected session id in header")
+	}
+
+	post := func(body map[string]any) *http.Response {
+		t.Helper()
+		jsonBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		return resp
+	}
+
+	resp = post(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      2,
+		"method":  "resources/subscribe",
+		"params":  map[string]any{"uri": "test://resource"},
+	})
+	var response jsonRPCResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if response.Error != nil {
+		t.Fatalf("Subscribe failed: %+v", response.Error)
+	}
+
+	// the listening GET connection receives the updates
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	getResp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open GET connection: %v", err)
+	}
+	defer getResp.Body.Close()
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		mcpServer.NotifyResourceUpdated("test://unrelated")
+		mcpServer.NotifyResourceUpdated("test://resource")
+	}()
+
+	reader := bufio.NewReader(getResp.Body)
+	_, _ = reader.ReadBytes('\n') // skip event type
+	data, err := reader.ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("Failed to read notification: %v", err)
+	}
+	if !strings.Contains(string(data), "notifications/resources/updated") || !strings.Contains(string(data), "test://resource") {
+		t.Fatalf("Expected resource updated notification, got %s", data)
+	}
+	if strings.Contains(string(data), "test://unrelated") {
+		t.Errorf("Expected no notification for unsubscribed resource, got %s", data)
+	}
+
+	// terminating the session removes its subscriptions
+	req, _ = http.NewRequest(http.MethodDelete, server.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	delResp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	delResp.Body.Close()
+
+	if streamableServer.sessionSubscriptions.isSubscribed(sessionID, "test://resource") {
+		t.Error("Expected subscriptions to be removed after DELETE")
+	}
+}
+
+func TestStreamableHTTP_SessionHooks(t *testing.T) {
+	var mu sync.Mutex
+	var connected, disconnected []string
+	mcpServer := NewMCPServer("test-mcp-server", "1.0", WithSessionHooks(
+		func(sessionID string, request mcp.InitializeRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			connected = append(connected, sessionID)
+		},
+		func(sessionID string) {
+			mu.Lock()
+			defer mu.Unlock()
+			disconnected = append(disconnected, sessionID)
+		},
+	))
+	server := httptest.NewServer(NewStreamableHTTPServer(mcpServer))
+	defer server.Close()
+
+	resp, err := postJSON(server.URL, initRequest)
+	if err != nil {
+		t.Fatalf("Failed to send initialize request: %v", err)
+	}
+	resp.Body.Close()
+	sessionID := resp.Header.Get(headerKeySessionID)
+
+	// Closing the listening connection does not end the session
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	getResp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open GET connection: %v", err)
+	}
+	cancel()
+	getResp.Body.Close()
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	if len(connected) != 1 || connected[0] != sessionID {
+		t.Errorf("Expected connect for session %s, got %v", sessionID, connected)
+	}
+	if len(disconnected) != 0 {
+		t.Errorf("Expected no disconnect before DELETE, got %v", disconnected)
+	}
+	mu.Unlock()
+
+	req, _ = http.NewRequest(http.MethodDelete, server.URL, nil)
+	req.Header.Set(headerKeySessionID, sessionID)
+	delResp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	delResp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(disconnected) != 1 || disconnected[0] != sessionID {
+		t.Errorf("Expected disconnect for session %s, got %v", sessionID, disconnected)
+	}
+}
+
+func TestStreamableHTTP_ConcurrentSessions(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0", WithLogging())
+	streamableServer := NewStreamableHTTPServer(mcpServer)
+	server := httptest.NewServer(streamableServer)
+	defer server.Close()
+
+	post := func(sessionID string, body map[string]any) (*http.Response, jsonRPCResponse) {
+		t.Helper()
+		jsonBody, _ := json.Marshal(body)
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Errorf("Failed to send request: %v", err)
+			return nil, jsonRPCResponse{}
+		}
+		defer resp.Body.Close()
+		var response jsonRPCResponse
+		_ = json.NewDecoder(resp.Body).Decode(&response)
+		return resp, response
+	}
+
+	// initialize many sessions concurrently, each with its own log level
+	const sessions = 20
+	levels := []mcp.LoggingLevel{mcp.LoggingLevelDebug, mcp.LoggingLevelError}
+	ids := make([]string, sessions)
+	var wg sync.WaitGroup
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, _ := post("", initRequest)
+			if resp == nil {
+				return
+			}
+			ids[i] = resp.Header.Get(headerKeySessionID)
+			post(ids[i], map[string]any{
+				"jsonrpc": "2.0",
+				"id":      2,
+				"method":  "logging/setLevel",
+				"params":  map[string]any{"level": levels[i%2]},
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i, id := range ids {
+		if id == "" || seen[id] {
+			t.Fatalf("Expected distinct session ids, got %q", id)
+		}
+		seen[id] = true
+		if level := streamableServer.sessionLogLevels.get(id); level != levels[i%2] {
+			t.Errorf("Session %d: expected log level %s, got %s", i, levels[i%2], level)
+		}
+	}
+
+	// deleting a session does not affect the others
+	req, _ := http.NewRequest(http.MethodDelete, server.URL, nil)
+	req.Header.Set(headerKeySessionID, ids[0])
+	delResp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	delResp.Body.Close()
+
+	if level := streamableServer.sessionLogLevels.get(ids[0]); level != mcp.LoggingLevelInfo {
+		t.Errorf("Expected log level of the deleted session to be freed, got %s", level)
+	}
+	ping := map[string]any{"jsonrpc": "2.0", "id": 3, "method": "ping"}
+	if resp, _ := post(ids[0], ping); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for a deleted session, got %d", resp.StatusCode)
+	}
+	if resp, _ := post(ids[1], ping); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 for another session, got %d", resp.StatusCode)
+	}
+	if level := streamableServer.sessionLogLevels.get(ids[1]); level != levels[1] {
+		t.Errorf("Expected log level of other session to be kept, got %s", level)
+	}
+}
+
+// terminatedSessionIdManager reports every session as terminated.
+type terminatedSessionIdManager struct {
+	InsecureStatefulSessionIdManager
+}
+
+func (m *terminatedSessionIdManager) Validate(sessionID string) (isTerminated bool, err error) {
+	return true, nil
+}
+
+func TestStreamableHTTP_UnknownSession(t *testing.T) {
+	post := func(t *testing.T, url string, sessionID string, body string) (*http.Response, []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp, respBody
+	}
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	expectNotFound := func(t *testing.T, resp *http.Response, body []byte) {
+		t.Helper()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected 404, got %d", resp.StatusCode)
+		}
+		var response struct {
+			Error *struct {
+				Code int `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(body, &response); err != nil || response.Error == nil || response.Error.Code != mcp.INVALID_REQUEST {
+			t.Errorf("Expected a JSON-RPC error, got %s (%v)", body, err)
+		}
+	}
+
+	t.Run("sessions not issued by the server get a 404", func(t *testing.T) {
+		server := NewTestStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"))
+		defer server.Close()
+
+		// a well-formed session id that this server never issued, e.g. before a restart
+		resp, body := post(t, server.URL, "mcp-session-2c44d701-fd50-44ce-92b8-dec46185a741", ping)
+		expectNotFound(t, resp, body)
+		if resp, _ := post(t, server.URL, "not-a-session-id", ping); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for an invalid session id, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("sessions terminated by the manager get a 404", func(t *testing.T) {
+		server := NewTestStreamableHTTPServer(NewMCPServer("test-mcp-server", "1.0"),
+			WithSessionIdManager(&terminatedSessionIdManager{}),
+		)
+		defer server.Close()
+
+		initResp, _ := post(t, server.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"}}}`)
+		resp, body := post(t, server.URL, initResp.Header.Get(headerKeySessionID), ping)
+		expectNotFound(t, resp, body)
+	})
+}
+
+func TestStreamableHTTP_SessionIdleTimeout(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0")
+	streamableServer := NewStreamableHTTPServer(mcpServer, WithSessionIdleTimeout(100*time.Millisecond))
+	defer streamableServer.Shutdown(context.Background())
+	server := httptest.NewServer(streamableServer)
+	defer server.Close()
+
+	initialize := func() string {
+		t.Helper()
+		resp, err := postJSON(server.URL, initRequest)
+		if err != nil {
+			t.Fatalf("Failed to send initialize request: %v", err)
+		}
+		resp.Body.Close()
+		return resp.Header.Get(headerKeySessionID)
+	}
+	ping := func(sessionID string) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send ping: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("active sessions are kept", func(t *testing.T) {
+		sessionID := initialize()
+		for i := 0; i < 5; i++ {
+			time.Sleep(40 * time.Millisecond)
+			if status := ping(sessionID); status != http.StatusOK {
+				t.Fatalf("Expected 200 for an active session, got %d", status)
+			}
+		}
+	})
+
+	t.Run("abandoned sessions expire", func(t *testing.T) {
+		sessionID := initialize()
+		streamableServer.sessionLogLevels.set(sessionID, mcp.LoggingLevelDebug)
+		streamableServer.sessionSubscriptions.subscribe(sessionID, "test://resource")
+
+		// Idle sessions are swept while the server handles other requests
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			if _, ok := streamableServer.sessionStates.Load(sessionID); !ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the idle session to expire")
+			}
+			time.Sleep(10 * time.Millisecond)
+			initialize()
+		}
+		if streamableServer.sessionSubscriptions.isSubscribed(sessionID, "test://resource") {
+			t.Error("Expected subscriptions of the expired session to be freed")
+		}
+		if level := streamableServer.sessionLogLevels.get(sessionID); level != mcp.LoggingLevelInfo {
+			t.Errorf("Expected log level of the expired session to be freed, got %s", level)
+		}
+		if status := ping(sessionID); status != http.StatusNotFound {
+			t.Errorf("Expected 404 for an expired session, got %d", status)
+		}
+	})
+
+	t.Run("sessions with an open stream are kept", func(t *testing.T) {
+		sessionID := initialize()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		req.Header.Set(headerKeySessionID, sessionID)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to open GET connection: %v", err)
+		}
+		defer resp.Body.Close()
+
+		time.Sleep(300 * time.Millisecond)
+		if status := ping(sessionID); status != http.StatusOK {
+			t.Errorf("Expected 200 for a session with an open stream, got %d", status)
+		}
+	})
+}
+
+func TestStreamableHTTP_StrictHandshakeSessionExpiry(t *testing.T) {
+	mcpServer := NewMCPServer("test-mcp-server", "1.0", WithToolCapabilities(false), WithStrictHandshake())
+	streamableServer := NewStreamableHTTPServer(mcpServer, WithSessionIdleTimeout(100*time.Millisecond))
+	defer streamableServer.Shutdown(context.Background())
+	server := httptest.NewServer(streamableServer)
+	defer server.Close()
+
+	post := func(sessionID, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sessionID != "" {
+			req.Header.Set(headerKeySessionID, sessionID)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("Failed to send request: %v", err)
+		}
+		return resp
+	}
+	handshake := func() string {
+		t.Helper()
+		resp, err := postJSON(server.URL, initRequest)
+		if err != nil {
+			t.Fatalf("Failed to send initialize request: %v", err)
+		}
+		resp.Body.Close()
+		sessionID := resp.Header.Get(headerKeySessionID)
+		post(sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`).Body.Close()
+		return sessionID
+	}
+	listTools := func(sessionID string) (int, map[string]any) {
+		t.Helper()
+		resp := post(sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)
+		defer resp.Body.Close()
+		var body map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body
+	}
+
+	sessionID := handshake()
+	if status, body := listTools(sessionID); status != http.StatusOK || body["error"] != nil {
+		t.Fatalf("Expected tools/list to succeed after the handshake, got %d: %v", status, body)
+	}
+
+	// Sweeps run while the server handles other requests
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := streamableServer.sessionStates.Load(sessionID); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the idle session to expire")
+		}
+		time.Sleep(10 * time.Millisecond)
+		resp, _ := postJSON(server.URL, initRequest)
+		resp.Body.Close()
+	}
+	if status, _ := listTools(sessionID); status != http.StatusNotFound {
+		t.Fatalf("Expected 404 for an expired session, got %d", status)
+	}
+
+	newSessionID := handshake()
+	if newSessionID == sessionID {
+		t.Fatal("Expected a new session ID after re-initializing")
+	}
+	if status, body := listTools(newSessionID); status != http.StatusOK || body["error"] != nil {
+		t.Errorf("Expected tools/list to succeed after re-initializing, got %d: %v", status, body)
+	}
+}
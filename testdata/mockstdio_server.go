@@ -52,12 +52,13 @@ func handleRequest(request JSONRPCRequest) JSONRPCResponse {
 	switch request.Method {
 	case "initialize":
 		response.Result = map[string]any{
-			"protocolVersion": "1.0",
+			"protocolVersion": "2024-11-05",
 			"serverInfo": map[string]any{
 				"name":    "mock-server",
 				"version": "1.0.0",
 			},
 			"capabilities": map[string]any{
+				"logging": map[string]any{},
 				"prompts": map[string]any{
 					"listChanged": true,
 				},
@@ -155,6 +156,8 @@ func handleRequest(request JSONRPCRequest) JSONRPCResponse {
 		})
 		fmt.Fprintf(os.Stdout, "%s\n", responseBytes)
 
+	case "debug/exit":
+		os.Exit(0)
 	case "debug/echo_error_string":
 		all, _ := json.Marshal(request)
 		response.Error = &struct {